@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gverger/go-graph-layout/layout"
+	"github.com/gverger/go-graph-layout/layoutjson"
+	"github.com/gverger/go-graph-layout/pipeline"
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+// layoutGraph lays out graph in layoutjson schema with pipeline and returns it with positions in same schema.
+// Pipeline is JSON of either name of layout, or pipeline config. Layers layout is used when pipeline is empty.
+// Nodes without size are sized by their labels.
+func layoutGraph(graph, pipelineConfig string) (string, error) {
+	config := pipeline.Config{"type": "layers"}
+	if pipelineConfig != "" {
+		var name string
+		if err := json.Unmarshal([]byte(pipelineConfig), &name); err == nil {
+			config = pipeline.Config{"type": name}
+		} else {
+			c, err := pipeline.ParseJSON([]byte(pipelineConfig))
+			if err != nil {
+				return "", err
+			}
+			config = c
+		}
+	}
+	l, err := pipeline.Build(config)
+	if err != nil {
+		return "", err
+	}
+
+	g, err := layoutjson.Unmarshal([]byte(graph))
+	if err != nil {
+		return "", err
+	}
+	lg, ids, err := g.Layout()
+	if err != nil {
+		return "", err
+	}
+	var sizer svg.Graph
+	for _, n := range g.Nodes {
+		if n.W == 0 && n.H == 0 {
+			title := n.ID
+			if n.Label != "" {
+				title = n.Label
+			}
+			node := lg.Nodes[ids[n.ID]]
+			node.W, node.H = sizer.NodeSize(svg.Node{Title: title})
+			lg.Nodes[ids[n.ID]] = node
+		}
+	}
+
+	// layered layouts report invalid layered graphs as error
+	if layered, ok := l.(interface {
+		LayoutLayered(g layout.Graph) (layout.LayeredGraph, error)
+	}); ok {
+		if _, err := layered.LayoutLayered(lg); err != nil {
+			return "", fmt.Errorf("layout: %w", err)
+		}
+	} else {
+		l.UpdateGraphLayout(lg)
+	}
+
+	g.Update(lg, ids)
+	data, err := layoutjson.Marshal(g)
+	if err != nil {
+		return "", fmt.Errorf("write graph: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layoutjson"
+)
+
+const testGraph = `{"nodes": [{"id": "a", "label": "A"}, {"id": "b", "w": 40, "h": 20}, {"id": "c"}],
+"edges": [{"from": "a", "to": "b"}, {"from": "a", "to": "c"}]}`
+
+func TestLayoutGraph(t *testing.T) {
+	pipelines := map[string]string{
+		"default": "",
+		"name":    `"layers"`,
+		"config":  `{"type": "forces", "max_steps": 10}`,
+	}
+	for name, p := range pipelines {
+		t.Run(name, func(t *testing.T) {
+			out, err := layoutGraph(testGraph, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := layoutjson.Unmarshal([]byte(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(g.Nodes) != 3 || g.Nodes[0].ID != "a" || g.Nodes[0].Label != "A" || g.Nodes[1].W != 40 {
+				t.Errorf("unexpected nodes: %+v", g.Nodes)
+			}
+			for _, e := range g.Edges {
+				if len(e.Path) < 2 {
+					t.Errorf("edge without path: %+v", e)
+				}
+			}
+		})
+	}
+}
+
+func TestLayoutGraphErrors(t *testing.T) {
+	tests := map[string][2]string{
+		"invalid graph":     {`{"nodes": [{"id": "a"}, {"id": "a"}]}`, ""},
+		"unknown pipeline":  {testGraph, `"circle"`},
+		"invalid config":    {testGraph, `{"type": "layers", "epochs": 1}`},
+		"config not object": {testGraph, `[1]`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := layoutGraph(tt[0], tt[1]); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := layoutGraph(testGraph, `{"type": "layers", "epochs": 1}`); !strings.Contains(err.Error(), "epochs") {
+		t.Errorf("expected error about epochs, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Command graphlayout-wasm exposes layouts to JavaScript when built with GOOS=js GOARCH=wasm.
+//
+// It defines global function graphLayout(graph, pipeline). Graph is object or JSON string in layoutjson schema,
+// pipeline is optional name of layout, like "layers" or "forces", or pipeline config object.
+// Function returns graph with positions in same schema, or object with message in "error".
+//
+//	const go = new Go();
+//	const {instance} = await WebAssembly.instantiateStreaming(fetch("graphlayout.wasm"), go.importObject);
+//	go.run(instance);
+//	const g = graphLayout({nodes: [{id: "a"}, {id: "b"}], edges: [{from: "a", to: "b"}]}, "layers");
+package main
+
+import (
+	"io"
+	"log"
+	"syscall/js"
+)
+
+func main() {
+	// layouts log their progress
+	log.SetOutput(io.Discard)
+
+	register()
+	select {}
+}
+
+func register() {
+	js.Global().Set("graphLayout", js.FuncOf(jsLayoutGraph))
+}
+
+func jsLayoutGraph(this js.Value, args []js.Value) any {
+	JSON := js.Global().Get("JSON")
+	jsonOf := func(v js.Value) string {
+		if v.Type() == js.TypeString {
+			return v.String()
+		}
+		return JSON.Call("stringify", v).String()
+	}
+
+	if len(args) == 0 || args[0].IsUndefined() || args[0].IsNull() {
+		return map[string]any{"error": "missing graph"}
+	}
+	graph := jsonOf(args[0])
+
+	var config string
+	if len(args) > 1 && !args[1].IsUndefined() && !args[1].IsNull() {
+		config = JSON.Call("stringify", args[1]).String()
+	}
+
+	out, err := layoutGraph(graph, config)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return JSON.Call("parse", out)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"syscall/js"
+	"testing"
+)
+
+// Run with: GOOS=js GOARCH=wasm go test -exec="$(go env GOROOT)/lib/wasm/go_js_wasm_exec" ./cmd/graphlayout-wasm
+func TestJSLayoutGraph(t *testing.T) {
+	// like in main, writes to stderr inside of callback deadlock
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	register()
+	JSON := js.Global().Get("JSON")
+
+	graph := JSON.Call("parse", testGraph)
+	for _, p := range []any{js.Undefined(), "forces", JSON.Call("parse", `{"type": "layers", "ordering_assigner": {"type": "warfield", "epochs": 5}}`)} {
+		out := js.Global().Call("graphLayout", graph, p)
+		if err := out.Get("error"); !err.IsUndefined() {
+			t.Fatalf("pipeline %v: %s", p, err.String())
+		}
+		nodes := out.Get("nodes")
+		if nodes.Length() != 3 || nodes.Index(0).Get("id").String() != "a" {
+			t.Errorf("unexpected nodes: %s", JSON.Call("stringify", nodes).String())
+		}
+		if out.Get("edges").Index(0).Get("path").Length() < 2 {
+			t.Errorf("unexpected edges: %s", JSON.Call("stringify", out.Get("edges")).String())
+		}
+	}
+
+	out := js.Global().Call("graphLayout", testGraph, "circle")
+	if err := out.Get("error"); err.IsUndefined() {
+		t.Error("expected error for unknown layout")
+	}
+	out = js.Global().Call("graphLayout")
+	if err := out.Get("error"); err.Type() != js.TypeString || err.String() != "missing graph" {
+		t.Errorf("expected missing graph error, got %v", err)
+	}
+}
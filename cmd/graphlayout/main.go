@@ -0,0 +1,259 @@
+// Command graphlayout lays out graph and renders it.
+//
+// Graph is read from file given as argument, or from stdin.
+// Input can be JSONL (jsonl-graph), DOT, GraphML or JSON (layoutjson), result is written to stdout as SVG, HTML, PNG, PDF, JSON, DOT or text.
+// HTML is single page with SVG and viewer with pan, zoom and search, PNG and PDF are drawn like SVG without browser.
+// Text draws layers layout with box-drawing characters for terminals, ascii uses only ASCII characters.
+//
+//	graphlayout -layout layers -to svg graph.jsonl > graph.svg
+//	cat graph.dot | graphlayout -from dot -layout forces -to dot
+//	graphlayout -to text graph.dot
+//
+// Layout can be configured in detail with pipeline config file in JSON or YAML, then -layout and its flags are ignored.
+//
+//	graphlayout -config layers.yaml graph.dot > graph.svg
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/gverger/go-graph-layout/internal/document"
+	"github.com/gverger/go-graph-layout/layout"
+	"github.com/gverger/go-graph-layout/pdf"
+	"github.com/gverger/go-graph-layout/pipeline"
+	"github.com/gverger/go-graph-layout/raster"
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// layoutParams are flags for all layouts, each layout uses only its own.
+type layoutParams struct {
+	epochs         int
+	delta          int
+	layerMargin    int
+	fakeNodeHeight int
+
+	steps    int
+	gravity  float64
+	springK  float64
+	springL  float64
+	epsilon  float64
+	stepSize float64
+
+	repulsion float64
+	rate      float64
+	updates   int
+	theta     float64
+	scale     float64
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("graphlayout", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: graphlayout [flags] [file]")
+		fmt.Fprintln(flags.Output(), "Lays out graph from file or stdin and writes it to stdout.")
+		flags.PrintDefaults()
+	}
+
+	var (
+		from    = flags.String("from", "", "input format: jsonl, dot, graphml or json (default from file extension, jsonl for stdin)")
+		to      = flags.String("to", "svg", "output format: svg, html, png, pdf, json, dot, text or ascii (text and ascii need layers layout)")
+		theme   = flags.String("theme", "light", "svg: theme, light or dark")
+		margin  = flags.Int("margin", document.DefaultMargin, "svg: space around graph in pixels")
+		width   = flags.Int("width", 0, "svg: width of image in pixels (default width of graph, or scaled to -height)")
+		height  = flags.Int("height", 0, "svg: height of image in pixels (default height of graph, or scaled to -width)")
+		fit     = flags.Bool("fit", false, "svg: image fills its container, -width and -height are ignored")
+		embed   = flags.Bool("embed-font", false, "svg: embed font that measures text, so text fits nodes in browsers without it")
+		dpi     = flags.Float64("dpi", raster.DefaultDPI, "png: resolution, 96 is one pixel per pixel of svg")
+		page    = flags.String("page", "", "pdf: size of pages, a4, a3, letter or width and height in points like 842x595 (default size of graph)")
+		name    = flags.String("layout", "layers", "layout: layers, forces, eades or isomap")
+		config  = flags.String("config", "", "pipeline config file in JSON or YAML, overrides layout flags")
+		verbose = flags.Bool("v", false, "log progress of layout to stderr")
+		params  layoutParams
+	)
+	flags.IntVar(&params.epochs, "epochs", 100, "layers: epochs of ordering optimization")
+	flags.IntVar(&params.delta, "delta", 25, "layers: minimum horizontal distance between nodes")
+	flags.IntVar(&params.layerMargin, "layer-margin", 25, "layers: vertical margin between layers")
+	flags.IntVar(&params.fakeNodeHeight, "fake-node-height", 25, "layers: height of layers with only edges")
+	flags.IntVar(&params.steps, "steps", 5000, "forces: maximum number of steps")
+	flags.Float64Var(&params.gravity, "gravity", -50, "forces: gravity force coefficient, negative pushes nodes apart")
+	flags.Float64Var(&params.springK, "spring-k", 0.2, "forces: spring force coefficient")
+	flags.Float64Var(&params.springL, "spring-l", 200, "forces: spring length")
+	flags.Float64Var(&params.epsilon, "epsilon", 1.5, "forces: stop when forces are smaller than this")
+	flags.Float64Var(&params.stepSize, "step", 1, "forces: how much nodes move each step")
+	flags.Float64Var(&params.repulsion, "repulsion", 1, "eades: repulsion of nodes")
+	flags.Float64Var(&params.rate, "rate", 0.05, "eades: learning rate")
+	flags.IntVar(&params.updates, "updates", 30, "eades: number of updates")
+	flags.Float64Var(&params.theta, "theta", 0.2, "eades: Barnes-Hut approximation parameter")
+	flags.Float64Var(&params.scale, "scale", 0.5, "eades, isomap: scale of coordinates")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// layouts log their progress
+	if !*verbose {
+		defer log.SetOutput(log.Writer())
+		log.SetOutput(io.Discard)
+	}
+
+	var (
+		in   io.Reader = stdin
+		path string
+	)
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		path = flags.Arg(0)
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	default:
+		return errors.New("expected at most one input file")
+	}
+
+	format := *from
+	if format == "" {
+		format = document.FormatOf(path)
+	}
+
+	t, ok := svg.Themes[*theme]
+	if !ok {
+		return fmt.Errorf("unknown theme %q", *theme)
+	}
+	t.EmbedFont = *embed
+	if *margin < 0 || *width < 0 || *height < 0 {
+		return errors.New("margin, width and height of svg should not be negative")
+	}
+	if *dpi <= 0 {
+		return errors.New("dpi of png should be positive")
+	}
+	pageWidth, pageHeight, err := pdf.PageSize(*page)
+	if err != nil {
+		return err
+	}
+	opts := document.Options{
+		Theme: &t, Margin: *margin, Width: *width, Height: *height, Fit: *fit, DPI: *dpi,
+		PageWidth: pageWidth, PageHeight: pageHeight,
+	}
+
+	var l layout.Layout
+	if *config != "" {
+		l, err = loadLayout(*config)
+	} else {
+		l, err = newLayout(*name, params)
+	}
+	if err != nil {
+		return err
+	}
+
+	doc, err := document.Read(in, format, opts.Theme)
+	if err != nil {
+		return err
+	}
+
+	if err := doc.UpdateLayout(l); err != nil {
+		return err
+	}
+
+	return document.Write(stdout, doc, *to, opts)
+}
+
+// loadLayout builds layout from pipeline config file, files with .json extension are JSON, others are YAML.
+func loadLayout(path string) (layout.Layout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c pipeline.Config
+	if filepath.Ext(path) == ".json" {
+		c, err = pipeline.ParseJSON(data)
+	} else {
+		c, err = pipeline.ParseYAML(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pipeline.Build(c)
+}
+
+func newLayout(name string, p layoutParams) (layout.Layout, error) {
+	switch name {
+	case "layers":
+		return layout.SugiyamaLayersStrategyGraphLayout{
+			CycleRemover:   layout.NewSimpleCycleRemover(),
+			LevelsAssigner: layout.NewLayeredGraph,
+			OrderingAssigner: layout.WarfieldOrderingOptimizer{
+				Epochs:                   p.epochs,
+				LayerOrderingInitializer: layout.BFSOrderingInitializer{},
+				LayerOrderingOptimizer: layout.CompositeLayerOrderingOptimizer{
+					Optimizers: []layout.LayerOrderingOptimizer{
+						layout.WMedianOrderingOptimizer{},
+						layout.SwitchAdjacentOrderingOptimizer{},
+					},
+				},
+			}.Optimize,
+			NodesHorizontalCoordinatesAssigner: layout.BrandesKopfLayersNodesHorizontalAssigner{
+				Delta: p.delta,
+			},
+			NodesVerticalCoordinatesAssigner: layout.BasicNodesVerticalCoordinatesAssigner{
+				MarginLayers:   p.layerMargin,
+				FakeNodeHeight: p.fakeNodeHeight,
+			},
+			EdgePathAssigner: layout.StraightEdgePathAssigner{}.UpdateGraphLayout,
+		}, nil
+	case "forces":
+		return layout.SequenceLayout{
+			Layouts: []layout.Layout{
+				layout.ForceGraphLayout{
+					Delta:    p.stepSize,
+					MaxSteps: p.steps,
+					Epsilon:  p.epsilon,
+					Forces: []layout.Force{
+						layout.GravityForce{K: p.gravity},
+						layout.SpringForce{K: p.springK, L: p.springL, EdgesOnly: true},
+					},
+				},
+				layout.DirectEdgesLayout{},
+			},
+		}, nil
+	case "eades":
+		return layout.SequenceLayout{
+			Layouts: []layout.Layout{
+				layout.EadesGonumLayout{
+					Repulsion: p.repulsion,
+					Rate:      p.rate,
+					Updates:   p.updates,
+					Theta:     p.theta,
+					ScaleX:    p.scale,
+					ScaleY:    p.scale,
+				},
+				layout.DirectEdgesLayout{},
+			},
+		}, nil
+	case "isomap":
+		return layout.SequenceLayout{
+			Layouts: []layout.Layout{
+				layout.IsomapR2GonumLayout{ScaleX: p.scale, ScaleY: p.scale},
+				layout.DirectEdgesLayout{},
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown layout %q", name)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testJSONL = `{"id":"a","size":1}
+{"id":"b"}
+{"id":"c"}
+{"from":"a","to":"b"}
+{"from":"b","to":"c"}
+{"from":"a","to":"c"}
+`
+	testDOT = `digraph { subgraph cluster_x { label="X"; a; b } a -> b -> c; a -> c }`
+
+	testGraphML = `<graphml><graph edgedefault="directed">
+<node id="a"/><node id="b"/><node id="c"/>
+<edge source="a" target="b"/><edge source="b" target="c"/><edge source="a" target="c"/>
+</graph></graphml>`
+
+	testJSON = `{"nodes": [{"id": "a", "label": "A"}, {"id": "b", "w": 40, "h": 20}, {"id": "c"}],
+"edges": [{"from": "a", "to": "b"}, {"from": "b", "to": "c"}, {"from": "a", "to": "c"}],
+"clusters": [{"id": "x", "label": "X", "nodes": ["a", "b"]}]}`
+)
+
+func TestRun(t *testing.T) {
+	inputs := map[string]string{"jsonl": testJSONL, "dot": testDOT, "graphml": testGraphML, "json": testJSON}
+	outputs := map[string]string{"svg": "<svg", "html": "<!DOCTYPE html>", "png": "\x89PNG", "pdf": "%PDF-", "json": `"nodes"`, "dot": "digraph"}
+	layouts := []string{"layers", "forces", "eades", "isomap"}
+
+	for from, input := range inputs {
+		for to, prefix := range outputs {
+			for _, l := range layouts {
+				t.Run(from+"_"+to+"_"+l, func(t *testing.T) {
+					var out strings.Builder
+					args := []string{"-from", from, "-to", to, "-layout", l, "-steps", "10"}
+					if err := run(args, strings.NewReader(input), &out); err != nil {
+						t.Fatal(err)
+					}
+					if !strings.Contains(out.String(), prefix) {
+						t.Errorf("unexpected output:\n%s", out.String())
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.dot")
+	if err := os.WriteFile(path, []byte(testDOT), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := run([]string{"-to", "dot", path}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `subgraph cluster_x`) || !strings.Contains(out.String(), `pos=`) {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	configs := map[string]string{
+		"layers.yaml": "type: layers\nhorizontal_assigner: {type: brandes_kopf, delta: 40}\n",
+		"forces.json": `{"type": "forces", "max_steps": 10}`,
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var out strings.Builder
+			if err := run([]string{"-config", path, "-to", "json"}, strings.NewReader(testJSONL), &out); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), `"path"`) {
+				t.Errorf("unexpected output:\n%s", out.String())
+			}
+		})
+	}
+
+	t.Run("invalid config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "layers.yaml")
+		if err := os.WriteFile(path, []byte("type: layers\nepochs: 10\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		err := run([]string{"-config", path}, strings.NewReader(testJSONL), &strings.Builder{})
+		if err == nil || !strings.Contains(err.Error(), `epochs: unknown parameter of layout "layers"`) {
+			t.Errorf("expected unknown parameter error, got %v", err)
+		}
+	})
+}
+
+func TestRunSize(t *testing.T) {
+	var out strings.Builder
+	if err := run([]string{"-width", "300", "-margin", "0"}, strings.NewReader(testJSONL), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `viewBox="`) || !strings.Contains(out.String(), `width="300"`) {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestRunText(t *testing.T) {
+	outputs := map[string]string{"text": "┌─", "ascii": "+-"}
+	for to, prefix := range outputs {
+		t.Run(to, func(t *testing.T) {
+			var out strings.Builder
+			if err := run([]string{"-from", "dot", "-to", to}, strings.NewReader(`digraph { a -> b }`), &out); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(out.String(), prefix) || !strings.Contains(out.String(), "│ a │") && !strings.Contains(out.String(), "| a |") {
+				t.Errorf("unexpected output:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestRunEmbedFont(t *testing.T) {
+	var out strings.Builder
+	if err := run([]string{"-embed-font"}, strings.NewReader(testJSONL), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "@font-face { font-family: Go;") {
+		t.Errorf("expected embedded font:\n%.1000s", out.String())
+	}
+}
+
+func TestRunShapes(t *testing.T) {
+	input := `digraph { a [shape=ellipse]; b [shape=record]; a -> b }`
+	var out strings.Builder
+	if err := run([]string{"-from", "dot"}, strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "foreignObject") || !strings.Contains(out.String(), "<ellipse") || !strings.Contains(out.String(), "graph-node-record") {
+		t.Errorf("expected svg shapes:\n%s", out.String())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := map[string][]string{
+		"unknown layout": {"-layout", "circle"},
+		"unknown input":  {"-from", "csv"},
+		"unknown output": {"-to", "bmp"},
+		"unknown theme":  {"-theme", "neon"},
+		"negative width": {"-width", "-10"},
+		"zero dpi":       {"-to", "png", "-dpi", "0"},
+		"unknown page":   {"-to", "pdf", "-page", "a0"},
+		"text of forces": {"-to", "text", "-layout", "forces", "-steps", "10"},
+		"missing file":   {"missing.jsonl"},
+		"too many files": {"a.jsonl", "b.jsonl"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := run(args, strings.NewReader(testJSONL), &strings.Builder{}); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+
+}
+
+func TestRunContradictingConstraints(t *testing.T) {
+	// same rank of nodes of edge is dropped
+	input := `digraph { a -> b; {rank=same; a; b} }`
+	var out strings.Builder
+	if err := run([]string{"-from", "dot", "-to", "dot"}, strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "pos=") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestRunEdgeLabels(t *testing.T) {
+	var out strings.Builder
+	if err := run([]string{"-from", "dot", "-to", "json"}, strings.NewReader(`digraph { a -> b [label="ab"] }`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"text": "ab"`) {
+		t.Fatalf("expected edge label:\n%s", out.String())
+	}
+
+	// labels of json input are kept
+	var again strings.Builder
+	if err := run([]string{"-from", "json", "-to", "json"}, strings.NewReader(out.String()), &again); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(again.String(), `"text": "ab"`) {
+		t.Errorf("expected edge label:\n%s", again.String())
+	}
+}
+
+func TestRunUndirectedEdges(t *testing.T) {
+	input := `<graphml><graph edgedefault="directed">
+<node id="a"/><node id="b"/><node id="c"/>
+<edge source="a" target="b"/><edge source="b" target="c" directed="false"/>
+</graph></graphml>`
+	var out strings.Builder
+	if err := run([]string{"-from", "graphml", "-to", "svg"}, strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out.String(), "marker-end="); n != 1 {
+		t.Errorf("expected arrow only on directed edge, got %d:\n%s", n, out.String())
+	}
+}
@@ -0,0 +1,69 @@
+// Command layoutserver serves layouts over HTTP with httplayout handler.
+//
+//	layoutserver -addr localhost:8080
+//	curl -d '{"format": "dot", "graph": "digraph { a -> b }", "output": "svg"}' localhost:8080/layout
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/gverger/go-graph-layout/httplayout"
+)
+
+func main() {
+	var (
+		addr     = flag.String("addr", "localhost:8080", "address to listen on")
+		timeout  = flag.Duration("timeout", httplayout.DefaultTimeout, "maximum duration of layout")
+		maxBytes = flag.Int64("max-bytes", httplayout.DefaultMaxBytes, "maximum size of request")
+		maxNodes = flag.Int("max-nodes", httplayout.DefaultMaxNodes, "maximum number of nodes in graph")
+		maxIters = flag.Int("max-iterations", httplayout.DefaultMaxIterations, "maximum iterations, epochs and steps of each component of configs")
+		maxWork  = flag.Int("max-work", httplayout.DefaultMaxWork, "maximum work of configs, iterations of nested components are multiplied by iterations of their parents")
+		maxPix   = flag.Int("max-pixels", httplayout.DefaultMaxPixels, "maximum pixels of PNG images")
+		layouts  = flag.Int("layouts", runtime.NumCPU(), "maximum number of layouts that run at once")
+		verbose  = flag.Bool("v", false, "log progress of layouts")
+	)
+	flag.Parse()
+	if *maxIters <= 0 || *maxWork <= 0 || *maxPix <= 0 || *layouts <= 0 {
+		fmt.Fprintln(os.Stderr, "max-iterations, max-work, max-pixels and layouts should be positive")
+		os.Exit(2)
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	// layouts log their progress
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/layout", httplayout.Handler{
+		MaxBytes:      *maxBytes,
+		MaxNodes:      *maxNodes,
+		MaxIterations: *maxIters,
+		MaxWork:       *maxWork,
+		MaxPixels:     *maxPix,
+		Timeout:       *timeout,
+		Slots:         make(chan struct{}, *layouts),
+		ErrorLog:      logger,
+	})
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      *timeout + 30*time.Second,
+		ErrorLog:          logger,
+	}
+	logger.Printf("listening on %s", *addr)
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,71 @@
+package dot
+
+import (
+	"strings"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+// Graph is graph parsed from DOT, together with layout graph built from it.
+// Node IDs are assigned in order of first appearance, starting from 1.
+// Edges with same ends are merged, later attributes override earlier ones.
+// Self loops are kept in Edges, but are not in Layout, since layouts do not support them.
+type Graph struct {
+	Name     string
+	Directed bool
+	Strict   bool
+	Attrs    Attrs // graph attributes
+	Layout   layout.Graph
+	IDs      map[string]layout.NodeID // DOT node ID -> layout node ID
+	Nodes    map[layout.NodeID]Node
+	Edges    map[[2]layout.NodeID]Edge
+	Clusters map[layout.ClusterID]Cluster // subgraphs with name starting with "cluster"
+}
+
+// Attrs are DOT attributes, values are unquoted.
+type Attrs map[string]string
+
+func (a Attrs) copy() Attrs {
+	c := make(Attrs, len(a))
+	for k, v := range a {
+		c[k] = v
+	}
+	return c
+}
+
+// Node is DOT node with its attributes.
+type Node struct {
+	ID    string
+	Attrs Attrs
+}
+
+// Label is label of node, defaults to node ID.
+// Escape `\N` in label is replaced by node ID.
+func (n Node) Label() string {
+	label, ok := n.Attrs["label"]
+	if !ok {
+		return n.ID
+	}
+	return strings.ReplaceAll(label, `\N`, n.ID)
+}
+
+// Edge is DOT edge with its attributes.
+// Ports are port name and compass point, like "p1:n" or "s".
+type Edge struct {
+	From     string
+	To       string
+	FromPort string
+	ToPort   string
+	Attrs    Attrs
+}
+
+// Cluster is DOT cluster subgraph with its graph attributes.
+type Cluster struct {
+	ID    string
+	Attrs Attrs
+}
+
+// Label is label of cluster, empty by default.
+func (c Cluster) Label() string {
+	return c.Attrs["label"]
+}
@@ -0,0 +1,306 @@
+package dot
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenID
+	tokenLBrace
+	tokenRBrace
+	tokenLBracket
+	tokenRBracket
+	tokenEqual
+	tokenSemicolon
+	tokenComma
+	tokenColon
+	tokenEdgeOp
+)
+
+func (t tokenType) String() string {
+	switch t {
+	case tokenEOF:
+		return "end of input"
+	case tokenID:
+		return "identifier"
+	case tokenLBrace:
+		return "'{'"
+	case tokenRBrace:
+		return "'}'"
+	case tokenLBracket:
+		return "'['"
+	case tokenRBracket:
+		return "']'"
+	case tokenEqual:
+		return "'='"
+	case tokenSemicolon:
+		return "';'"
+	case tokenComma:
+		return "','"
+	case tokenColon:
+		return "':'"
+	case tokenEdgeOp:
+		return "edge operator"
+	default:
+		return fmt.Sprintf("token(%d)", int(t))
+	}
+}
+
+type token struct {
+	typ    tokenType
+	value  string
+	quoted bool // quoted and HTML strings are never keywords
+	line   int
+	column int
+}
+
+func (t token) String() string {
+	if t.typ == tokenID || t.typ == tokenEdgeOp {
+		return fmt.Sprintf("%q", t.value)
+	}
+	return t.typ.String()
+}
+
+// ParseError is error in DOT input, with position where it was found.
+type ParseError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("dot: line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// lexer splits DOT input into tokens.
+// Comments and preprocessor lines (starting with '#') are skipped.
+type lexer struct {
+	input  string
+	pos    int
+	line   int
+	column int
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: input, line: 1, column: 1}
+}
+
+func (l *lexer) errorf(line, column int, format string, args ...interface{}) error {
+	return ParseError{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) peekRune(offset int) rune {
+	if l.pos+offset >= len(l.input) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos+offset:])
+	return r
+}
+
+func (l *lexer) next() rune {
+	r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+	l.pos += size
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return r
+}
+
+// skipSpace skips whitespace and comments.
+func (l *lexer) skipSpace() error {
+	for l.pos < len(l.input) {
+		r := l.peekRune(0)
+		switch {
+		case unicode.IsSpace(r):
+			l.next()
+		case r == '#' && l.column == 1:
+			for l.pos < len(l.input) && l.peekRune(0) != '\n' {
+				l.next()
+			}
+		case r == '/' && l.peekRune(1) == '/':
+			for l.pos < len(l.input) && l.peekRune(0) != '\n' {
+				l.next()
+			}
+		case r == '/' && l.peekRune(1) == '*':
+			line, column := l.line, l.column
+			l.next()
+			l.next()
+			for {
+				if l.pos >= len(l.input) {
+					return l.errorf(line, column, "unterminated comment")
+				}
+				if l.peekRune(0) == '*' && l.peekRune(1) == '/' {
+					l.next()
+					l.next()
+					break
+				}
+				l.next()
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *lexer) token() (token, error) {
+	if err := l.skipSpace(); err != nil {
+		return token{}, err
+	}
+
+	t := token{line: l.line, column: l.column}
+	if l.pos >= len(l.input) {
+		t.typ = tokenEOF
+		return t, nil
+	}
+
+	r := l.peekRune(0)
+	switch {
+	case r == '{':
+		t.typ = tokenLBrace
+	case r == '}':
+		t.typ = tokenRBrace
+	case r == '[':
+		t.typ = tokenLBracket
+	case r == ']':
+		t.typ = tokenRBracket
+	case r == '=':
+		t.typ = tokenEqual
+	case r == ';':
+		t.typ = tokenSemicolon
+	case r == ',':
+		t.typ = tokenComma
+	case r == ':':
+		t.typ = tokenColon
+	case r == '-' && (l.peekRune(1) == '>' || l.peekRune(1) == '-'):
+		l.next()
+		t.typ = tokenEdgeOp
+		t.value = "-" + string(l.next())
+		return t, nil
+	case r == '"':
+		return l.quoted(t)
+	case r == '<':
+		return l.html(t)
+	case r == '-' || r == '.' || unicode.IsDigit(r):
+		return l.numeral(t)
+	case r == '_' || unicode.IsLetter(r) || r >= 0x80:
+		start := l.pos
+		for l.pos < len(l.input) {
+			r := l.peekRune(0)
+			if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && r < 0x80 {
+				break
+			}
+			l.next()
+		}
+		t.typ = tokenID
+		t.value = l.input[start:l.pos]
+		return t, nil
+	default:
+		return t, l.errorf(t.line, t.column, "unexpected character %q", r)
+	}
+
+	l.next()
+	return t, nil
+}
+
+func (l *lexer) numeral(t token) (token, error) {
+	start := l.pos
+	if l.peekRune(0) == '-' {
+		l.next()
+	}
+	digits, dot := 0, false
+	for l.pos < len(l.input) {
+		r := l.peekRune(0)
+		if r == '.' && !dot {
+			dot = true
+		} else if unicode.IsDigit(r) {
+			digits++
+		} else {
+			break
+		}
+		l.next()
+	}
+	if digits == 0 {
+		return t, l.errorf(t.line, t.column, "invalid numeral %q", l.input[start:l.pos])
+	}
+	t.typ = tokenID
+	t.value = l.input[start:l.pos]
+	return t, nil
+}
+
+// quoted reads double quoted string, escaped quotes are unescaped and escaped newlines are removed.
+// Other escapes, like \n or \l in labels, are kept as is.
+func (l *lexer) quoted(t token) (token, error) {
+	l.next()
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.input) {
+			return t, l.errorf(t.line, t.column, "unterminated string")
+		}
+		r := l.next()
+		switch {
+		case r == '"':
+			// strings can be concatenated with '+'
+			pos, line, column := l.pos, l.line, l.column
+			if err := l.skipSpace(); err == nil && l.peekRune(0) == '+' {
+				l.next()
+				if err := l.skipSpace(); err == nil && l.peekRune(0) == '"' {
+					next, err := l.quoted(token{line: l.line, column: l.column})
+					if err != nil {
+						return t, err
+					}
+					b.WriteString(next.value)
+					pos, line, column = l.pos, l.line, l.column
+				}
+			}
+			l.pos, l.line, l.column = pos, line, column
+
+			t.typ = tokenID
+			t.value = b.String()
+			t.quoted = true
+			return t, nil
+		case r == '\\' && l.peekRune(0) == '"':
+			b.WriteRune(l.next())
+		case r == '\\' && l.peekRune(0) == '\n':
+			l.next()
+		case r == '\\' && l.peekRune(0) == '\r' && l.peekRune(1) == '\n':
+			l.next()
+			l.next()
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// html reads HTML string, which is text between balanced angle brackets.
+func (l *lexer) html(t token) (token, error) {
+	l.next()
+	start := l.pos
+	depth := 1
+	for {
+		if l.pos >= len(l.input) {
+			return t, l.errorf(t.line, t.column, "unterminated HTML string")
+		}
+		switch l.next() {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				t.typ = tokenID
+				t.value = l.input[start : l.pos-1]
+				t.quoted = true
+				return t, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,621 @@
+package dot
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+const (
+	pointsPerInch  = 72
+	defaultWidth   = 0.75 // inches
+	defaultHeight  = 0.5  // inches
+	defaultMargin  = 8    // points
+	clusterPrefix  = "cluster"
+	keywordNode    = "node"
+	keywordEdge    = "edge"
+	keywordGraph   = "graph"
+	keywordDigraph = "digraph"
+	keywordSubG    = "subgraph"
+	keywordStrict  = "strict"
+)
+
+// Parse reads DOT graph and builds layout graph from it.
+//
+// Node attributes width and height (inches) set node size, default is 0.75x0.5.
+// Node attribute pos ("x,y" in points, y going up, like in Graphviz) sets node center,
+// node is pinned when pos ends with '!' or when pin=true.
+// Positions are relative to left top corner of graph attribute bb when it is set, so node at top of bb gets y=0.
+// Edge attributes weight and minlen set edge weight and minimum length.
+// Subgraphs named "cluster..." become clusters, margin (points) sets cluster margin.
+// Subgraphs with rank=same|min|max|source|sink become rank constraints.
+func Parse(r io.Reader) (*Graph, error) {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseString(string(input))
+}
+
+// ParseString is Parse for string input.
+func ParseString(input string) (*Graph, error) {
+	p := &parser{
+		lex: newLexer(input),
+		g: &Graph{
+			Attrs:    Attrs{},
+			IDs:      map[string]layout.NodeID{},
+			Nodes:    map[layout.NodeID]Node{},
+			Edges:    map[[2]layout.NodeID]Edge{},
+			Clusters: map[layout.ClusterID]Cluster{},
+		},
+		nodeCluster:   map[layout.NodeID]layout.ClusterID{},
+		clusterParent: map[layout.ClusterID]layout.ClusterID{},
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if err := p.parseGraph(); err != nil {
+		return nil, err
+	}
+	p.build()
+	return p.g, nil
+}
+
+// scope is graph or subgraph being parsed.
+type scope struct {
+	nodeDefaults Attrs
+	edgeDefaults Attrs
+	attrs        Attrs // graph attributes of subgraph
+	cluster      layout.ClusterID
+	nodes        []layout.NodeID // nodes mentioned in scope, including nested subgraphs
+}
+
+type parser struct {
+	lex    *lexer
+	tok    token
+	g      *Graph
+	scopes []*scope
+
+	nodeCluster   map[layout.NodeID]layout.ClusterID
+	clusterParent map[layout.ClusterID]layout.ClusterID
+	ranks         []layout.RankConstraint
+}
+
+func (p *parser) advance() error {
+	t, err := p.lex.token()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return ParseError{Line: p.tok.line, Column: p.tok.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expect(typ tokenType) (token, error) {
+	t := p.tok
+	if t.typ != typ {
+		return t, p.errorf("expected %s, got %s", typ, t)
+	}
+	return t, p.advance()
+}
+
+// isKeyword tells that current token is given keyword, keywords are case insensitive.
+func (p *parser) isKeyword(keyword string) bool {
+	return p.tok.typ == tokenID && !p.tok.quoted && strings.EqualFold(p.tok.value, keyword)
+}
+
+func (p *parser) current() *scope {
+	return p.scopes[len(p.scopes)-1]
+}
+
+// graph : [strict] (graph | digraph) [ID] '{' stmt_list '}'
+func (p *parser) parseGraph() error {
+	if p.isKeyword(keywordStrict) {
+		p.g.Strict = true
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case p.isKeyword(keywordGraph):
+	case p.isKeyword(keywordDigraph):
+		p.g.Directed = true
+	default:
+		return p.errorf("expected graph or digraph, got %s", p.tok)
+	}
+	if err := p.advance(); err != nil {
+		return err
+	}
+
+	if p.tok.typ == tokenID {
+		p.g.Name = p.tok.value
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+
+	p.scopes = []*scope{{nodeDefaults: Attrs{}, edgeDefaults: Attrs{}, attrs: p.g.Attrs}}
+	if _, err := p.expect(tokenLBrace); err != nil {
+		return err
+	}
+	if err := p.parseStmtList(); err != nil {
+		return err
+	}
+	if _, err := p.expect(tokenRBrace); err != nil {
+		return err
+	}
+	if p.tok.typ != tokenEOF {
+		return p.errorf("unexpected %s after end of graph", p.tok)
+	}
+	return nil
+}
+
+// stmt_list : [stmt [';'] stmt_list]
+func (p *parser) parseStmtList() error {
+	for p.tok.typ != tokenRBrace && p.tok.typ != tokenEOF {
+		if err := p.parseStmt(); err != nil {
+			return err
+		}
+		if p.tok.typ == tokenSemicolon {
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stmt : node_stmt | edge_stmt | attr_stmt | ID '=' ID | subgraph
+func (p *parser) parseStmt() error {
+	switch {
+	case p.isKeyword(keywordGraph), p.isKeyword(keywordNode), p.isKeyword(keywordEdge):
+		kind := strings.ToLower(p.tok.value)
+		if err := p.advance(); err != nil {
+			return err
+		}
+		attrs, err := p.parseAttrLists()
+		if err != nil {
+			return err
+		}
+		s := p.current()
+		switch kind {
+		case keywordGraph:
+			merge(s.attrs, attrs)
+		case keywordNode:
+			s.nodeDefaults = s.nodeDefaults.copy()
+			merge(s.nodeDefaults, attrs)
+		case keywordEdge:
+			s.edgeDefaults = s.edgeDefaults.copy()
+			merge(s.edgeDefaults, attrs)
+		}
+		return nil
+	case p.tok.typ == tokenLBrace || p.isKeyword(keywordSubG):
+		nodes, err := p.parseSubgraph()
+		if err != nil {
+			return err
+		}
+		if p.tok.typ == tokenEdgeOp {
+			return p.parseEdgeRHS(endpoints{nodes: nodes})
+		}
+		return nil
+	case p.tok.typ == tokenID:
+		id := p.tok
+		if err := p.advance(); err != nil {
+			return err
+		}
+
+		if p.tok.typ == tokenEqual {
+			if err := p.advance(); err != nil {
+				return err
+			}
+			value, err := p.expect(tokenID)
+			if err != nil {
+				return err
+			}
+			attrs := Attrs{id.value: value.value}
+			if err := validateAttrs(attrs, id); err != nil {
+				return err
+			}
+			merge(p.current().attrs, attrs)
+			return nil
+		}
+
+		port, err := p.parsePort()
+		if err != nil {
+			return err
+		}
+
+		if p.tok.typ == tokenEdgeOp {
+			n := p.mentionNode(id.value, nil)
+			return p.parseEdgeRHS(endpoints{nodes: []layout.NodeID{n}, port: port})
+		}
+
+		attrs, err := p.parseAttrLists()
+		if err != nil {
+			return err
+		}
+		p.mentionNode(id.value, attrs)
+		return nil
+	default:
+		return p.errorf("unexpected %s", p.tok)
+	}
+}
+
+// endpoints is one side of edge, either single node with port or all nodes of subgraph.
+type endpoints struct {
+	nodes []layout.NodeID
+	port  string
+}
+
+// edgeRHS : edgeop (node_id | subgraph) [edgeRHS] [attr_list]
+func (p *parser) parseEdgeRHS(from endpoints) error {
+	chain := []endpoints{from}
+	for p.tok.typ == tokenEdgeOp {
+		if p.g.Directed && p.tok.value != "->" {
+			return p.errorf("undirected edge operator %q in digraph", p.tok.value)
+		}
+		if !p.g.Directed && p.tok.value != "--" {
+			return p.errorf("directed edge operator %q in graph", p.tok.value)
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+
+		switch {
+		case p.tok.typ == tokenLBrace || p.isKeyword(keywordSubG):
+			nodes, err := p.parseSubgraph()
+			if err != nil {
+				return err
+			}
+			chain = append(chain, endpoints{nodes: nodes})
+		case p.tok.typ == tokenID:
+			id := p.tok.value
+			if err := p.advance(); err != nil {
+				return err
+			}
+			port, err := p.parsePort()
+			if err != nil {
+				return err
+			}
+			chain = append(chain, endpoints{nodes: []layout.NodeID{p.mentionNode(id, nil)}, port: port})
+		default:
+			return p.errorf("expected node or subgraph after edge operator, got %s", p.tok)
+		}
+	}
+
+	attrs, err := p.parseAttrLists()
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i < len(chain); i++ {
+		for _, from := range chain[i-1].nodes {
+			for _, to := range chain[i].nodes {
+				p.addEdge(from, to, chain[i-1].port, chain[i].port, attrs)
+			}
+		}
+	}
+	return nil
+}
+
+// port : ':' ID [':' compass_pt]
+func (p *parser) parsePort() (string, error) {
+	var parts []string
+	for p.tok.typ == tokenColon && len(parts) < 2 {
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		id, err := p.expect(tokenID)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, id.value)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// subgraph : [subgraph [ID]] '{' stmt_list '}'
+// Returns all nodes mentioned in subgraph.
+func (p *parser) parseSubgraph() ([]layout.NodeID, error) {
+	var name string
+	if p.isKeyword(keywordSubG) {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.typ == tokenID {
+			name = p.tok.value
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	parent := p.current()
+	s := &scope{
+		nodeDefaults: parent.nodeDefaults,
+		edgeDefaults: parent.edgeDefaults,
+		attrs:        Attrs{},
+		cluster:      parent.cluster,
+	}
+	if strings.HasPrefix(name, clusterPrefix) {
+		c, ok := p.g.Clusters[name]
+		if !ok {
+			c = Cluster{ID: name, Attrs: Attrs{}}
+			p.g.Clusters[name] = c
+			p.clusterParent[name] = parent.cluster
+		}
+		s.attrs = c.Attrs
+		s.cluster = name
+	}
+
+	start := p.tok
+	if _, err := p.expect(tokenLBrace); err != nil {
+		return nil, err
+	}
+	p.scopes = append(p.scopes, s)
+	if err := p.parseStmtList(); err != nil {
+		return nil, err
+	}
+	if p.tok.typ != tokenRBrace {
+		return nil, ParseError{Line: start.line, Column: start.column, Msg: "subgraph is not closed"}
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	p.scopes = p.scopes[:len(p.scopes)-1]
+
+	if rank, ok := s.attrs["rank"]; ok && len(s.nodes) > 0 {
+		p.ranks = append(p.ranks, layout.RankConstraint{Rank: rankTypes[rank], Nodes: s.nodes})
+	}
+
+	return s.nodes, nil
+}
+
+// attr_list : '[' [a_list] ']' [attr_list]
+// a_list : ID '=' ID [(';' | ',')] [a_list]
+func (p *parser) parseAttrLists() (Attrs, error) {
+	attrs := Attrs{}
+	for p.tok.typ == tokenLBracket {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for p.tok.typ != tokenRBracket {
+			key, err := p.expect(tokenID)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokenEqual); err != nil {
+				return nil, err
+			}
+			value, err := p.expect(tokenID)
+			if err != nil {
+				return nil, err
+			}
+			a := Attrs{key.value: value.value}
+			if err := validateAttrs(a, key); err != nil {
+				return nil, err
+			}
+			merge(attrs, a)
+			if p.tok.typ == tokenSemicolon || p.tok.typ == tokenComma {
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return attrs, nil
+}
+
+// mentionNode creates node when it is seen first time and adds it to current subgraphs.
+func (p *parser) mentionNode(id string, attrs Attrs) layout.NodeID {
+	n, ok := p.g.IDs[id]
+	if !ok {
+		n = layout.NodeID(len(p.g.IDs) + 1)
+		p.g.IDs[id] = n
+		p.g.Nodes[n] = Node{ID: id, Attrs: p.current().nodeDefaults.copy()}
+	}
+	merge(p.g.Nodes[n].Attrs, attrs)
+
+	for _, s := range p.scopes {
+		s.nodes = append(s.nodes, n)
+	}
+
+	// node goes to innermost cluster it is mentioned in
+	if c := p.current().cluster; c != "" {
+		prev, ok := p.nodeCluster[n]
+		if !ok || p.isInside(c, prev) {
+			p.nodeCluster[n] = c
+		}
+	}
+	return n
+}
+
+// isInside tells that cluster c is nested in cluster parent.
+func (p *parser) isInside(c, parent layout.ClusterID) bool {
+	for c = p.clusterParent[c]; c != ""; c = p.clusterParent[c] {
+		if c == parent {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) addEdge(from, to layout.NodeID, fromPort, toPort string, attrs Attrs) {
+	key := [2]layout.NodeID{from, to}
+	if !p.g.Directed {
+		if _, ok := p.g.Edges[[2]layout.NodeID{to, from}]; ok {
+			key = [2]layout.NodeID{to, from}
+			from, to = to, from
+			fromPort, toPort = toPort, fromPort
+		}
+	}
+	e, ok := p.g.Edges[key]
+	if !ok {
+		e = Edge{
+			From:  p.g.Nodes[from].ID,
+			To:    p.g.Nodes[to].ID,
+			Attrs: p.current().edgeDefaults.copy(),
+		}
+	}
+	if fromPort != "" {
+		e.FromPort = fromPort
+	}
+	if toPort != "" {
+		e.ToPort = toPort
+	}
+	merge(e.Attrs, attrs)
+	p.g.Edges[key] = e
+}
+
+func merge(dst, src Attrs) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+var rankTypes = map[string]layout.RankType{
+	"same":   layout.RankSame,
+	"min":    layout.RankMin,
+	"max":    layout.RankMax,
+	"source": layout.RankSource,
+	"sink":   layout.RankSink,
+}
+
+// validateAttrs checks values of attributes that are used for layout.
+func validateAttrs(attrs Attrs, at token) error {
+	fail := func(k, v, expected string) error {
+		return ParseError{Line: at.line, Column: at.column, Msg: fmt.Sprintf("attribute %s=%q: expected %s", k, v, expected)}
+	}
+	for k, v := range attrs {
+		switch k {
+		case "width", "height", "weight", "minlen", "margin":
+			if f, err := strconv.ParseFloat(v, 64); err != nil || f < 0 {
+				// cluster margin can be "x,y", only first value is used
+				if _, _, ok := parsePoint(v); k == "margin" && ok {
+					continue
+				}
+				return fail(k, v, "non negative number")
+			}
+		case "rank":
+			if _, ok := rankTypes[v]; !ok {
+				return fail(k, v, "one of same, min, max, source, sink")
+			}
+		case "pin":
+			if _, err := strconv.ParseBool(v); err != nil {
+				return fail(k, v, "boolean")
+			}
+		}
+	}
+	return nil
+}
+
+func parsePoint(v string) (x, y float64, ok bool) {
+	parts := strings.Split(v, ",")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	x, errX := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	return x, y, errX == nil && errY == nil
+}
+
+// graphFrame is left x and top y of bb "llx,lly,urx,ury", zeros when bb is not valid.
+func graphFrame(bb string) (left, top float64) {
+	parts := strings.Split(bb, ",")
+	if len(parts) != 4 {
+		return 0, 0
+	}
+	x, _, okLow := parsePoint(parts[0] + "," + parts[1])
+	_, y, okUp := parsePoint(parts[2] + "," + parts[3])
+	if !okLow || !okUp {
+		return 0, 0
+	}
+	return x, y
+}
+
+func attrFloat(attrs Attrs, key string, def float64) float64 {
+	v, ok := attrs[key]
+	if !ok {
+		return def
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	if x, _, ok := parsePoint(v); ok {
+		return x
+	}
+	return def
+}
+
+// build creates layout graph from parsed nodes, edges and subgraphs.
+func (p *parser) build() {
+	g := layout.Graph{
+		Nodes: make(map[layout.NodeID]layout.Node, len(p.g.Nodes)),
+		Edges: make(map[[2]layout.NodeID]layout.Edge, len(p.g.Edges)),
+		Ranks: p.ranks,
+	}
+
+	left, top := graphFrame(p.g.Attrs["bb"])
+	for id, n := range p.g.Nodes {
+		node := layout.Node{
+			W: int(math.Round(attrFloat(n.Attrs, "width", defaultWidth) * pointsPerInch)),
+			H: int(math.Round(attrFloat(n.Attrs, "height", defaultHeight) * pointsPerInch)),
+		}
+		pos := n.Attrs["pos"]
+		if x, y, ok := parsePoint(strings.TrimSuffix(pos, "!")); ok {
+			node.Position = layout.Position{
+				X: int(math.Round(x - left - float64(node.W)/2)),
+				Y: int(math.Round(top - y - float64(node.H)/2)),
+			}
+			pin, _ := strconv.ParseBool(n.Attrs["pin"])
+			node.Pinned = pin || strings.HasSuffix(pos, "!")
+		}
+		g.Nodes[id] = node
+	}
+
+	for key, e := range p.g.Edges {
+		if key[0] == key[1] {
+			continue
+		}
+		edge := layout.Edge{MinLen: int(math.Round(attrFloat(e.Attrs, "minlen", 1)))}
+		// weight 0 is kept, it means that edge does not need to be short and straight
+		if _, ok := e.Attrs["weight"]; ok {
+			w := int(math.Round(attrFloat(e.Attrs, "weight", 1)))
+			edge.Weight = &w
+		}
+		g.Edges[key] = edge
+	}
+
+	if len(p.g.Clusters) > 0 {
+		g.Clusters = make(map[layout.ClusterID]layout.Cluster, len(p.g.Clusters))
+		for id, c := range p.g.Clusters {
+			g.Clusters[id] = layout.Cluster{
+				Parent: p.clusterParent[id],
+				Margin: int(math.Round(attrFloat(c.Attrs, "margin", defaultMargin))),
+			}
+		}
+		for n, id := range p.nodeCluster {
+			c := g.Clusters[id]
+			c.Nodes = append(c.Nodes, n)
+			g.Clusters[id] = c
+		}
+		for id, c := range g.Clusters {
+			sort.Slice(c.Nodes, func(i, j int) bool { return c.Nodes[i] < c.Nodes[j] })
+			g.Clusters[id] = c
+		}
+	}
+
+	p.g.Layout = g
+}
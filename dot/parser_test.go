@@ -0,0 +1,151 @@
+package dot_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/dot"
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+func weight(w int) *int { return &w }
+
+func TestParse(t *testing.T) {
+	g, err := dot.ParseString(`
+/* comment */
+strict digraph "G" {
+	rankdir = TB
+	node [shape=box, width=1]
+	a [label="A \N", height=0.25]
+	a -> b:p1:n -> c [weight=3]
+	b -> d [minlen=2, weight=0] // comment
+	subgraph cluster_0 {
+		label = "Cluster"
+		margin = 12
+		c; d
+		subgraph cluster_1 { d }
+	}
+	{ rank = same; e; f }
+	e -> { a f } -> a
+	f [pos="100,200!", width=0.5, height=0.5]
+	a -> a
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Name != "G" || !g.Directed || !g.Strict || g.Attrs["rankdir"] != "TB" {
+		t.Errorf("wrong graph header: %q directed=%v strict=%v attrs=%v", g.Name, g.Directed, g.Strict, g.Attrs)
+	}
+
+	ids := map[string]layout.NodeID{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6}
+	if !reflect.DeepEqual(g.IDs, ids) {
+		t.Errorf("wrong node ids: %v", g.IDs)
+	}
+	if l := g.Nodes[1].Label(); l != "A a" {
+		t.Errorf("wrong label %q", l)
+	}
+	if s := g.Nodes[4].Attrs["shape"]; s != "box" {
+		t.Errorf("node default attribute not applied, shape=%q", s)
+	}
+
+	if n := g.Layout.Nodes[1]; n.W != 72 || n.H != 18 {
+		t.Errorf("wrong node size %dx%d", n.W, n.H)
+	}
+	if n := g.Layout.Nodes[6]; !n.Pinned || n.Position != (layout.Position{X: 82, Y: -218}) {
+		t.Errorf("wrong pinned node %+v", n)
+	}
+
+	edges := map[[2]layout.NodeID]layout.Edge{
+		{1, 2}: {Weight: weight(3), MinLen: 1},
+		{2, 3}: {Weight: weight(3), MinLen: 1},
+		{2, 4}: {Weight: weight(0), MinLen: 2},
+		{5, 1}: {MinLen: 1},
+		{5, 6}: {MinLen: 1},
+		{6, 1}: {MinLen: 1},
+	}
+	if !reflect.DeepEqual(g.Layout.Edges, edges) {
+		t.Errorf("wrong layout edges: %v", g.Layout.Edges)
+	}
+	if _, ok := g.Edges[[2]layout.NodeID{1, 1}]; !ok {
+		t.Errorf("self loop is missing from parsed edges")
+	}
+	if e := g.Edges[[2]layout.NodeID{1, 2}]; e.ToPort != "p1:n" {
+		t.Errorf("wrong port %q", e.ToPort)
+	}
+
+	clusters := map[layout.ClusterID]layout.Cluster{
+		"cluster_0": {Nodes: []layout.NodeID{3}, Margin: 12},
+		"cluster_1": {Nodes: []layout.NodeID{4}, Parent: "cluster_0", Margin: 8},
+	}
+	if !reflect.DeepEqual(g.Layout.Clusters, clusters) {
+		t.Errorf("wrong clusters: %v", g.Layout.Clusters)
+	}
+	if l := g.Clusters["cluster_0"].Label(); l != "Cluster" {
+		t.Errorf("wrong cluster label %q", l)
+	}
+
+	ranks := []layout.RankConstraint{{Rank: layout.RankSame, Nodes: []layout.NodeID{5, 6}}}
+	if !reflect.DeepEqual(g.Layout.Ranks, ranks) {
+		t.Errorf("wrong ranks: %v", g.Layout.Ranks)
+	}
+}
+
+func TestParseUndirected(t *testing.T) {
+	g, err := dot.ParseString(`graph { a -- b; b -- a [label=x]; "node" -- c }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Edges) != 2 {
+		t.Errorf("expected 2 edges, got %v", g.Edges)
+	}
+	if e := g.Edges[[2]layout.NodeID{1, 2}]; e.Attrs["label"] != "x" {
+		t.Errorf("edges with same ends should be merged: %+v", e)
+	}
+	if _, ok := g.IDs["node"]; !ok {
+		t.Errorf("quoted keyword should be node ID: %v", g.IDs)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		line   int
+		column int
+	}{
+		"missing brace":   {input: "digraph {\n a -> b\n", line: 3, column: 1},
+		"wrong edge op":   {input: "digraph {\n a -- b\n}", line: 2, column: 4},
+		"bad attribute":   {input: "digraph {\n a [width=wide]\n}", line: 2, column: 5},
+		"bad rank":        {input: "digraph {\n {rank=top; a}\n}", line: 2, column: 3},
+		"unterminated":    {input: "digraph {\n a [label=\"x]\n}", line: 2, column: 11},
+		"not a graph":     {input: "tree { }", line: 1, column: 1},
+		"trailing tokens": {input: "graph { } }", line: 1, column: 11},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := dot.ParseString(tt.input)
+			var perr dot.ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected parse error, got %v", err)
+			}
+			if perr.Line != tt.line || perr.Column != tt.column {
+				t.Errorf("expected error at %d:%d, got %v", tt.line, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	g, err := dot.ParseString(`digraph { graph [bb="0,0,200,100"]; a [pos="36,82", width=1, height=0.5]; b [pos="136,18"] }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := g.Layout.Nodes[g.IDs["a"]].Position; p != (layout.Position{X: 0, Y: 0}) {
+		t.Errorf("expected node a at top left corner, got %+v", p)
+	}
+	if p := g.Layout.Nodes[g.IDs["b"]].Position; p != (layout.Position{X: 109, Y: 64}) {
+		t.Errorf("expected node b at 109,64, got %+v", p)
+	}
+}
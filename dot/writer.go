@@ -0,0 +1,392 @@
+package dot
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+// arrowLength is length of arrowhead in points, spline of edge ends this far from arrowhead tip.
+const arrowLength = 10
+
+// FromLayout wraps layout graph to be written as DOT.
+// Node IDs are written as numbers, graph is directed.
+// Cluster IDs get "cluster_" prefix when they do not start with "cluster", so that Graphviz draws them as clusters.
+func FromLayout(g layout.Graph) *Graph {
+	dg := &Graph{
+		Directed: true,
+		Attrs:    Attrs{},
+		Layout:   g,
+		IDs:      make(map[string]layout.NodeID, len(g.Nodes)),
+		Nodes:    make(map[layout.NodeID]Node, len(g.Nodes)),
+		Edges:    make(map[[2]layout.NodeID]Edge, len(g.Edges)),
+		Clusters: make(map[layout.ClusterID]Cluster, len(g.Clusters)),
+	}
+	for n := range g.Nodes {
+		id := strconv.FormatUint(n, 10)
+		dg.IDs[id] = n
+		dg.Nodes[n] = Node{ID: id, Attrs: Attrs{}}
+	}
+	for e := range g.Edges {
+		dg.Edges[e] = Edge{From: dg.Nodes[e[0]].ID, To: dg.Nodes[e[1]].ID, Attrs: Attrs{}}
+	}
+	for c := range g.Clusters {
+		id := c
+		if !strings.HasPrefix(id, clusterPrefix) {
+			id = clusterPrefix + "_" + id
+		}
+		dg.Clusters[c] = Cluster{ID: id, Attrs: Attrs{}}
+	}
+	return dg
+}
+
+// Write writes graph as DOT with positions from its layout graph, like `dot -Tdot` does.
+// Coordinates follow Graphviz conventions: points, y axis going up, node pos is center of node,
+// width and height are in inches, edge pos is B-spline clipped to node boxes, with arrowhead end point.
+// Layout y axis goes down, so points are written relative to bounding box of graph, which is written as bb="0,0,W,H".
+// Parsing written graph gives back same node positions, moved so that bounding box starts at 0,0.
+// Graph and clusters get their bounding box in bb attribute, subgraphs of clusters are named by their Cluster ID.
+// Output is deterministic: nodes are written in order of their IDs, edges in order of their ends.
+func Write(w io.Writer, g *Graph) error {
+	var b bytes.Buffer
+
+	if g.Strict {
+		b.WriteString("strict ")
+	}
+	if g.Directed {
+		b.WriteString("digraph")
+	} else {
+		b.WriteString("graph")
+	}
+	if g.Name != "" {
+		b.WriteString(" " + quote(g.Name))
+	}
+	b.WriteString(" {\n")
+
+	minx, miny, maxx, maxy := graphBox(g.Layout)
+	f := frame{X: float64(minx), Y: float64(maxy)}
+
+	attrs := g.Attrs.copy()
+	attrs["bb"] = f.box(minx, miny, maxx, maxy)
+	writeAttrStmt(&b, 1, "graph", attrs)
+
+	children := make(map[layout.ClusterID][]layout.ClusterID)
+	for id, c := range g.Layout.Clusters {
+		children[c.Parent] = append(children[c.Parent], id)
+	}
+	for _, ids := range children {
+		sort.Strings(ids)
+	}
+
+	inCluster := make(map[layout.NodeID]bool)
+	for _, c := range g.Layout.Clusters {
+		for _, n := range c.Nodes {
+			inCluster[n] = true
+		}
+	}
+
+	var writeCluster func(id layout.ClusterID, depth int)
+	writeCluster = func(id layout.ClusterID, depth int) {
+		c := g.Layout.Clusters[id]
+		indent(&b, depth)
+		name := g.Clusters[id].ID
+		if name == "" {
+			name = id
+		}
+		b.WriteString("subgraph " + quote(name) + " {\n")
+
+		attrs := g.Clusters[id].Attrs.copy()
+		attrs["bb"] = f.box(c.X, c.Y, c.X+c.W, c.Y+c.H)
+		writeAttrStmt(&b, depth+1, "graph", attrs)
+
+		for _, child := range children[id] {
+			writeCluster(child, depth+1)
+		}
+
+		nodes := append([]layout.NodeID(nil), c.Nodes...)
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+		for _, n := range nodes {
+			writeNode(&b, depth+1, g, f, n)
+		}
+
+		indent(&b, depth)
+		b.WriteString("}\n")
+	}
+	for _, id := range children[""] {
+		writeCluster(id, 1)
+	}
+
+	nodes := make([]layout.NodeID, 0, len(g.Nodes))
+	for n := range g.Nodes {
+		if !inCluster[n] {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+	for _, n := range nodes {
+		writeNode(&b, 1, g, f, n)
+	}
+
+	edges := make([][2]layout.NodeID, 0, len(g.Edges))
+	for e := range g.Edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+	for _, e := range edges {
+		writeEdge(&b, g, f, e)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func writeNode(b *bytes.Buffer, depth int, g *Graph, f frame, n layout.NodeID) {
+	if _, ok := g.Nodes[n]; !ok {
+		return
+	}
+	attrs := g.Nodes[n].Attrs.copy()
+	if node, ok := g.Layout.Nodes[n]; ok {
+		pos := f.point(nodeCenter(node))
+		if node.Pinned {
+			pos += "!"
+		}
+		attrs["pos"] = pos
+		attrs["width"] = formatFloat(float64(node.W)/pointsPerInch, 5)
+		attrs["height"] = formatFloat(float64(node.H)/pointsPerInch, 5)
+	}
+
+	indent(b, depth)
+	b.WriteString(quote(g.Nodes[n].ID))
+	writeAttrs(b, attrs)
+	b.WriteString(";\n")
+}
+
+func writeEdge(b *bytes.Buffer, g *Graph, f frame, e [2]layout.NodeID) {
+	edge := g.Edges[e]
+	attrs := edge.Attrs.copy()
+	if le, ok := g.Layout.Edges[e]; ok && len(le.Path) >= 2 {
+		attrs["pos"] = edgeSpline(g, f, e, le.Path)
+	}
+
+	op := "--"
+	if g.Directed {
+		op = "->"
+	}
+
+	indent(b, 1)
+	b.WriteString(quote(edge.From))
+	writePort(b, edge.FromPort)
+	b.WriteString(" " + op + " ")
+	b.WriteString(quote(edge.To))
+	writePort(b, edge.ToPort)
+	writeAttrs(b, attrs)
+	b.WriteString(";\n")
+}
+
+func writePort(b *bytes.Buffer, port string) {
+	if port == "" {
+		return
+	}
+	for _, p := range strings.Split(port, ":") {
+		b.WriteString(":" + quote(p))
+	}
+}
+
+func writeAttrStmt(b *bytes.Buffer, depth int, kind string, attrs Attrs) {
+	if len(attrs) == 0 {
+		return
+	}
+	indent(b, depth)
+	b.WriteString(kind)
+	writeAttrs(b, attrs)
+	b.WriteString(";\n")
+}
+
+func writeAttrs(b *bytes.Buffer, attrs Attrs) {
+	if len(attrs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString(" [")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(quote(k) + "=" + quote(attrs[k]))
+	}
+	b.WriteString("]")
+}
+
+func indent(b *bytes.Buffer, depth int) {
+	b.WriteString(strings.Repeat("\t", depth))
+}
+
+var (
+	plainID   = regexp.MustCompile(`^[A-Za-z_][A-Za-z_0-9]*$`)
+	numeralID = regexp.MustCompile(`^-?(\.[0-9]+|[0-9]+(\.[0-9]*)?)$`)
+	keywords  = map[string]bool{keywordNode: true, keywordEdge: true, keywordGraph: true, keywordDigraph: true, keywordSubG: true, keywordStrict: true}
+)
+
+// quote returns ID as is when it can be written without quotes, otherwise it is quoted.
+func quote(id string) string {
+	if (plainID.MatchString(id) && !keywords[strings.ToLower(id)]) || numeralID.MatchString(id) {
+		return id
+	}
+	id = strings.ReplaceAll(id, `"`, `\"`)
+	// trailing backslash would escape closing quote
+	if strings.HasSuffix(id, `\`) && !strings.HasSuffix(id, `\\`) {
+		id += `\`
+	}
+	return `"` + id + `"`
+}
+
+type point struct {
+	X, Y float64
+}
+
+func nodeCenter(n layout.Node) point {
+	return point{X: float64(n.X) + float64(n.W)/2, Y: float64(n.Y) + float64(n.H)/2}
+}
+
+// frame converts layout points to Graphviz points, relative to left bottom corner X,Y of graph with y axis going up.
+type frame point
+
+func (f frame) point(p point) string {
+	return formatFloat(p.X-f.X, 2) + "," + formatFloat(f.Y-p.Y, 2)
+}
+
+// box converts layout box to Graphviz bb: lower left and upper right corners.
+func (f frame) box(minx, miny, maxx, maxy int) string {
+	return f.point(point{X: float64(minx), Y: float64(maxy)}) + "," + f.point(point{X: float64(maxx), Y: float64(miny)})
+}
+
+// formatFloat formats number with at most given number of decimals.
+func formatFloat(v float64, decimals int) string {
+	scale := math.Pow10(decimals)
+	v = math.Round(v*scale) / scale
+	if v == 0 {
+		v = 0 // no "-0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// graphBox is bounding box of nodes, clusters and edges.
+func graphBox(g layout.Graph) (minx, miny, maxx, maxy int) {
+	first := true
+	add := func(x0, y0, x1, y1 int) {
+		if first {
+			minx, miny, maxx, maxy = x0, y0, x1, y1
+			first = false
+			return
+		}
+		minx, miny = min(minx, x0), min(miny, y0)
+		maxx, maxy = max(maxx, x1), max(maxy, y1)
+	}
+	for _, n := range g.Nodes {
+		add(n.X, n.Y, n.X+n.W, n.Y+n.H)
+	}
+	for _, c := range g.Clusters {
+		add(c.X, c.Y, c.X+c.W, c.Y+c.H)
+	}
+	for _, e := range g.Edges {
+		for _, p := range e.Path {
+			add(p.X, p.Y, p.X, p.Y)
+		}
+	}
+	return minx, miny, maxx, maxy
+}
+
+// edgeSpline makes Graphviz edge pos from polyline path.
+// Path is clipped to boxes of end nodes, arrowheads are added as "s,x,y" and "e,x,y" end points,
+// depending on dir attribute. Each straight segment becomes cubic Bezier curve.
+func edgeSpline(g *Graph, f frame, e [2]layout.NodeID, path []layout.Position) string {
+	points := make([]point, len(path))
+	for i, p := range path {
+		points[i] = point{X: float64(p.X), Y: float64(p.Y)}
+	}
+
+	last := len(points) - 1
+	points[0] = clip(g.Layout.Nodes[e[0]], points[1], points[0])
+	points[last] = clip(g.Layout.Nodes[e[1]], points[last-1], points[last])
+
+	dir := g.Edges[e].Attrs["dir"]
+	if dir == "" {
+		dir = "none"
+		if g.Directed {
+			dir = "forward"
+		}
+	}
+
+	var parts []string
+	if dir == "back" || dir == "both" {
+		parts = append(parts, "s,"+f.point(points[0]))
+		points[0] = shorten(points[1], points[0], arrowLength)
+	}
+	if dir == "forward" || dir == "both" {
+		parts = append(parts, "e,"+f.point(points[last]))
+		points[last] = shorten(points[last-1], points[last], arrowLength)
+	}
+
+	parts = append(parts, f.point(points[0]))
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		parts = append(parts,
+			f.point(point{X: a.X + (b.X-a.X)/3, Y: a.Y + (b.Y-a.Y)/3}),
+			f.point(point{X: a.X + 2*(b.X-a.X)/3, Y: a.Y + 2*(b.Y-a.Y)/3}),
+			f.point(b),
+		)
+	}
+	return strings.Join(parts, " ")
+}
+
+// clip moves end point of segment from inside point to border of node box.
+// Points outside of node box are not moved.
+func clip(n layout.Node, from, to point) point {
+	c := nodeCenter(n)
+	hw, hh := float64(n.W)/2, float64(n.H)/2
+	dx, dy := from.X-c.X, from.Y-c.Y
+	if math.Abs(to.X-c.X) > hw || math.Abs(to.Y-c.Y) > hh || (dx == 0 && dy == 0) {
+		return to
+	}
+
+	t := math.Inf(1)
+	if dx != 0 {
+		t = hw / math.Abs(dx)
+	}
+	if dy != 0 {
+		t = math.Min(t, hh/math.Abs(dy))
+	}
+	if t >= 1 {
+		// other end of segment is inside node
+		return to
+	}
+	return point{X: c.X + dx*t, Y: c.Y + dy*t}
+}
+
+// shorten moves end point of segment towards its start by given length, but not past start.
+func shorten(from, to point, length float64) point {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	d := math.Hypot(dx, dy)
+	if d <= length {
+		return to
+	}
+	return point{X: to.X - dx*length/d, Y: to.Y - dy*length/d}
+}
@@ -0,0 +1,112 @@
+package dot_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/dot"
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+func newLayersLayout() layout.SugiyamaLayersStrategyGraphLayout {
+	return layout.SugiyamaLayersStrategyGraphLayout{
+		CycleRemover:   layout.NewSimpleCycleRemover(),
+		LevelsAssigner: layout.NewLayeredGraph,
+		OrderingAssigner: layout.WarfieldOrderingOptimizer{
+			Epochs:                   20,
+			LayerOrderingInitializer: layout.BFSOrderingInitializer{},
+			LayerOrderingOptimizer:   layout.WMedianOrderingOptimizer{},
+		}.Optimize,
+		NodesHorizontalCoordinatesAssigner: layout.BrandesKopfLayersNodesHorizontalAssigner{
+			Delta: 25,
+		},
+		NodesVerticalCoordinatesAssigner: layout.BasicNodesVerticalCoordinatesAssigner{
+			MarginLayers:   25,
+			FakeNodeHeight: 25,
+		},
+		EdgePathAssigner: layout.StraightEdgePathAssigner{}.UpdateGraphLayout,
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{
+			1: {Position: layout.Position{X: 0, Y: 0}, W: 54, H: 36},
+			2: {Position: layout.Position{X: 0, Y: 100}, W: 54, H: 36, Pinned: true},
+		},
+		Edges: map[[2]uint64]layout.Edge{
+			{1, 2}: {Path: []layout.Position{{X: 27, Y: 18}, {X: 27, Y: 118}}},
+		},
+		Clusters: map[string]layout.Cluster{
+			"a": {Position: layout.Position{X: -8, Y: 92}, W: 70, H: 52, Nodes: []uint64{2}},
+		},
+	}
+
+	var b strings.Builder
+	if err := dot.Write(&b, dot.FromLayout(g)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `digraph {
+	graph [bb="0,0,70,144"];
+	subgraph cluster_a {
+		graph [bb="0,0,70,52"];
+		2 [height=0.5, pos="35,26!", width=0.75];
+	}
+	1 [height=0.5, pos="35,126", width=0.75];
+	1 -> 2 [pos="e,35,44 35,108 35,90 35,72 35,54"];
+}
+`
+	if b.String() != expected {
+		t.Errorf("wrong output:\n%s", b.String())
+	}
+}
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	input := `graph "my graph" {
+	node [shape=box]
+	a [label="say \"hi\"", width=1]
+	subgraph cluster_x { label=X; b }
+	a -- b:p:s -- c
+	c -- node_1
+}`
+	g, err := dot.ParseString(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newLayersLayout().UpdateGraphLayout(g.Layout)
+
+	var b strings.Builder
+	if err := dot.Write(&b, g); err != nil {
+		t.Fatal(err)
+	}
+
+	g2, err := dot.ParseString(b.String())
+	if err != nil {
+		t.Fatalf("written graph can not be parsed: %v\n%s", err, b.String())
+	}
+
+	if g2.Name != g.Name || g2.Directed {
+		t.Errorf("wrong graph header %q directed=%v", g2.Name, g2.Directed)
+	}
+	// positions are moved so that bounding box starts at 0,0
+	origin, origin2 := g.Layout.Nodes[g.IDs["a"]].Position, g2.Layout.Nodes[g2.IDs["a"]].Position
+	for id, n := range g.IDs {
+		n2 := g2.IDs[id]
+		if g.Nodes[n].Label() != g2.Nodes[n2].Label() {
+			t.Errorf("node %q: label %q, expected %q", id, g2.Nodes[n2].Label(), g.Nodes[n].Label())
+		}
+		p, p2 := g.Layout.Nodes[n], g2.Layout.Nodes[n2]
+		moved := layout.Position{X: p.X - origin.X + origin2.X, Y: p.Y - origin.Y + origin2.Y}
+		if moved != p2.Position || p.W != p2.W || p.H != p2.H {
+			t.Errorf("node %q: %+v, expected %+v at %+v", id, p2, p, moved)
+		}
+	}
+	if !reflect.DeepEqual(g2.Layout.Clusters["cluster_x"].Nodes, []layout.NodeID{g2.IDs["b"]}) {
+		t.Errorf("wrong cluster %+v", g2.Layout.Clusters)
+	}
+	if e := g2.Edges[[2]layout.NodeID{g2.IDs["a"], g2.IDs["b"]}]; e.ToPort != "p:s" || e.Attrs["pos"] == "" || strings.Contains(e.Attrs["pos"], "e,") {
+		t.Errorf("wrong undirected edge %+v", e)
+	}
+}
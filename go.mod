@@ -6,8 +6,11 @@ toolchain go1.23.1
 
 require (
 	github.com/nikolaydubina/multiline-jsonl v1.0.3 // indirect
-	golang.org/x/exp v0.0.0-20231110203233-9a3e6036ecaa // indirect
+	golang.org/x/exp v0.0.0-20231110203233-9a3e6036ecaa
+	golang.org/x/image v0.18.0
+	golang.org/x/text v0.16.0 // indirect
 	gonum.org/v1/gonum v0.15.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/nikolaydubina/jsonl-graph v1.1.0
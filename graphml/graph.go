@@ -0,0 +1,68 @@
+package graphml
+
+import (
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+// Graph is graph read from GraphML, together with layout graph built from it.
+// Node IDs are assigned in document order, starting from 1.
+// Nodes with nested graph (groups in yEd) are clusters, nodes of nested graph are members of cluster.
+// Edges with same ends are merged, later data overrides earlier one.
+// Self loops are kept in Edges, but are not in Layout, since layouts do not support them.
+type Graph struct {
+	ID       string
+	Directed bool
+	Keys     []Key
+	Data     Data // graph data
+	Layout   layout.Graph
+	IDs      map[string]layout.NodeID // GraphML node ID -> layout node ID
+	Nodes    map[layout.NodeID]Node
+	Edges    map[[2]layout.NodeID]Edge
+	Clusters map[layout.ClusterID]Cluster
+}
+
+// Key is declaration of data attribute.
+// Keys with yFiles graphics are not listed, their content is in Layout and labels.
+type Key struct {
+	ID      string
+	For     string // node, edge, graph or all
+	Name    string // attr.name
+	Type    string // attr.type: boolean, int, long, float, double or string
+	Default string
+}
+
+// Data are values of data elements by attribute name, or by key ID when key has no name.
+// Defaults of keys are included.
+type Data map[string]string
+
+func (d Data) copy() Data {
+	c := make(Data, len(d))
+	for k, v := range d {
+		c[k] = v
+	}
+	return c
+}
+
+// Node is GraphML node with its data.
+type Node struct {
+	ID    string
+	Label string // from yFiles node label, empty if none
+	Data  Data
+}
+
+// Edge is GraphML edge with its data.
+type Edge struct {
+	ID       string
+	Source   string
+	Target   string
+	Directed bool   // from directed attribute, edgedefault of graph when it is not set
+	Label    string // from yFiles edge label, empty if none
+	Data     Data
+}
+
+// Cluster is GraphML node that has nested graph.
+type Cluster struct {
+	ID    string
+	Label string
+	Data  Data
+}
@@ -0,0 +1,193 @@
+package graphml_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/graphml"
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+const yEdGraph = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:y="http://www.yworks.com/xml/graphml">
+  <key attr.name="color" attr.type="string" for="node" id="d0"><default>yellow</default></key>
+  <key attr.name="weight" attr.type="double" for="edge" id="d1"/>
+  <key for="node" id="d2" yfiles.type="nodegraphics"/>
+  <key for="edge" id="d3" yfiles.type="edgegraphics"/>
+  <key attr.name="size" attr.type="float" for="node" id="d4"/>
+  <graph edgedefault="directed" id="G">
+    <node id="a">
+      <data key="d0">green</data>
+      <data key="d2">
+        <y:ShapeNode>
+          <y:Geometry height="30.0" width="80.0" x="10.0" y="20.0"/>
+          <y:NodeLabel>Node A</y:NodeLabel>
+        </y:ShapeNode>
+      </data>
+    </node>
+    <node id="group" yfiles.foldertype="group">
+      <data key="d2">
+        <y:ProxyAutoBoundsNode>
+          <y:Realizers active="1">
+            <y:GroupNode><y:Geometry height="10" width="10" x="0" y="0"/><y:NodeLabel>Closed</y:NodeLabel></y:GroupNode>
+            <y:GroupNode><y:Geometry height="100" width="200" x="-5" y="95"/><y:NodeLabel>Group</y:NodeLabel></y:GroupNode>
+          </y:Realizers>
+        </y:ProxyAutoBoundsNode>
+      </data>
+      <graph edgedefault="directed" id="group:">
+        <node id="b"><data key="d4">40</data></node>
+        <edge id="e1" source="b" target="a" directed="false"/>
+      </graph>
+    </node>
+    <edge id="e0" source="a" target="b">
+      <data key="d1">2.5</data>
+      <data key="d3">
+        <y:PolyLineEdge>
+          <y:Path sx="0.0" sy="15.0" tx="0.0" ty="-20.0"><y:Point x="50" y="80"/></y:Path>
+          <y:EdgeLabel>label</y:EdgeLabel>
+        </y:PolyLineEdge>
+      </data>
+    </edge>
+  </graph>
+</graphml>`
+
+func TestRead(t *testing.T) {
+	g, err := graphml.Read(strings.NewReader(yEdGraph))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.ID != "G" || !g.Directed {
+		t.Errorf("wrong graph %q directed=%v", g.ID, g.Directed)
+	}
+	if len(g.Keys) != 3 || g.Keys[0] != (graphml.Key{ID: "d0", For: "node", Name: "color", Type: "string", Default: "yellow"}) {
+		t.Errorf("wrong keys %+v", g.Keys)
+	}
+
+	a, b := g.IDs["a"], g.IDs["b"]
+	if a != 1 || b != 2 {
+		t.Errorf("wrong ids %v", g.IDs)
+	}
+	if n := g.Nodes[a]; n.Label != "Node A" || n.Data["color"] != "green" {
+		t.Errorf("wrong node %+v", n)
+	}
+	if n := g.Nodes[b]; n.Data["color"] != "yellow" {
+		t.Errorf("default data is not applied %+v", n)
+	}
+
+	nodes := map[layout.NodeID]layout.Node{
+		a: {Position: layout.Position{X: 10, Y: 20}, W: 80, H: 30},
+		b: {W: 40, H: 40},
+	}
+	if !reflect.DeepEqual(g.Layout.Nodes, nodes) {
+		t.Errorf("wrong layout nodes %+v", g.Layout.Nodes)
+	}
+
+	clusters := map[layout.ClusterID]layout.Cluster{
+		"group": {Position: layout.Position{X: -5, Y: 95}, W: 200, H: 100, Nodes: []layout.NodeID{b}, Margin: 8},
+	}
+	if !reflect.DeepEqual(g.Layout.Clusters, clusters) {
+		t.Errorf("wrong clusters %+v", g.Layout.Clusters)
+	}
+	if c := g.Clusters["group"]; c.Label != "Group" {
+		t.Errorf("wrong cluster label %q", c.Label)
+	}
+
+	if e := g.Edges[[2]layout.NodeID{a, b}]; e.ID != "e0" || e.Label != "label" || e.Data["weight"] != "2.5" || !e.Directed {
+		t.Errorf("wrong edge %+v", e)
+	}
+	if e := g.Edges[[2]layout.NodeID{b, a}]; e.Directed {
+		t.Errorf("edge with directed=false is directed %+v", e)
+	}
+	edges := map[[2]layout.NodeID]layout.Edge{
+		{a, b}: {Path: []layout.Position{{X: 50, Y: 50}, {X: 50, Y: 80}, {X: 20, Y: 0}}},
+		{b, a}: {},
+	}
+	if !reflect.DeepEqual(g.Layout.Edges, edges) {
+		t.Errorf("wrong layout edges %+v", g.Layout.Edges)
+	}
+}
+
+func TestReadGroupEdges(t *testing.T) {
+	f, err := os.Open("testdata/group_edges.graphml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	g, err := graphml.Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b, c := g.IDs["a"], g.IDs["b"], g.IDs["c"]
+	edges := map[[2]layout.NodeID]layout.Edge{{a, b}: {}, {b, c}: {}}
+	if !reflect.DeepEqual(g.Layout.Edges, edges) || len(g.Edges) != 2 {
+		t.Errorf("edges of group are not skipped: %+v", g.Layout.Edges)
+	}
+	if cl := g.Layout.Clusters["group"]; !reflect.DeepEqual(cl.Nodes, []layout.NodeID{b, c}) {
+		t.Errorf("wrong cluster %+v", cl)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := map[string]string{
+		"not xml":        `graph {}`,
+		"no graph":       `<graphml></graphml>`,
+		"missing node":   `<graphml><graph><node id="a"/><edge source="a" target="b"/></graph></graphml>`,
+		"wrong directed": `<graphml><graph><node id="a"/><node id="b"/><edge source="a" target="b" directed="no"/></graph></graphml>`,
+		"duplicate node": `<graphml><graph><node id="a"/><node id="a"/></graph></graphml>`,
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := graphml.Read(strings.NewReader(input)); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	lg := layout.Graph{
+		Nodes: map[uint64]layout.Node{
+			1: {Position: layout.Position{X: 0, Y: 0}, W: 50, H: 20},
+			2: {Position: layout.Position{X: 100, Y: 100}, W: 30, H: 30},
+			3: {Position: layout.Position{X: 0, Y: 100}, W: 30, H: 30},
+		},
+		Edges: map[[2]uint64]layout.Edge{
+			{1, 2}: {Path: []layout.Position{{X: 25, Y: 10}, {X: 115, Y: 50}, {X: 115, Y: 115}}},
+			{1, 3}: {Path: []layout.Position{{X: 25, Y: 10}, {X: 15, Y: 115}}},
+		},
+		Clusters: map[string]layout.Cluster{
+			"c": {Position: layout.Position{X: -8, Y: -8}, W: 66, H: 36, Nodes: []uint64{1}, Margin: 8},
+		},
+	}
+
+	g := graphml.FromLayout(lg)
+	g.Keys = []graphml.Key{{ID: "k", For: "node", Name: "kind", Type: "string"}}
+	g.Nodes[1] = graphml.Node{ID: "n1", Label: "Root <&>", Data: graphml.Data{"kind": "root", "extra": "x"}}
+	e := g.Edges[[2]uint64{1, 3}]
+	e.Directed = false
+	g.Edges[[2]uint64{1, 3}] = e
+
+	var b strings.Builder
+	if err := graphml.Write(&b, g); err != nil {
+		t.Fatal(err)
+	}
+
+	g2, err := graphml.Read(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("written graph can not be read: %v\n%s", err, b.String())
+	}
+
+	if !reflect.DeepEqual(g2.Layout, lg) {
+		t.Errorf("wrong layout after round trip:\n%+v\nexpected:\n%+v\n%s", g2.Layout, lg, b.String())
+	}
+	if n := g2.Nodes[g2.IDs["n1"]]; n.Label != "Root <&>" || n.Data["kind"] != "root" || n.Data["extra"] != "x" {
+		t.Errorf("wrong node after round trip %+v", n)
+	}
+	if e := g2.Edges[[2]uint64{g2.IDs["n1"], g2.IDs["n3"]}]; e.Directed || !g2.Edges[[2]uint64{g2.IDs["n1"], g2.IDs["n2"]}].Directed {
+		t.Errorf("wrong direction of edges after round trip %+v", g2.Edges)
+	}
+}
@@ -0,0 +1,377 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+const (
+	defaultWidth  = 54 // points, same as Graphviz
+	defaultHeight = 36 // points
+	defaultMargin = 8  // points, for clusters
+)
+
+type xmlGraphML struct {
+	XMLName xml.Name   `xml:"graphml"`
+	Keys    []xmlKey   `xml:"key"`
+	Graphs  []xmlGraph `xml:"graph"`
+}
+
+type xmlKey struct {
+	ID         string  `xml:"id,attr"`
+	For        string  `xml:"for,attr"`
+	Name       string  `xml:"attr.name,attr"`
+	Type       string  `xml:"attr.type,attr"`
+	YFilesType string  `xml:"yfiles.type,attr"`
+	Default    *string `xml:"default"`
+}
+
+type xmlGraph struct {
+	ID          string    `xml:"id,attr"`
+	EdgeDefault string    `xml:"edgedefault,attr"`
+	Data        []xmlData `xml:"data"`
+	Nodes       []xmlNode `xml:"node"`
+	Edges       []xmlEdge `xml:"edge"`
+}
+
+type xmlNode struct {
+	ID    string    `xml:"id,attr"`
+	Data  []xmlData `xml:"data"`
+	Graph *xmlGraph `xml:"graph"`
+}
+
+type xmlEdge struct {
+	ID       string    `xml:"id,attr"`
+	Source   string    `xml:"source,attr"`
+	Target   string    `xml:"target,attr"`
+	Directed string    `xml:"directed,attr"`
+	Data     []xmlData `xml:"data"`
+}
+
+type xmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// graphics is content of yFiles node or edge graphics.
+type graphics struct {
+	geometry  *[4]float64 // x, y, width, height
+	label     string
+	path      *[4]float64 // sx, sy, tx, ty
+	points    [][2]float64
+	hasLabel  bool
+	hasPoints bool
+}
+
+// Read reads GraphML graph and builds layout graph from it.
+//
+// Node size is taken from yFiles geometry, then from data attributes width and height (points),
+// then from data attribute size (points, Gephi), and is 54x36 by default.
+// Node position is taken from yFiles geometry, edge paths from yFiles edge paths.
+// Edges from or to group nodes are skipped and logged, since layouts do not have edges of clusters.
+// Only first graph of document is read.
+func Read(r io.Reader) (*Graph, error) {
+	var doc xmlGraphML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("graphml: %w", err)
+	}
+	if len(doc.Graphs) == 0 {
+		return nil, errors.New("graphml: no graph")
+	}
+
+	rd := reader{
+		g: &Graph{
+			Data:     Data{},
+			IDs:      map[string]layout.NodeID{},
+			Nodes:    map[layout.NodeID]Node{},
+			Edges:    map[[2]layout.NodeID]Edge{},
+			Clusters: map[layout.ClusterID]Cluster{},
+		},
+		keys:     map[string]xmlKey{},
+		clusters: map[string]bool{},
+		layout: layout.Graph{
+			Nodes: map[layout.NodeID]layout.Node{},
+			Edges: map[[2]layout.NodeID]layout.Edge{},
+		},
+	}
+
+	for _, k := range doc.Keys {
+		rd.keys[k.ID] = k
+		if k.YFilesType != "" {
+			continue
+		}
+		key := Key{ID: k.ID, For: k.For, Name: k.Name, Type: k.Type}
+		if k.Default != nil {
+			key.Default = strings.TrimSpace(*k.Default)
+		}
+		rd.g.Keys = append(rd.g.Keys, key)
+	}
+
+	root := doc.Graphs[0]
+	rd.g.ID = root.ID
+	rd.g.Directed = root.EdgeDefault != "undirected"
+	rd.g.Data, _ = rd.data("graph", root.Data)
+
+	// edges of nested graphs can go to nodes of any graph, so nodes are read first
+	rd.graphs = append(rd.graphs, root)
+	if err := rd.readNodes(root, ""); err != nil {
+		return nil, err
+	}
+	for _, g := range rd.graphs {
+		if err := rd.readEdges(g); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(rd.layout.Clusters) == 0 {
+		rd.layout.Clusters = nil
+	}
+	rd.g.Layout = rd.layout
+	return rd.g, nil
+}
+
+type reader struct {
+	g        *Graph
+	keys     map[string]xmlKey
+	clusters map[string]bool
+	graphs   []xmlGraph
+	layout   layout.Graph
+}
+
+// data collects values of data elements with defaults of keys, and yFiles graphics.
+func (rd reader) data(kind string, elements []xmlData) (Data, graphics) {
+	d := Data{}
+	for _, k := range rd.keys {
+		if k.Default != nil && k.YFilesType == "" && (k.For == kind || k.For == "all") {
+			d[dataName(k)] = strings.TrimSpace(*k.Default)
+		}
+	}
+
+	var gr graphics
+	for _, e := range elements {
+		k, ok := rd.keys[e.Key]
+		if !ok {
+			d[e.Key] = strings.TrimSpace(e.Value)
+			continue
+		}
+		if k.YFilesType != "" {
+			gr.merge(parseGraphics(e.Inner))
+			continue
+		}
+		d[dataName(k)] = strings.TrimSpace(e.Value)
+	}
+	return d, gr
+}
+
+func dataName(k xmlKey) string {
+	if k.Name != "" {
+		return k.Name
+	}
+	return k.ID
+}
+
+// readNodes reads nodes of graph, nested graphs become clusters with given parent.
+func (rd *reader) readNodes(g xmlGraph, parent layout.ClusterID) error {
+	for _, n := range g.Nodes {
+		if n.ID == "" {
+			return errors.New("graphml: node without id")
+		}
+		if _, ok := rd.g.IDs[n.ID]; ok || rd.clusters[n.ID] {
+			return fmt.Errorf("graphml: duplicate node(%s)", n.ID)
+		}
+		data, gr := rd.data("node", n.Data)
+
+		if n.Graph != nil {
+			rd.clusters[n.ID] = true
+			rd.g.Clusters[n.ID] = Cluster{ID: n.ID, Label: gr.label, Data: data}
+			if rd.layout.Clusters == nil {
+				rd.layout.Clusters = map[layout.ClusterID]layout.Cluster{}
+			}
+			c := layout.Cluster{Parent: parent, Margin: defaultMargin}
+			if gr.geometry != nil {
+				c.Position = layout.Position{X: round(gr.geometry[0]), Y: round(gr.geometry[1])}
+				c.W, c.H = round(gr.geometry[2]), round(gr.geometry[3])
+			}
+			rd.layout.Clusters[n.ID] = c
+
+			rd.graphs = append(rd.graphs, *n.Graph)
+			if err := rd.readNodes(*n.Graph, n.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		id := layout.NodeID(len(rd.g.IDs) + 1)
+		rd.g.IDs[n.ID] = id
+		rd.g.Nodes[id] = Node{ID: n.ID, Label: gr.label, Data: data}
+
+		node := layout.Node{
+			W: round(dataFloat(data, defaultWidth, "width", "size")),
+			H: round(dataFloat(data, defaultHeight, "height", "size")),
+		}
+		if gr.geometry != nil {
+			node.Position = layout.Position{X: round(gr.geometry[0]), Y: round(gr.geometry[1])}
+			node.W, node.H = round(gr.geometry[2]), round(gr.geometry[3])
+		}
+		rd.layout.Nodes[id] = node
+
+		if parent != "" {
+			c := rd.layout.Clusters[parent]
+			c.Nodes = append(c.Nodes, id)
+			rd.layout.Clusters[parent] = c
+		}
+	}
+
+	return nil
+}
+
+func (rd *reader) readEdges(g xmlGraph) error {
+	for _, e := range g.Edges {
+		if rd.clusters[e.Source] || rd.clusters[e.Target] {
+			log.Printf("graphml: edge(%s) from node(%s) to node(%s) goes to group, it is skipped", e.ID, e.Source, e.Target)
+			continue
+		}
+		from, ok := rd.g.IDs[e.Source]
+		if !ok {
+			return fmt.Errorf("graphml: edge(%s) source node(%s) not found", e.ID, e.Source)
+		}
+		to, ok := rd.g.IDs[e.Target]
+		if !ok {
+			return fmt.Errorf("graphml: edge(%s) target node(%s) not found", e.ID, e.Target)
+		}
+
+		data, gr := rd.data("edge", e.Data)
+		key := [2]layout.NodeID{from, to}
+		edge, ok := rd.g.Edges[key]
+		if !ok {
+			edge = Edge{ID: e.ID, Source: e.Source, Target: e.Target, Data: Data{}}
+		}
+		edge.Directed = rd.g.Directed
+		if e.Directed != "" {
+			d, err := strconv.ParseBool(e.Directed)
+			if err != nil {
+				return fmt.Errorf("graphml: edge(%s) directed: %w", e.ID, err)
+			}
+			edge.Directed = d
+		}
+		for k, v := range data {
+			edge.Data[k] = v
+		}
+		if gr.hasLabel {
+			edge.Label = gr.label
+		}
+		rd.g.Edges[key] = edge
+
+		if from == to {
+			continue
+		}
+		le := rd.layout.Edges[key]
+		if gr.path != nil {
+			src, dst := rd.layout.Nodes[from].CenterXY(), rd.layout.Nodes[to].CenterXY()
+			le.Path = []layout.Position{{X: src.X + round(gr.path[0]), Y: src.Y + round(gr.path[1])}}
+			for _, p := range gr.points {
+				le.Path = append(le.Path, layout.Position{X: round(p[0]), Y: round(p[1])})
+			}
+			le.Path = append(le.Path, layout.Position{X: dst.X + round(gr.path[2]), Y: dst.Y + round(gr.path[3])})
+		}
+		rd.layout.Edges[key] = le
+	}
+	return nil
+}
+
+func (gr *graphics) merge(o graphics) {
+	if o.geometry != nil {
+		gr.geometry = o.geometry
+	}
+	if o.hasLabel {
+		gr.label, gr.hasLabel = o.label, true
+	}
+	if o.path != nil {
+		gr.path = o.path
+	}
+	if o.hasPoints {
+		gr.points, gr.hasPoints = o.points, true
+	}
+}
+
+// parseGraphics reads geometry, first label and path of yFiles graphics.
+// Only active realizer of group nodes is read.
+func parseGraphics(inner string) graphics {
+	var gr graphics
+	d := xml.NewDecoder(strings.NewReader(inner))
+	active, realizer := -1, 0
+	inLabel := false
+	var label strings.Builder
+	for {
+		t, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			attrs := make(map[string]string, len(t.Attr))
+			for _, a := range t.Attr {
+				attrs[a.Name.Local] = a.Value
+			}
+			switch t.Name.Local {
+			case "Realizers":
+				active, _ = strconv.Atoi(attrs["active"])
+				realizer = -1
+			case "GroupNode", "GenericGroupNode", "ShapeNode", "GenericNode":
+				if active >= 0 {
+					realizer++
+				}
+			case "Geometry":
+				if gr.geometry == nil && (active < 0 || realizer == active) {
+					gr.geometry = &[4]float64{attrFloat(attrs, "x"), attrFloat(attrs, "y"), attrFloat(attrs, "width"), attrFloat(attrs, "height")}
+				}
+			case "NodeLabel", "EdgeLabel":
+				if !gr.hasLabel && (active < 0 || realizer == active) {
+					inLabel = true
+				}
+			case "Path":
+				gr.path = &[4]float64{attrFloat(attrs, "sx"), attrFloat(attrs, "sy"), attrFloat(attrs, "tx"), attrFloat(attrs, "ty")}
+				gr.hasPoints = true
+			case "Point":
+				gr.points = append(gr.points, [2]float64{attrFloat(attrs, "x"), attrFloat(attrs, "y")})
+			}
+		case xml.CharData:
+			if inLabel {
+				label.Write(t)
+			}
+		case xml.EndElement:
+			if inLabel && (t.Name.Local == "NodeLabel" || t.Name.Local == "EdgeLabel") {
+				inLabel = false
+				gr.label, gr.hasLabel = strings.TrimSpace(label.String()), true
+			}
+		}
+	}
+	return gr
+}
+
+func attrFloat(attrs map[string]string, key string) float64 {
+	f, _ := strconv.ParseFloat(attrs[key], 64)
+	return f
+}
+
+func dataFloat(d Data, def float64, keys ...string) float64 {
+	for _, k := range keys {
+		if f, err := strconv.ParseFloat(d[k], 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+func round(v float64) int {
+	return int(math.Round(v))
+}
@@ -0,0 +1,357 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+const (
+	namespace       = "http://graphml.graphdrawing.org/xmlns"
+	namespaceYFiles = "http://www.yworks.com/xml/graphml"
+	keyNodeGraphics = "ng"
+	keyEdgeGraphics = "eg"
+)
+
+type xmlOutGraphML struct {
+	XMLName xml.Name    `xml:"graphml"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	XMLNSY  string      `xml:"xmlns:y,attr"`
+	Keys    []xmlOutKey `xml:"key"`
+	Graph   xmlOutGraph `xml:"graph"`
+}
+
+type xmlOutKey struct {
+	ID         string  `xml:"id,attr"`
+	For        string  `xml:"for,attr"`
+	Name       string  `xml:"attr.name,attr,omitempty"`
+	Type       string  `xml:"attr.type,attr,omitempty"`
+	YFilesType string  `xml:"yfiles.type,attr,omitempty"`
+	Default    *string `xml:"default"`
+}
+
+type xmlOutGraph struct {
+	ID          string       `xml:"id,attr"`
+	EdgeDefault string       `xml:"edgedefault,attr"`
+	Data        []xmlOutData `xml:"data"`
+	Nodes       []xmlOutNode `xml:"node"`
+	Edges       []xmlOutEdge `xml:"edge"`
+}
+
+type xmlOutNode struct {
+	ID         string       `xml:"id,attr"`
+	FolderType string       `xml:"yfiles.foldertype,attr,omitempty"`
+	Data       []xmlOutData `xml:"data"`
+	Graph      *xmlOutGraph `xml:"graph"`
+}
+
+type xmlOutEdge struct {
+	ID       string       `xml:"id,attr"`
+	Source   string       `xml:"source,attr"`
+	Target   string       `xml:"target,attr"`
+	Directed string       `xml:"directed,attr,omitempty"`
+	Data     []xmlOutData `xml:"data"`
+}
+
+type xmlOutData struct {
+	Key       string         `xml:"key,attr"`
+	Value     string         `xml:",chardata"`
+	ShapeNode *yShapeNode    `xml:"y:ShapeNode"`
+	GroupNode *yProxyNode    `xml:"y:ProxyAutoBoundsNode"`
+	Edge      *yPolyLineEdge `xml:"y:PolyLineEdge"`
+}
+
+type yShapeNode struct {
+	Geometry yGeometry `xml:"y:Geometry"`
+	Label    string    `xml:"y:NodeLabel"`
+}
+
+type yProxyNode struct {
+	Realizers yRealizers `xml:"y:Realizers"`
+}
+
+type yRealizers struct {
+	Active int        `xml:"active,attr"`
+	Group  yGroupNode `xml:"y:GroupNode"`
+}
+
+type yGroupNode struct {
+	Geometry yGeometry `xml:"y:Geometry"`
+	Label    string    `xml:"y:NodeLabel"`
+	State    yState    `xml:"y:State"`
+}
+
+type yState struct {
+	Closed bool `xml:"closed,attr"`
+}
+
+type yGeometry struct {
+	X      int `xml:"x,attr"`
+	Y      int `xml:"y,attr"`
+	Width  int `xml:"width,attr"`
+	Height int `xml:"height,attr"`
+}
+
+type yPolyLineEdge struct {
+	Path  yPath   `xml:"y:Path"`
+	Label *string `xml:"y:EdgeLabel"`
+}
+
+type yPath struct {
+	SX     int      `xml:"sx,attr"`
+	SY     int      `xml:"sy,attr"`
+	TX     int      `xml:"tx,attr"`
+	TY     int      `xml:"ty,attr"`
+	Points []yPoint `xml:"y:Point"`
+}
+
+type yPoint struct {
+	X int `xml:"x,attr"`
+	Y int `xml:"y,attr"`
+}
+
+// FromLayout wraps layout graph to be written as GraphML.
+// Node IDs are written as "n" followed by layout node ID, graph is directed.
+func FromLayout(g layout.Graph) *Graph {
+	gg := &Graph{
+		ID:       "G",
+		Directed: true,
+		Data:     Data{},
+		Layout:   g,
+		IDs:      make(map[string]layout.NodeID, len(g.Nodes)),
+		Nodes:    make(map[layout.NodeID]Node, len(g.Nodes)),
+		Edges:    make(map[[2]layout.NodeID]Edge, len(g.Edges)),
+		Clusters: make(map[layout.ClusterID]Cluster, len(g.Clusters)),
+	}
+	for n := range g.Nodes {
+		id := "n" + strconv.FormatUint(n, 10)
+		gg.IDs[id] = n
+		gg.Nodes[n] = Node{ID: id, Data: Data{}}
+	}
+	for e := range g.Edges {
+		gg.Edges[e] = Edge{Source: gg.Nodes[e[0]].ID, Target: gg.Nodes[e[1]].ID, Directed: true, Data: Data{}}
+	}
+	for c := range g.Clusters {
+		gg.Clusters[c] = Cluster{ID: c, Data: Data{}}
+	}
+	return gg
+}
+
+// Write writes graph as GraphML with positions from its layout graph in yFiles graphics, so it can be opened in yEd.
+// Node geometry is top left corner and size of node, edge path has bend points of Edge.Path
+// and offsets of its ends from centers of nodes.
+// Clusters are written as group nodes with nested graphs.
+// Output is deterministic: nodes are written in order of their IDs, edges in order of their ends.
+func Write(w io.Writer, g *Graph) error {
+	doc := xmlOutGraphML{
+		XMLNS:  namespace,
+		XMLNSY: namespaceYFiles,
+	}
+
+	// data of keys that are not declared get string keys
+	keyOf := make(map[string]map[string]string) // for -> data name -> key ID
+	used := make(map[string]bool)
+	declare := func(k Key) {
+		if keyOf[k.For] == nil {
+			keyOf[k.For] = map[string]string{}
+		}
+		name := k.Name
+		if name == "" {
+			name = k.ID
+		}
+		keyOf[k.For][name] = k.ID
+		used[k.ID] = true
+
+		xk := xmlOutKey{ID: k.ID, For: k.For, Name: k.Name, Type: k.Type}
+		if k.Default != "" {
+			def := k.Default
+			xk.Default = &def
+		}
+		doc.Keys = append(doc.Keys, xk)
+	}
+	for _, k := range g.Keys {
+		declare(k)
+	}
+	keyID := func(kind, name string) string {
+		if id, ok := keyOf[kind][name]; ok {
+			return id
+		}
+		if id, ok := keyOf["all"][name]; ok {
+			return id
+		}
+		id := name
+		for i := 0; used[id]; i++ {
+			id = kind + "_" + name + "_" + strconv.Itoa(i)
+		}
+		declare(Key{ID: id, For: kind, Name: name, Type: "string"})
+		return id
+	}
+	data := func(kind string, d Data) []xmlOutData {
+		names := make([]string, 0, len(d))
+		for k := range d {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		var out []xmlOutData
+		for _, k := range names {
+			out = append(out, xmlOutData{Key: keyID(kind, k), Value: d[k]})
+		}
+		return out
+	}
+
+	nodeGraphics, edgeGraphics := keyNodeGraphics, keyEdgeGraphics
+	for i := 0; used[nodeGraphics] || used[edgeGraphics]; i++ {
+		nodeGraphics, edgeGraphics = keyNodeGraphics+strconv.Itoa(i), keyEdgeGraphics+strconv.Itoa(i)
+	}
+	used[nodeGraphics], used[edgeGraphics] = true, true
+
+	edgeDefault := "undirected"
+	if g.Directed {
+		edgeDefault = "directed"
+	}
+	doc.Graph = xmlOutGraph{ID: g.ID, EdgeDefault: edgeDefault, Data: data("graph", g.Data)}
+
+	children := make(map[layout.ClusterID][]layout.ClusterID)
+	for id, c := range g.Layout.Clusters {
+		children[c.Parent] = append(children[c.Parent], id)
+	}
+	for _, ids := range children {
+		sort.Strings(ids)
+	}
+	inCluster := make(map[layout.NodeID]bool)
+	for _, c := range g.Layout.Clusters {
+		for _, n := range c.Nodes {
+			inCluster[n] = true
+		}
+	}
+
+	writeNode := func(n layout.NodeID) xmlOutNode {
+		node, ln := g.Nodes[n], g.Layout.Nodes[n]
+		label := node.Label
+		if label == "" {
+			label = node.ID
+		}
+		return xmlOutNode{
+			ID: node.ID,
+			Data: append(data("node", node.Data), xmlOutData{
+				Key: nodeGraphics,
+				ShapeNode: &yShapeNode{
+					Geometry: yGeometry{X: ln.X, Y: ln.Y, Width: ln.W, Height: ln.H},
+					Label:    label,
+				},
+			}),
+		}
+	}
+
+	var writeCluster func(id layout.ClusterID) xmlOutNode
+	writeCluster = func(id layout.ClusterID) xmlOutNode {
+		c, lc := g.Clusters[id], g.Layout.Clusters[id]
+		nested := &xmlOutGraph{ID: id + ":", EdgeDefault: edgeDefault}
+		for _, child := range children[id] {
+			nested.Nodes = append(nested.Nodes, writeCluster(child))
+		}
+		nodes := append([]layout.NodeID(nil), lc.Nodes...)
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+		for _, n := range nodes {
+			if _, ok := g.Nodes[n]; ok {
+				nested.Nodes = append(nested.Nodes, writeNode(n))
+			}
+		}
+		return xmlOutNode{
+			ID:         id,
+			FolderType: "group",
+			Data: append(data("node", c.Data), xmlOutData{
+				Key: nodeGraphics,
+				GroupNode: &yProxyNode{Realizers: yRealizers{Group: yGroupNode{
+					Geometry: yGeometry{X: lc.X, Y: lc.Y, Width: lc.W, Height: lc.H},
+					Label:    c.Label,
+				}}},
+			}),
+			Graph: nested,
+		}
+	}
+
+	for _, id := range children[""] {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, writeCluster(id))
+	}
+	nodes := make([]layout.NodeID, 0, len(g.Nodes))
+	for n := range g.Nodes {
+		if !inCluster[n] {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+	for _, n := range nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, writeNode(n))
+	}
+
+	edges := make([][2]layout.NodeID, 0, len(g.Edges))
+	for e := range g.Edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+	for i, e := range edges {
+		edge := g.Edges[e]
+		id := edge.ID
+		if id == "" {
+			id = "e" + strconv.Itoa(i)
+		}
+
+		pl := &yPolyLineEdge{Path: edgePath(g.Layout, e)}
+		if edge.Label != "" {
+			label := edge.Label
+			pl.Label = &label
+		}
+
+		xe := xmlOutEdge{
+			ID:     id,
+			Source: g.Nodes[e[0]].ID,
+			Target: g.Nodes[e[1]].ID,
+			Data:   append(data("edge", edge.Data), xmlOutData{Key: edgeGraphics, Edge: pl}),
+		}
+		if edge.Directed != g.Directed {
+			xe.Directed = strconv.FormatBool(edge.Directed)
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, xe)
+	}
+
+	doc.Keys = append(doc.Keys,
+		xmlOutKey{ID: nodeGraphics, For: "node", YFilesType: "nodegraphics"},
+		xmlOutKey{ID: edgeGraphics, For: "edge", YFilesType: "edgegraphics"},
+	)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// edgePath makes yFiles path from edge path: ends are offsets from centers of nodes, rest are bend points.
+func edgePath(g layout.Graph, e [2]layout.NodeID) yPath {
+	path := g.Edges[e].Path
+	if len(path) < 2 {
+		return yPath{}
+	}
+
+	src, dst := g.Nodes[e[0]].CenterXY(), g.Nodes[e[1]].CenterXY()
+	first, last := path[0], path[len(path)-1]
+	p := yPath{SX: first.X - src.X, SY: first.Y - src.Y, TX: last.X - dst.X, TY: last.Y - dst.Y}
+	for _, b := range path[1 : len(path)-1] {
+		p.Points = append(p.Points, yPoint{X: b.X, Y: b.Y})
+	}
+	return p
+}
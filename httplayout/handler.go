@@ -0,0 +1,293 @@
+// Package httplayout serves layouts over HTTP, Handler can be mounted in any net/http server.
+//
+// Request is POST with JSON body: graph in one of input formats, optional pipeline config and output format.
+// Graph is string for text formats, and object or string for json format.
+//
+//	{
+//	  "format": "dot",
+//	  "graph": "digraph { a -> b; a -> c }",
+//	  "config": {"type": "layers", "horizontal_assigner": {"type": "brandes_kopf", "delta": 40}},
+//	  "output": "svg"
+//	}
+//
+// Response is laid out graph as layoutjson JSON, as SVG or as HTML page with SVG viewer.
+// Errors are JSON objects with message in "error".
+package httplayout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gverger/go-graph-layout/internal/document"
+	"github.com/gverger/go-graph-layout/pipeline"
+	"github.com/gverger/go-graph-layout/raster"
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+const (
+	DefaultMaxBytes      = 1 << 20
+	DefaultMaxNodes      = 5000
+	DefaultMaxIterations = 10000
+	DefaultMaxWork       = 100000
+	DefaultMaxPixels     = 1 << 24
+	DefaultTimeout       = 10 * time.Second
+)
+
+// defaultSlots limits layouts of handlers without their own slots to one per CPU.
+var defaultSlots = make(chan struct{}, runtime.NumCPU())
+
+// Request is body of layout request.
+type Request struct {
+	Format string          `json:"format"` // jsonl, dot, graphml or json, json by default
+	Graph  json.RawMessage `json:"graph"`
+	Config pipeline.Config `json:"config"` // layers layout with defaults when missing
+	Output string          `json:"output"` // json, svg, html, png or pdf, json by default
+	Theme  string          `json:"theme"`  // theme of svg: light or dark, light by default
+}
+
+// Handler lays out graphs of requests. Zero values of fields mean defaults.
+// Layout is built from config for each request, so requests do not share state of layouts.
+//
+// Layouts can not be stopped, so layout that times out keeps running until it is done and keeps its slot.
+// Limits of iterations and work of configs bound how long that is, and slots bound how many layouts run at once.
+type Handler struct {
+	Registry      *pipeline.Registry // components of configs, pipeline.DefaultRegistry when nil
+	MaxBytes      int64              // maximum size of request body
+	MaxNodes      int                // maximum number of nodes in graph
+	MaxIterations int                // maximum iterations, epochs and steps of each component of config
+	MaxWork       int                // maximum work of config, see pipeline.Limits
+	MaxPixels     int                // maximum pixels of PNG images
+	Timeout       time.Duration      // maximum duration of layout and rendering, including wait for slot
+	Slots         chan struct{}      // capacity is number of layouts that run at once, one per CPU for all handlers when nil
+	ErrorLog      *log.Logger        // logs panics of layouts and rendering, standard logger when nil
+}
+
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e httpError) Error() string { return e.msg }
+
+func errorf(status int, format string, args ...any) error {
+	return httpError{status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, errorf(http.StatusMethodNotAllowed, "method %s is not allowed", r.Method))
+		return
+	}
+
+	contentType, out, err := h.serve(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(out)
+}
+
+func (h Handler) serve(r *http.Request) (contentType string, out []byte, err error) {
+	maxBytes := h.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBytes)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", nil, errorf(http.StatusRequestEntityTooLarge, "request is larger than %d bytes", maxBytes)
+		}
+		return "", nil, errorf(http.StatusBadRequest, "invalid request: %v", err)
+	}
+
+	switch req.Output {
+	case "", "json":
+		contentType = "application/json"
+		req.Output = "json"
+	case "svg":
+		contentType = "image/svg+xml"
+	case "html":
+		contentType = "text/html; charset=utf-8"
+	case "png":
+		contentType = "image/png"
+	case "pdf":
+		contentType = "application/pdf"
+	default:
+		return "", nil, errorf(http.StatusBadRequest, "unknown output format %q", req.Output)
+	}
+
+	opts := document.Options{Margin: document.DefaultMargin, Pixels: h.MaxPixels}
+	if opts.Pixels == 0 {
+		opts.Pixels = DefaultMaxPixels
+	}
+	if req.Theme != "" {
+		theme, ok := svg.Themes[req.Theme]
+		if !ok {
+			return "", nil, errorf(http.StatusBadRequest, "unknown theme %q", req.Theme)
+		}
+		opts.Theme = &theme
+	}
+
+	doc, err := h.readGraph(req, opts.Theme)
+	if err != nil {
+		return "", nil, err
+	}
+
+	config := req.Config
+	if config == nil {
+		config = pipeline.Config{"type": "layers"}
+	}
+	registry := h.Registry
+	if registry == nil {
+		registry = pipeline.DefaultRegistry
+	}
+	limits := pipeline.Limits{Iterations: h.MaxIterations, Work: h.MaxWork}
+	if limits.Iterations == 0 {
+		limits.Iterations = DefaultMaxIterations
+	}
+	if limits.Work == 0 {
+		limits.Work = DefaultMaxWork
+	}
+	l, err := registry.BuildLimited(config, limits)
+	if err != nil {
+		return "", nil, errorf(http.StatusBadRequest, "%v", err)
+	}
+
+	out, err = h.render(r.Context(), func() ([]byte, error) {
+		if err := doc.UpdateLayout(l); err != nil {
+			return nil, errorf(http.StatusUnprocessableEntity, "%v", err)
+		}
+		var b bytes.Buffer
+		if err := document.Write(&b, doc, req.Output, opts); err != nil {
+			if errors.Is(err, raster.ErrTooLarge) {
+				return nil, errorf(http.StatusRequestEntityTooLarge, "%v", err)
+			}
+			return nil, errorf(http.StatusInternalServerError, "%v", err)
+		}
+		return b.Bytes(), nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return contentType, out, nil
+}
+
+func (h Handler) readGraph(req Request, theme *svg.Theme) (*document.Document, error) {
+	format := req.Format
+	if format == "" {
+		format = "json"
+	}
+	if len(req.Graph) == 0 {
+		return nil, errorf(http.StatusBadRequest, "missing graph")
+	}
+
+	// text formats come as JSON strings, json graph can be object too
+	graph := []byte(req.Graph)
+	if req.Graph[0] == '"' {
+		var s string
+		if err := json.Unmarshal(req.Graph, &s); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid graph: %v", err)
+		}
+		graph = []byte(s)
+	} else if format != "json" {
+		return nil, errorf(http.StatusBadRequest, "graph in %s format should be string", format)
+	}
+
+	doc, err := document.Read(bytes.NewReader(graph), format, theme)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "%v", err)
+	}
+
+	maxNodes := h.MaxNodes
+	if maxNodes == 0 {
+		maxNodes = DefaultMaxNodes
+	}
+	if n := len(doc.Layout.Nodes); n > maxNodes {
+		return nil, errorf(http.StatusRequestEntityTooLarge, "graph has %d nodes, maximum is %d", n, maxNodes)
+	}
+	return doc, nil
+}
+
+// render runs layout and writes document when there is free slot, until timeout or until request is canceled.
+// Layout that times out keeps running in background until it is done, and frees its slot then.
+func (h Handler) render(ctx context.Context, f func() ([]byte, error)) ([]byte, error) {
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	slots := h.Slots
+	if slots == nil {
+		slots = defaultSlots
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, errorf(http.StatusServiceUnavailable, "too many layouts are running")
+	}
+
+	type result struct {
+		out []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-slots }()
+		// panic of layout or rendering is bug, it fails request instead of stopping server
+		defer func() {
+			if r := recover(); r != nil {
+				h.logf("httplayout: panic: %v\n%s", r, debug.Stack())
+				done <- result{err: errorf(http.StatusInternalServerError, "internal error")}
+			}
+		}()
+		out, err := f()
+		done <- result{out: out, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.out, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil, errorf(http.StatusServiceUnavailable, "request canceled")
+		}
+		return nil, errorf(http.StatusServiceUnavailable, "layout did not finish in %v", timeout)
+	}
+}
+
+func (h Handler) logf(format string, args ...any) {
+	if h.ErrorLog != nil {
+		h.ErrorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var herr httpError
+	if errors.As(err, &herr) {
+		status = herr.status
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{Error: strings.TrimSpace(err.Error())})
+}
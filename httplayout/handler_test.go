@@ -0,0 +1,218 @@
+package httplayout_test
+
+import (
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gverger/go-graph-layout/httplayout"
+	"github.com/gverger/go-graph-layout/layout"
+	"github.com/gverger/go-graph-layout/layoutjson"
+	"github.com/gverger/go-graph-layout/pipeline"
+)
+
+func post(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	tests := map[string]string{
+		"json object": `{"graph": {"nodes": [{"id": "a"}, {"id": "b", "w": 40, "h": 20}], "edges": [{"from": "a", "to": "b"}]}}`,
+		"json string": `{"format": "json", "graph": "{\"nodes\": [{\"id\": \"a\"}, {\"id\": \"b\"}], \"edges\": [{\"from\": \"a\", \"to\": \"b\"}]}"}`,
+		"dot":         `{"format": "dot", "graph": "digraph { a -> b }", "config": {"type": "layers", "ordering_assigner": {"type": "warfield", "epochs": 5}}}`,
+		"jsonl":       `{"format": "jsonl", "graph": "{\"id\": \"a\"}\n{\"id\": \"b\"}\n{\"from\": \"a\", \"to\": \"b\"}\n", "config": {"type": "forces", "max_steps": 10}, "output": "json"}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := post(t, httplayout.Handler{}, body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d: %s", w.Code, w.Body)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("unexpected content type %q", ct)
+			}
+
+			g, err := layoutjson.Unmarshal(w.Body.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(g.Nodes) != 2 || len(g.Edges) != 1 {
+				t.Fatalf("unexpected graph: %+v", g)
+			}
+			if e := g.Edges[0]; e.From != "a" || e.To != "b" || len(e.Path) < 2 {
+				t.Errorf("unexpected edge: %+v", e)
+			}
+		})
+	}
+}
+
+func TestHandlerSVG(t *testing.T) {
+	w := post(t, httplayout.Handler{}, `{"format": "dot", "graph": "digraph { a -> b }", "output": "svg", "theme": "dark"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "<svg") || !strings.Contains(w.Body.String(), "background: #1e1e1e") {
+		t.Errorf("unexpected body: %s", w.Body)
+	}
+}
+
+func TestHandlerHTML(t *testing.T) {
+	w := post(t, httplayout.Handler{}, `{"format": "dot", "graph": "digraph { a -> b }", "output": "html"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if !strings.HasPrefix(w.Body.String(), "<!DOCTYPE html>") || !strings.Contains(w.Body.String(), "<script>") {
+		t.Errorf("unexpected body: %s", w.Body)
+	}
+}
+
+func TestHandlerPNG(t *testing.T) {
+	w := post(t, httplayout.Handler{}, `{"format": "dot", "graph": "digraph { a -> b }", "output": "png"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if _, err := png.Decode(w.Body); err != nil {
+		t.Errorf("invalid png: %v", err)
+	}
+}
+
+func TestHandlerPDF(t *testing.T) {
+	w := post(t, httplayout.Handler{}, `{"format": "dot", "graph": "digraph { a -> b }", "output": "pdf"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if !strings.HasPrefix(w.Body.String(), "%PDF-") || !strings.HasSuffix(w.Body.String(), "%%EOF\n") {
+		t.Errorf("unexpected body: %q", w.Body)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	slow := pipeline.NewRegistry()
+	slow.Register(pipeline.KindLayout, "slow", func(p *pipeline.Params) any {
+		return layout.SequenceLayout{Layouts: []layout.Layout{sleepLayout(time.Second)}}
+	})
+	slow.Register(pipeline.KindLayout, "broken", func(p *pipeline.Params) any { return panicLayout{} })
+	full := make(chan struct{}, 1)
+	full <- struct{}{}
+
+	tests := []struct {
+		name    string
+		handler httplayout.Handler
+		body    string
+		status  int
+		msg     string
+	}{
+		{"invalid json", httplayout.Handler{}, `{"graph": `, http.StatusBadRequest, "invalid request"},
+		{"missing graph", httplayout.Handler{}, `{}`, http.StatusBadRequest, "missing graph"},
+		{"unknown input", httplayout.Handler{}, `{"format": "csv", "graph": "a,b"}`, http.StatusBadRequest, `unknown input format "csv"`},
+		{"unknown output", httplayout.Handler{}, `{"graph": {}, "output": "bmp"}`, http.StatusBadRequest, `unknown output format "bmp"`},
+		{"unknown theme", httplayout.Handler{}, `{"graph": {}, "output": "svg", "theme": "neon"}`, http.StatusBadRequest, `unknown theme "neon"`},
+		{"dot not string", httplayout.Handler{}, `{"format": "dot", "graph": {}}`, http.StatusBadRequest, "should be string"},
+		{"invalid graph", httplayout.Handler{}, `{"format": "dot", "graph": "digraph {"}`, http.StatusBadRequest, "dot: line 1"},
+		{"invalid config", httplayout.Handler{}, `{"graph": {}, "config": {"type": "layers", "epochs": 3}}`, http.StatusBadRequest, `pipeline: epochs: unknown parameter`},
+		{"layout panic", httplayout.Handler{Registry: slow}, `{"graph": {}, "config": {"type": "broken"}}`, http.StatusInternalServerError, "internal error"},
+		{"too large", httplayout.Handler{MaxBytes: 10}, `{"graph": {"nodes": []}}`, http.StatusRequestEntityTooLarge, "larger than 10 bytes"},
+		{"too many nodes", httplayout.Handler{MaxNodes: 1}, `{"graph": {"nodes": [{"id": "a"}, {"id": "b"}]}}`, http.StatusRequestEntityTooLarge, "graph has 2 nodes, maximum is 1"},
+		{"too many iterations", httplayout.Handler{}, `{"graph": {}, "config": {"type": "forces", "max_steps": 1000000}}`, http.StatusBadRequest, "max_steps: 1000000 is more than maximum of 10000 iterations"},
+		{"too much work", httplayout.Handler{}, `{"graph": {}, "config": {"type": "sequence", "layouts": [{"type": "forces", "max_steps": 10000}, {"type": "forces", "max_steps": 10000}, {"type": "forces", "max_steps": 10000}, {"type": "forces", "max_steps": 10000}]}}`, http.StatusBadRequest, "maximum is 100000"},
+		{"too many pixels", httplayout.Handler{}, `{"graph": {"nodes": [{"id": "a", "w": 40000, "h": 40000}]}, "output": "png"}`, http.StatusRequestEntityTooLarge, "raster: image is too large"},
+		{"no free slot", httplayout.Handler{Slots: full, Timeout: 10 * time.Millisecond}, `{"graph": {}}`, http.StatusServiceUnavailable, "too many layouts are running"},
+		{"timeout", httplayout.Handler{Registry: slow, Timeout: 10 * time.Millisecond}, `{"graph": {}, "config": {"type": "slow"}}`, http.StatusServiceUnavailable, "did not finish"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := post(t, tt.handler, tt.body)
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+			var resp struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(resp.Error, tt.msg) {
+				t.Errorf("expected error with %q, got %q", tt.msg, resp.Error)
+			}
+		})
+	}
+
+	t.Run("method", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		httplayout.Handler{}.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+			t.Errorf("unexpected response %d: %v", w.Code, w.Header())
+		}
+	})
+}
+
+func TestHandlerSlots(t *testing.T) {
+	slow := pipeline.NewRegistry()
+	slow.Register(pipeline.KindLayout, "slow", func(p *pipeline.Params) any { return sleepLayout(100 * time.Millisecond) })
+	slots := make(chan struct{}, 1)
+	h := httplayout.Handler{Registry: slow, Slots: slots, Timeout: 10 * time.Millisecond}
+
+	// layout that timed out keeps its slot until it is done
+	if w := post(t, h, `{"graph": {}, "config": {"type": "slow"}}`); !strings.Contains(w.Body.String(), "did not finish") {
+		t.Fatalf("expected timeout, got %d: %s", w.Code, w.Body)
+	}
+	if w := post(t, h, `{"graph": {}, "config": {"type": "slow"}}`); !strings.Contains(w.Body.String(), "too many layouts are running") {
+		t.Fatalf("expected busy slots, got %d: %s", w.Code, w.Body)
+	}
+
+	h = httplayout.Handler{Slots: slots, Timeout: time.Second}
+	if w := post(t, h, `{"graph": {"nodes": [{"id": "a"}]}}`); w.Code != http.StatusOK {
+		t.Errorf("expected slot to be free after layout is done, got %d: %s", w.Code, w.Body)
+	}
+	if len(slots) != 0 {
+		t.Errorf("slot is not freed")
+	}
+}
+
+type sleepLayout time.Duration
+
+func (l sleepLayout) UpdateGraphLayout(g layout.Graph) { time.Sleep(time.Duration(l)) }
+
+type panicLayout struct{}
+
+func (panicLayout) UpdateGraphLayout(g layout.Graph) { panic("broken layout") }
+
+func TestServer(t *testing.T) {
+	s := httptest.NewServer(httplayout.Handler{})
+	defer s.Close()
+
+	resp, err := http.Post(s.URL, "application/json", strings.NewReader(`{"format": "dot", "graph": "digraph { a -> b -> c }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	var g layoutjson.Graph
+	if err := json.NewDecoder(resp.Body).Decode(&g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 3 {
+		t.Errorf("unexpected graph: %+v", g)
+	}
+}
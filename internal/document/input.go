@@ -0,0 +1,222 @@
+// Package document reads graphs in all supported formats and writes them with their layout, for commands and services.
+package document
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/nikolaydubina/jsonl-graph/graph"
+
+	"github.com/gverger/go-graph-layout/dot"
+	"github.com/gverger/go-graph-layout/graphml"
+	"github.com/gverger/go-graph-layout/layout"
+	"github.com/gverger/go-graph-layout/layoutjson"
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+// Document is graph read from any input format, with what is needed to render it.
+type Document struct {
+	Layout        layout.Graph
+	IDs           map[uint64]string // node ID in input
+	Titles        map[uint64]string
+	Data          map[uint64]map[string]interface{} // rendered as table in node
+	ClusterTitles map[string]string
+	EdgeTitles    map[[2]uint64]string // labels of edges that have them
+	Directed      bool
+	EdgeDirected  map[[2]uint64]bool   // direction of edges that differ from Directed
+	DOT           *dot.Graph           // original DOT graph, if input is DOT
+	Layered       *layout.LayeredGraph // layers of graph, if layout is layered
+}
+
+// UpdateLayout runs layout on graph. Layered layouts also keep layers of graph, to render it as text.
+// Invalid clusters and layered graphs are reported as error.
+func (d *Document) UpdateLayout(l layout.Layout) error {
+	d.Layered = nil
+	if err := d.Layout.ValidateClusters(); err != nil {
+		return fmt.Errorf("layout: %w", err)
+	}
+	if layered, ok := l.(interface {
+		LayoutLayered(g layout.Graph) (layout.LayeredGraph, error)
+	}); ok {
+		lg, err := layered.LayoutLayered(d.Layout)
+		if err != nil {
+			return fmt.Errorf("layout: %w", err)
+		}
+		d.Layered = &lg
+		return nil
+	}
+	l.UpdateGraphLayout(d.Layout)
+	return nil
+}
+
+// FormatOf guesses input format from file extension, JSONL by default.
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return "dot"
+	case ".graphml":
+		return "graphml"
+	case ".json":
+		return "json"
+	default:
+		return "jsonl"
+	}
+}
+
+// Read reads document in format: jsonl, dot, graphml or json.
+// Nodes without size are sized to fit their text with padding and font size of theme, svg.DefaultTheme when nil.
+// Theme should be same as theme that renders document.
+func Read(r io.Reader, format string, theme *svg.Theme) (*Document, error) {
+	switch format {
+	case "jsonl":
+		return readJSONL(r, theme)
+	case "dot":
+		return readDOT(r)
+	case "graphml":
+		return readGraphML(r)
+	case "json":
+		return readJSON(r, theme)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+}
+
+func newDocument() *Document {
+	return &Document{
+		Layout: layout.Graph{
+			Nodes: map[uint64]layout.Node{},
+			Edges: map[[2]uint64]layout.Edge{},
+		},
+		IDs:           map[uint64]string{},
+		Titles:        map[uint64]string{},
+		Data:          map[uint64]map[string]interface{}{},
+		ClusterTitles: map[string]string{},
+		EdgeTitles:    map[[2]uint64]string{},
+		Directed:      true,
+		EdgeDirected:  map[[2]uint64]bool{},
+	}
+}
+
+func readJSONL(r io.Reader, theme *svg.Theme) (*Document, error) {
+	gd, err := graph.NewGraphFromJSONL(r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := newDocument()
+	sizer := svg.Graph{Theme: theme}
+	for id, node := range gd.Nodes {
+		// compute w and h for nodes, since width and height of node depends on content
+		w, h := sizer.NodeSize(svg.Node{Title: node.ID(), NodeData: node})
+		doc.Layout.Nodes[id] = layout.Node{W: w, H: h}
+		doc.IDs[id] = node.ID()
+		doc.Titles[id] = node.ID()
+		doc.Data[id] = node
+	}
+	for e := range gd.Edges {
+		// self loops are not supported by layouts
+		if e[0] != e[1] {
+			doc.Layout.Edges[e] = layout.Edge{}
+		}
+	}
+	return doc, nil
+}
+
+func readDOT(r io.Reader) (*Document, error) {
+	g, err := dot.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := newDocument()
+	doc.Layout = g.Layout
+	doc.Directed = g.Directed
+	doc.DOT = g
+	for id, node := range g.Nodes {
+		doc.IDs[id] = node.ID
+		doc.Titles[id] = node.Label()
+	}
+	for id, c := range g.Clusters {
+		doc.ClusterTitles[id] = c.Label()
+	}
+	for e, edge := range g.Edges {
+		if label := edge.Attrs["label"]; label != "" {
+			doc.EdgeTitles[e] = label
+		}
+	}
+	return doc, nil
+}
+
+func readGraphML(r io.Reader) (*Document, error) {
+	g, err := graphml.Read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := newDocument()
+	doc.Layout = g.Layout
+	doc.Directed = g.Directed
+	for id, node := range g.Nodes {
+		doc.IDs[id] = node.ID
+		doc.Titles[id] = node.ID
+		if node.Label != "" {
+			doc.Titles[id] = node.Label
+		}
+	}
+	for id, c := range g.Clusters {
+		doc.ClusterTitles[id] = c.Label
+	}
+	for e, edge := range g.Edges {
+		if edge.Label != "" {
+			doc.EdgeTitles[e] = edge.Label
+		}
+		if edge.Directed != g.Directed {
+			doc.EdgeDirected[e] = edge.Directed
+		}
+	}
+	return doc, nil
+}
+
+// readJSON reads graph in layoutjson schema, nodes without size are sized by their labels.
+func readJSON(r io.Reader, theme *svg.Theme) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	g, err := layoutjson.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	lg, ids, err := g.Layout()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := newDocument()
+	doc.Layout = lg
+	sizer := svg.Graph{Theme: theme}
+	for _, node := range g.Nodes {
+		id := ids[node.ID]
+		doc.IDs[id] = node.ID
+		doc.Titles[id] = node.ID
+		if node.Label != "" {
+			doc.Titles[id] = node.Label
+		}
+		if node.W == 0 && node.H == 0 {
+			n := doc.Layout.Nodes[id]
+			n.W, n.H = sizer.NodeSize(svg.Node{Title: doc.Titles[id]})
+			doc.Layout.Nodes[id] = n
+		}
+	}
+	for _, c := range g.Clusters {
+		doc.ClusterTitles[c.ID] = c.Label
+	}
+	for _, e := range g.Edges {
+		if e.Label != nil && e.Label.Text != "" {
+			doc.EdgeTitles[[2]uint64{ids[e.From], ids[e.To]}] = e.Label.Text
+		}
+	}
+	return doc, nil
+}
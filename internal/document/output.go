@@ -0,0 +1,317 @@
+package document
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gverger/go-graph-layout/dot"
+	"github.com/gverger/go-graph-layout/layoutjson"
+	"github.com/gverger/go-graph-layout/pdf"
+	"github.com/gverger/go-graph-layout/raster"
+	"github.com/gverger/go-graph-layout/svg"
+	"github.com/gverger/go-graph-layout/term"
+)
+
+// DefaultMargin is space around SVG images, so borders and arrows are not cut off.
+const DefaultMargin = 10
+
+// Options change how documents are rendered.
+type Options struct {
+	Theme  *svg.Theme // theme of SVG, svg.DefaultTheme when nil
+	Margin int        // space around SVG image
+	Width  int        // width of SVG image, 0 is width of graph
+	Height int        // height of SVG image, 0 is height of graph
+	Fit    bool       // SVG image fills its container
+	DPI    float64    // resolution of PNG image, raster.DefaultDPI when 0
+	Pixels int        // maximum pixels of PNG image, 0 is no limit
+
+	PageWidth  float64 // width of PDF pages in points, 0 is width of graph
+	PageHeight float64 // height of PDF pages in points, 0 is height of graph
+}
+
+// Write writes document in format: svg, html, png, pdf, json, dot, text or ascii.
+// HTML is self-contained page with SVG and interactive viewer.
+// PNG image and PDF document are drawn like SVG, with same theme and margin.
+// Text is drawn with box-drawing characters, ascii only with ASCII characters, both need layered layout.
+func Write(w io.Writer, doc *Document, format string, opts Options) error {
+	switch format {
+	case "svg":
+		_, err := svgDocument(doc, opts).WriteTo(w)
+		return err
+	case "html":
+		_, err := svg.HTML{Title: "Graph", SVG: svgDocument(doc, opts)}.WriteTo(w)
+		return err
+	case "png":
+		r := raster.Renderer{DPI: opts.DPI, Margin: opts.Margin, Theme: opts.Theme, MaxPixels: opts.Pixels}
+		return r.WritePNG(w, svgGraph(doc, opts))
+	case "pdf":
+		r := pdf.Renderer{Title: "Graph", Margin: opts.Margin, Theme: opts.Theme, PageWidth: opts.PageWidth, PageHeight: opts.PageHeight}
+		return r.Write(w, svgGraph(doc, opts))
+	case "json":
+		return writeJSON(w, doc)
+	case "dot":
+		return writeDOT(w, doc)
+	case "text", "ascii":
+		if doc.Layered == nil {
+			return errors.New("text output needs layers layout")
+		}
+		r := term.Renderer{ASCII: format == "ascii", Titles: doc.Titles, Undirected: !doc.Directed}
+		_, err := io.WriteString(w, r.Render(doc.Layout, *doc.Layered))
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func svgDocument(doc *Document, opts Options) svg.SVG {
+	graph := svgGraph(doc, opts)
+	return svg.SVG{
+		ID:          "svg-root",
+		Theme:       opts.Theme,
+		Definitions: graph.Markers(),
+		Body:        graph,
+		Margin:      opts.Margin,
+		Width:       opts.Width,
+		Height:      opts.Height,
+		Fit:         opts.Fit,
+	}
+}
+
+// svgGraph is graph of document with theme of options.
+func svgGraph(doc *Document, opts Options) svg.Graph {
+	graph := svg.Graph{
+		ID:       "graph-root",
+		Nodes:    map[uint64]svg.Node{},
+		Edges:    map[[2]uint64]svg.Edge{},
+		Clusters: map[string]svg.Cluster{},
+		Theme:    opts.Theme,
+	}
+
+	for id, node := range doc.Layout.Nodes {
+		graph.Nodes[id] = svg.Node{
+			ID:       fmt.Sprintf("%d", id),
+			X:        node.X,
+			Y:        node.Y,
+			W:        node.W,
+			H:        node.H,
+			Title:    doc.Titles[id],
+			NodeData: doc.Data[id],
+			Style:    dotStyle(doc.nodeAttrs(id)),
+			Shape:    dotShape(doc.nodeAttrs(id)),
+		}
+	}
+
+	for e, edata := range doc.Layout.Edges {
+		path := make([][2]int, 0, len(edata.Path))
+		for _, p := range edata.Path {
+			path = append(path, [2]int{p.X, p.Y})
+		}
+		head, tail := arrows(doc, e)
+		graph.Edges[e] = svg.Edge{Path: path, Head: head, Tail: tail, Style: dotStyle(doc.edgeAttrs(e))}
+	}
+
+	for id, c := range doc.Layout.Clusters {
+		graph.Clusters[id] = svg.Cluster{
+			ID:    id,
+			X:     c.X,
+			Y:     c.Y,
+			W:     c.W,
+			H:     c.H,
+			Title: doc.ClusterTitles[id],
+			Style: dotStyle(doc.clusterAttrs(id)),
+		}
+	}
+
+	return graph
+}
+
+// arrows are arrows of edge: directed edges have arrow at head.
+// Edges of DOT graphs follow dir, arrowhead and arrowtail attributes.
+func arrows(doc *Document, e [2]uint64) (head, tail svg.Arrow) {
+	attrs := doc.edgeAttrs(e)
+
+	dir := attrs["dir"]
+	if dir == "" {
+		directed, ok := doc.EdgeDirected[e]
+		if !ok {
+			directed = doc.Directed
+		}
+		dir = "none"
+		if directed {
+			dir = "forward"
+		}
+	}
+	if dir == "forward" || dir == "both" {
+		head = dotArrow(attrs["arrowhead"])
+	}
+	if dir == "back" || dir == "both" {
+		tail = dotArrow(attrs["arrowtail"])
+	}
+	return head, tail
+}
+
+// nodeAttrs are DOT attributes of node, nil for other inputs.
+func (d *Document) nodeAttrs(id uint64) dot.Attrs {
+	if d.DOT == nil {
+		return nil
+	}
+	return d.DOT.Nodes[id].Attrs
+}
+
+func (d *Document) edgeAttrs(e [2]uint64) dot.Attrs {
+	if d.DOT == nil {
+		return nil
+	}
+	return d.DOT.Edges[e].Attrs
+}
+
+func (d *Document) clusterAttrs(id string) dot.Attrs {
+	if d.DOT == nil {
+		return nil
+	}
+	return d.DOT.Clusters[id].Attrs
+}
+
+// dotStyle is style from Graphviz attributes: class, color, fillcolor, penwidth, style, fontname, fontsize and fontcolor.
+// Font size is in points, it is rounded to pixels.
+func dotStyle(attrs dot.Attrs) svg.Style {
+	style := svg.Style{
+		Class:      attrs["class"],
+		Fill:       attrs["fillcolor"],
+		Stroke:     attrs["color"],
+		FontFamily: attrs["fontname"],
+		FontColor:  attrs["fontcolor"],
+	}
+	if w, err := strconv.ParseFloat(attrs["penwidth"], 64); err == nil && w >= 0 {
+		style.StrokeWidth = w
+	}
+	if size, err := strconv.ParseFloat(attrs["fontsize"], 64); err == nil && size > 0 {
+		style.FontSize = int(math.Round(size))
+	}
+	for _, s := range strings.Split(attrs["style"], ",") {
+		switch strings.TrimSpace(s) {
+		case "dashed":
+			style.Dash = "5 3"
+		case "dotted":
+			style.Dash = "1 3"
+		case "filled":
+			if style.Fill == "" {
+				style.Fill = attrs["color"]
+			}
+		}
+	}
+	return style
+}
+
+// dotShape is closest shape to Graphviz node shape, nodes without shape are HTML boxes.
+func dotShape(attrs dot.Attrs) svg.Shape {
+	switch attrs["shape"] {
+	case "box", "rect", "rectangle", "square":
+		for _, s := range strings.Split(attrs["style"], ",") {
+			if strings.TrimSpace(s) == "rounded" {
+				return svg.ShapeRounded
+			}
+		}
+		return svg.ShapeRect
+	case "ellipse", "oval", "circle":
+		return svg.ShapeEllipse
+	case "diamond":
+		return svg.ShapeDiamond
+	case "hexagon":
+		return svg.ShapeHexagon
+	case "cylinder":
+		return svg.ShapeCylinder
+	case "record", "Mrecord":
+		return svg.ShapeRecord
+	default:
+		return ""
+	}
+}
+
+// dotArrow is closest arrow to Graphviz arrow shape.
+func dotArrow(shape string) svg.Arrow {
+	switch shape {
+	case "none":
+		return svg.ArrowNone
+	case "empty", "onormal", "vee", "open":
+		return svg.ArrowOpen
+	case "diamond", "odiamond", "ediamond":
+		return svg.ArrowDiamond
+	case "dot", "odot":
+		return svg.ArrowDot
+	default:
+		return svg.ArrowNormal
+	}
+}
+
+// writeJSON writes graph in layoutjson schema with node IDs and titles of input, edge labels are at middle of edge paths.
+func writeJSON(w io.Writer, doc *Document) error {
+	g := layoutjson.FromLayout(doc.Layout)
+
+	// FromLayout names nodes by layout IDs
+	idOf := make(map[string]string, len(doc.IDs))
+	for id, name := range doc.IDs {
+		idOf[strconv.FormatUint(id, 10)] = name
+	}
+	for i, node := range g.Nodes {
+		id, _ := strconv.ParseUint(node.ID, 10, 64)
+		g.Nodes[i].ID = doc.IDs[id]
+		if title := doc.Titles[id]; title != doc.IDs[id] {
+			g.Nodes[i].Label = title
+		}
+	}
+	for i, edge := range g.Edges {
+		from, _ := strconv.ParseUint(edge.From, 10, 64)
+		to, _ := strconv.ParseUint(edge.To, 10, 64)
+		if title := doc.EdgeTitles[[2]uint64{from, to}]; title != "" {
+			g.Edges[i].Label = layoutjson.NewLabel(title, edge.Path)
+		}
+		g.Edges[i].From, g.Edges[i].To = idOf[edge.From], idOf[edge.To]
+	}
+	for i, c := range g.Clusters {
+		for j, n := range c.Nodes {
+			c.Nodes[j] = idOf[n]
+		}
+		g.Clusters[i].Label = doc.ClusterTitles[c.ID]
+	}
+
+	data, err := layoutjson.Marshal(g)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeDOT writes original DOT graph with positions, other inputs are converted to DOT with node IDs and titles.
+func writeDOT(w io.Writer, doc *Document) error {
+	g := doc.DOT
+	if g == nil {
+		g = dot.FromLayout(doc.Layout)
+		g.Directed = doc.Directed
+		g.IDs = make(map[string]uint64, len(g.Nodes))
+		for id, node := range g.Nodes {
+			node.ID = doc.IDs[id]
+			g.IDs[node.ID] = id
+			if doc.Titles[id] != node.ID {
+				node.Attrs["label"] = doc.Titles[id]
+			}
+			g.Nodes[id] = node
+		}
+		for e, edge := range g.Edges {
+			edge.From, edge.To = doc.IDs[e[0]], doc.IDs[e[1]]
+			g.Edges[e] = edge
+		}
+		for id, c := range g.Clusters {
+			if title := doc.ClusterTitles[id]; title != "" {
+				c.Attrs["label"] = title
+			}
+		}
+	}
+	g.Layout = doc.Layout
+	return dot.Write(w, g)
+}
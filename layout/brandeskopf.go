@@ -11,6 +11,8 @@ import (
 // Produces result such that neighbors are close and long edges cross Layers are straight.
 // Works on fully connected graphs.
 // Assuming nodes do not have width.
+// Segments of heavier edges are preferred for alignment, so they are more likely to be vertical.
+// When graph has clusters, nodes are moved apart so that cluster boxes do not overlap.
 type BrandesKopfLayersNodesHorizontalAssigner struct {
 	Delta       int  // distance between nodes, including fake ones
 	TopDownOnly bool // true if running the 2 top down strategies only (better for trees)
@@ -51,7 +53,7 @@ func (x LayoutResult) width() int {
 	return x.maxX - x.minX
 }
 
-func (s BrandesKopfLayersNodesHorizontalAssigner) NodesHorizontalCoordinates(_ Graph, g LayeredGraph) map[uint64]int {
+func (s BrandesKopfLayersNodesHorizontalAssigner) NodesHorizontalCoordinates(gr Graph, g LayeredGraph) map[uint64]int {
 	neighbors := computeOrderedNeighbors(g)
 	typeOneSegments := preprocessing(g, neighbors)
 
@@ -91,6 +93,13 @@ func (s BrandesKopfLayersNodesHorizontalAssigner) NodesHorizontalCoordinates(_ G
 		x[n] = (place[1] + place[2]) / 2
 	}
 
+	// clusters boxes should not overlap, this takes into account node widths
+	if len(gr.Clusters) > 0 {
+		t := newClusterTree(gr)
+		t.addFakeNodes(g)
+		t.separate(gr, g, x, s.Delta)
+	}
+
 	return x
 }
 
@@ -162,6 +171,65 @@ func preprocessing(g LayeredGraph, n Neighbors) (typeOneSegments map[[2]uint64]b
 	return typeOneSegments
 }
 
+// medianNeighbors is range of indexes of median neighbors, that node can be aligned with.
+// When weights differ, medians split total weight of segments in half, and heavier of two medians is preferred.
+// This keeps segments of heavy edges vertical.
+func medianNeighbors(neighbors []uint64, weight func(u uint64) int) (lo, hi int) {
+	d := len(neighbors)
+	lo, hi = (d-1)/2, min((d+1)/2, d-1)
+
+	total := 0
+	uniform := true
+	for _, u := range neighbors {
+		total += weight(u)
+		if weight(u) != weight(neighbors[0]) {
+			uniform = false
+		}
+	}
+	if uniform {
+		return lo, hi
+	}
+
+	lo, hi = -1, -1
+	acc := 0
+	for i, u := range neighbors {
+		acc += weight(u)
+		if lo < 0 && 2*acc >= total {
+			lo = i
+		}
+		if hi < 0 && 2*acc > total {
+			hi = i
+		}
+	}
+
+	switch {
+	case weight(neighbors[lo]) > weight(neighbors[hi]):
+		hi = lo
+	case weight(neighbors[hi]) > weight(neighbors[lo]):
+		lo = hi
+	}
+	return lo, hi
+}
+
+// isHeaviest tells that node does not have heavier segment to the same side as given segment.
+// Node is aligned only along its heaviest segments, so heavy edges win over light ones.
+func isHeaviest(weights map[[2]uint64]int, segment [2]uint64, node uint64, others []uint64, othersBelow bool) bool {
+	if len(weights) == 0 {
+		return true
+	}
+	w := segmentWeight(weights, segment)
+	for _, o := range others {
+		s := [2]uint64{o, node}
+		if othersBelow {
+			s = [2]uint64{node, o}
+		}
+		if segmentWeight(weights, s) > w {
+			return false
+		}
+	}
+	return true
+}
+
 type singleDirAlgo interface {
 	verticalAlignment(g LayeredGraph, typeOneSegments map[[2]uint64]bool, n Neighbors) (root map[uint64]uint64, align map[uint64]uint64)
 
@@ -190,10 +258,11 @@ func (s TopLeft) verticalAlignment(g LayeredGraph, typeOneSegments map[[2]uint64
 		for _, v := range layers[i] {
 			upNeighbors := n.Up[v]
 			if d := len(upNeighbors); d > 0 {
-				for m := (d - 1) / 2; m <= (d+1)/2 && m < len(upNeighbors); m++ {
+				lo, hi := medianNeighbors(upNeighbors, func(u uint64) int { return segmentWeight(g.Weights, [2]uint64{u, v}) })
+				for m := lo; m <= hi; m++ {
 					if align[v] == v {
 						u := upNeighbors[m]
-						if !typeOneSegments[[2]uint64{u, v}] && r < g.NodePosition[u].Order {
+						if !typeOneSegments[[2]uint64{u, v}] && r < g.NodePosition[u].Order && isHeaviest(g.Weights, [2]uint64{u, v}, u, n.Down[u], true) {
 							align[u] = v
 							root[v] = root[u]
 							align[v] = root[v]
@@ -272,6 +341,9 @@ func (s TopLeft) horizontalCompaction(g LayeredGraph, root map[uint64]uint64, al
 	// class offsets
 	for i := 0; i < len(layers); i++ {
 		layer := layers[i]
+		if len(layer) == 0 {
+			continue
+		}
 		vfirst := layer[0]
 		if sink[vfirst] == vfirst {
 			if shift[sink[vfirst]] == math.MaxInt {
@@ -333,14 +405,11 @@ func (s TopRight) verticalAlignment(g LayeredGraph, typeOneSegments map[[2]uint6
 			v := layers[i][j]
 			upNeighbors := n.Up[v]
 			if d := len(upNeighbors); d > 0 {
-				first := (d + 1) / 2
-				if first >= d {
-					first = d - 1
-				}
-				for m := first; m >= (d-1)/2; m-- {
+				lo, hi := medianNeighbors(upNeighbors, func(u uint64) int { return segmentWeight(g.Weights, [2]uint64{u, v}) })
+				for m := hi; m >= lo; m-- {
 					if align[v] == v {
 						u := upNeighbors[m]
-						if !typeOneSegments[[2]uint64{u, v}] && r > g.NodePosition[u].Order {
+						if !typeOneSegments[[2]uint64{u, v}] && r > g.NodePosition[u].Order && isHeaviest(g.Weights, [2]uint64{u, v}, u, n.Down[u], true) {
 							align[u] = v
 							root[v] = root[u]
 							align[v] = root[v]
@@ -419,6 +488,9 @@ func (s TopRight) horizontalCompaction(g LayeredGraph, root map[uint64]uint64, a
 	// class offsets
 	for i := 0; i < len(layers); i++ {
 		layer := layers[i]
+		if len(layer) == 0 {
+			continue
+		}
 		vfirst := layer[len(layer)-1]
 		if sink[vfirst] == vfirst {
 			if shift[sink[vfirst]] == math.MinInt {
@@ -480,10 +552,11 @@ func (s BottomLeft) verticalAlignment(g LayeredGraph, typeOneSegments map[[2]uin
 		for _, v := range layers[i] {
 			downNeighbors := n.Down[v]
 			if d := len(downNeighbors); d > 0 {
-				for m := (d - 1) / 2; m <= (d+1)/2 && m < len(downNeighbors); m++ {
+				lo, hi := medianNeighbors(downNeighbors, func(u uint64) int { return segmentWeight(g.Weights, [2]uint64{v, u}) })
+				for m := lo; m <= hi; m++ {
 					if align[v] == v {
 						u := downNeighbors[m]
-						if !typeOneSegments[[2]uint64{v, u}] && r < g.NodePosition[u].Order {
+						if !typeOneSegments[[2]uint64{v, u}] && r < g.NodePosition[u].Order && isHeaviest(g.Weights, [2]uint64{v, u}, u, n.Up[u], false) {
 							align[u] = v
 							root[v] = root[u]
 							align[v] = root[v]
@@ -562,6 +635,9 @@ func (s BottomLeft) horizontalCompaction(g LayeredGraph, root map[uint64]uint64,
 	// class offsets
 	for i := len(layers) - 1; i >= 0; i-- {
 		layer := layers[i]
+		if len(layer) == 0 {
+			continue
+		}
 		vfirst := layer[0]
 		if sink[vfirst] == vfirst {
 			if shift[sink[vfirst]] == math.MaxInt {
@@ -623,14 +699,11 @@ func (s BottomRight) verticalAlignment(g LayeredGraph, typeOneSegments map[[2]ui
 			v := layers[i][j]
 			downNeighbors := n.Down[v]
 			if d := len(downNeighbors); d > 0 {
-				first := (d + 1) / 2
-				if first >= d {
-					first = d - 1
-				}
-				for m := first; m >= (d-1)/2; m-- {
+				lo, hi := medianNeighbors(downNeighbors, func(u uint64) int { return segmentWeight(g.Weights, [2]uint64{v, u}) })
+				for m := hi; m >= lo; m-- {
 					if align[v] == v {
 						u := downNeighbors[m]
-						if !typeOneSegments[[2]uint64{v, u}] && r > g.NodePosition[u].Order {
+						if !typeOneSegments[[2]uint64{v, u}] && r > g.NodePosition[u].Order && isHeaviest(g.Weights, [2]uint64{v, u}, u, n.Up[u], false) {
 							align[u] = v
 							root[v] = root[u]
 							align[v] = root[v]
@@ -709,6 +782,9 @@ func (s BottomRight) horizontalCompaction(g LayeredGraph, root map[uint64]uint64
 	// class offsets
 	for i := len(layers) - 1; i >= 0; i-- {
 		layer := layers[i]
+		if len(layer) == 0 {
+			continue
+		}
 		vfirst := layer[len(layer)-1]
 		if sink[vfirst] == vfirst {
 			if shift[sink[vfirst]] == math.MinInt {
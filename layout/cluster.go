@@ -0,0 +1,427 @@
+package layout
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+type ClusterID = string
+
+// Cluster is group of nodes that is drawn inside common box.
+// Clusters form hierarchy, cluster with empty Parent is top level cluster.
+// Box coordinates are computed by layouts, similar to Node.
+type Cluster struct {
+	Position
+	W      int
+	H      int
+	Parent ClusterID
+	Nodes  []NodeID // direct members, members of child clusters are not listed here
+	Margin int      // distance between members and box border
+}
+
+// clusterTree is cluster hierarchy of graph together with membership of real and fake nodes.
+// Top level is represented by empty ClusterID.
+type clusterTree struct {
+	parent   map[ClusterID]ClusterID
+	children map[ClusterID][]ClusterID
+	cluster  map[uint64]ClusterID // innermost cluster of node, top level nodes are missing
+	margin   map[ClusterID]int
+}
+
+// ValidateClusters returns error when clusters do not form tree:
+// cluster has empty id or unknown parent, parents form cycle, or node is member of two clusters.
+// Layouts expect valid clusters.
+func (g Graph) ValidateClusters() error {
+	cluster := make(map[NodeID]ClusterID)
+	ids := make([]ClusterID, 0, len(g.Clusters))
+	for id := range g.Clusters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		c := g.Clusters[id]
+		if id == "" {
+			return fmt.Errorf("cluster with empty id is not allowed")
+		}
+		if _, ok := g.Clusters[c.Parent]; c.Parent != "" && !ok {
+			return fmt.Errorf("cluster(%s) has unknown parent(%s)", id, c.Parent)
+		}
+		for _, n := range c.Nodes {
+			if other, ok := cluster[n]; ok && other != id {
+				return fmt.Errorf("node(%d) is in two clusters(%s, %s)", n, other, id)
+			}
+			cluster[n] = id
+		}
+	}
+
+	for _, id := range ids {
+		p := g.Clusters[id].Parent
+		for depth := 0; p != ""; depth++ {
+			if p == id || depth > len(g.Clusters) {
+				return fmt.Errorf("cluster(%s) has cycle in parents", id)
+			}
+			p = g.Clusters[p].Parent
+		}
+	}
+	return nil
+}
+
+// newClusterTree expects valid clusters, it panics otherwise.
+func newClusterTree(g Graph) clusterTree {
+	if err := g.ValidateClusters(); err != nil {
+		panic(err)
+	}
+
+	t := clusterTree{
+		parent:   make(map[ClusterID]ClusterID, len(g.Clusters)),
+		children: make(map[ClusterID][]ClusterID, len(g.Clusters)),
+		cluster:  make(map[uint64]ClusterID),
+		margin:   make(map[ClusterID]int, len(g.Clusters)),
+	}
+
+	for id, c := range g.Clusters {
+		t.parent[id] = c.Parent
+		t.children[c.Parent] = append(t.children[c.Parent], id)
+		t.margin[id] = c.Margin
+		for _, n := range c.Nodes {
+			if _, ok := g.Nodes[n]; ok {
+				t.cluster[n] = id
+			}
+		}
+	}
+
+	for _, cs := range t.children {
+		sort.Strings(cs)
+	}
+
+	return t
+}
+
+// path is list of clusters from top level down to given cluster.
+func (t clusterTree) path(c ClusterID) []ClusterID {
+	var path []ClusterID
+	for ; c != "" && len(path) <= len(t.parent); c = t.parent[c] {
+		path = append(path, c)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// commonCluster is the innermost cluster containing both clusters.
+func (t clusterTree) commonCluster(a, b ClusterID) ClusterID {
+	pa := t.path(a)
+	pb := t.path(b)
+
+	var c ClusterID
+	for i := 0; i < len(pa) && i < len(pb) && pa[i] == pb[i]; i++ {
+		c = pa[i]
+	}
+	return c
+}
+
+// addFakeNodes puts fake nodes of long edges into innermost cluster containing both ends of edge.
+func (t clusterTree) addFakeNodes(lg LayeredGraph) {
+	for e, nodes := range lg.Edges {
+		c := t.commonCluster(t.cluster[e[0]], t.cluster[e[1]])
+		if c == "" {
+			continue
+		}
+		for _, n := range nodes {
+			if lg.Dummy[n] {
+				t.cluster[n] = c
+			}
+		}
+	}
+}
+
+// descendants are all nodes in cluster, including nodes of child clusters.
+func (t clusterTree) descendants() map[ClusterID][]uint64 {
+	nodes := make(map[ClusterID][]uint64, len(t.parent))
+	for n, c := range t.cluster {
+		for _, p := range t.path(c) {
+			nodes[p] = append(nodes[p], n)
+		}
+	}
+	return nodes
+}
+
+// clusterOrderKey is one level of node ordering key.
+// Each level is either cluster or node itself.
+type clusterOrderKey struct {
+	value   float64
+	cluster ClusterID
+	node    uint64
+}
+
+func (a clusterOrderKey) less(b clusterOrderKey) bool {
+	if a.value != b.value {
+		return a.value < b.value
+	}
+	if (a.cluster == "") != (b.cluster == "") {
+		return a.cluster != ""
+	}
+	if a.cluster != b.cluster {
+		return a.cluster < b.cluster
+	}
+	return a.node < b.node
+}
+
+// arrange reorders nodes in given layers such that members of each cluster are contiguous.
+// Sibling clusters get same relative order in all layers.
+// Cluster order is by average relative position of its members across all layers.
+// Nodes keep their current relative order otherwise.
+func (t clusterTree) arrange(layers [][]uint64, idxs ...int) {
+	pos := make(map[uint64]float64)
+	sum := make(map[ClusterID]float64)
+	cnt := make(map[ClusterID]int)
+	for _, layer := range layers {
+		for i, n := range layer {
+			v := (float64(i) + 0.5) / float64(len(layer))
+			pos[n] = v
+			for _, c := range t.path(t.cluster[n]) {
+				sum[c] += v
+				cnt[c]++
+			}
+		}
+	}
+
+	keys := make(map[uint64][]clusterOrderKey)
+	for _, idx := range idxs {
+		for _, n := range layers[idx] {
+			var key []clusterOrderKey
+			for _, c := range t.path(t.cluster[n]) {
+				key = append(key, clusterOrderKey{value: sum[c] / float64(cnt[c]), cluster: c})
+			}
+			keys[n] = append(key, clusterOrderKey{value: pos[n], node: n})
+		}
+
+		layer := layers[idx]
+		sort.SliceStable(layer, func(i, j int) bool {
+			a, b := keys[layer[i]], keys[layer[j]]
+			for k := 0; k < len(a) && k < len(b); k++ {
+				if a[k] != b[k] {
+					return a[k].less(b[k])
+				}
+			}
+			return len(a) < len(b)
+		})
+	}
+}
+
+// arrangeAll is arrange for all layers.
+func (t clusterTree) arrangeAll(layers [][]uint64) {
+	idxs := make([]int, len(layers))
+	for i := range layers {
+		idxs[i] = i
+	}
+	t.arrange(layers, idxs...)
+}
+
+// clusterItem is direct member of cluster, either node or child cluster.
+type clusterItem struct {
+	nodes       []uint64
+	isCluster   bool
+	left, right int
+	top, bottom int // layers
+}
+
+func (a clusterItem) overlaps(b clusterItem) bool {
+	// boxes of clusters in adjacent layers can touch, since box has margin
+	gap := 0
+	if a.isCluster || b.isCluster {
+		gap = 1
+	}
+	return a.top <= b.bottom+gap && b.top <= a.bottom+gap
+}
+
+// separate moves nodes horizontally to the right, so that boxes of clusters do not overlap with each other and with other nodes.
+// Expects layers to be arranged by clusters.
+// Clusters are processed from the innermost, members of each cluster are swept from left to right.
+func (t clusterTree) separate(g Graph, lg LayeredGraph, x map[uint64]int, delta int) {
+	descendants := t.descendants()
+	layers := lg.Layers()
+
+	var separateCluster func(c ClusterID) (left, right int)
+	separateCluster = func(c ClusterID) (left, right int) {
+		childBox := make(map[ClusterID][2]int, len(t.children[c]))
+		for _, child := range t.children[c] {
+			if len(descendants[child]) > 0 {
+				l, r := separateCluster(child)
+				childBox[child] = [2]int{l, r}
+			}
+		}
+
+		var nodes []uint64
+		if c == "" {
+			for n := range lg.NodePosition {
+				nodes = append(nodes, n)
+			}
+		} else {
+			nodes = descendants[c]
+		}
+
+		// each node belongs to direct member item, which is either node itself or child cluster
+		depth := len(t.path(c))
+		itemOf := make(map[uint64]int, len(nodes))
+		childItem := map[ClusterID]int{}
+		var items []clusterItem
+		for _, n := range nodes {
+			path := t.path(t.cluster[n])
+			if len(path) == depth {
+				w := g.Nodes[n].W / 2
+				itemOf[n] = len(items)
+				items = append(items, clusterItem{nodes: []uint64{n}, left: x[n] - w, right: x[n] + w, top: lg.NodePosition[n].Layer, bottom: lg.NodePosition[n].Layer})
+				continue
+			}
+			child := path[depth]
+			if _, ok := childItem[child]; !ok {
+				childItem[child] = len(items)
+				items = append(items, clusterItem{isCluster: true, left: childBox[child][0], right: childBox[child][1], top: math.MaxInt, bottom: math.MinInt})
+			}
+			i := childItem[child]
+			itemOf[n] = i
+			items[i].nodes = append(items[i].nodes, n)
+			items[i].top = min(items[i].top, lg.NodePosition[n].Layer)
+			items[i].bottom = max(items[i].bottom, lg.NodePosition[n].Layer)
+		}
+
+		// order items from left to right, consistently with order in every layer
+		next := make([]map[int]bool, len(items))
+		indegree := make([]int, len(items))
+		for _, layer := range layers {
+			prev := -1
+			for _, n := range layer {
+				i, ok := itemOf[n]
+				if !ok {
+					continue
+				}
+				if prev >= 0 && prev != i {
+					if next[prev] == nil {
+						next[prev] = map[int]bool{}
+					}
+					if !next[prev][i] {
+						next[prev][i] = true
+						indegree[i]++
+					}
+				}
+				prev = i
+			}
+		}
+
+		var que []int
+		for i := range items {
+			if indegree[i] == 0 {
+				que = append(que, i)
+			}
+		}
+		var order []int
+		placed := make([]bool, len(items))
+		for len(order) < len(items) {
+			if len(que) == 0 {
+				// members of cluster are not contiguous in some layer, like when order hints split it,
+				// so leftmost item that is left is placed next and boxes can overlap
+				log.Printf("clusters: members of cluster(%q) are not contiguous in layers, boxes can overlap", c)
+				for i := range items {
+					if !placed[i] && indegree[i] > 0 && (len(que) == 0 || items[i].left < items[que[0]].left) {
+						que = []int{i}
+					}
+				}
+				indegree[que[0]] = 0
+			}
+			sort.Slice(que, func(a, b int) bool { return items[que[a]].left < items[que[b]].left })
+			p := que[0]
+			que = que[1:]
+			order = append(order, p)
+			placed[p] = true
+			for i := range next[p] {
+				if placed[i] {
+					continue
+				}
+				indegree[i]--
+				if indegree[i] == 0 {
+					que = append(que, i)
+				}
+			}
+		}
+
+		// sweep from left to right, pushing items that overlap already placed ones
+		left, right = math.MaxInt, math.MinInt
+		for k, i := range order {
+			required := math.MinInt
+			for _, j := range order[:k] {
+				if items[i].overlaps(items[j]) && items[j].right+delta > required {
+					required = items[j].right + delta
+				}
+			}
+			if d := required - items[i].left; required != math.MinInt && d > 0 {
+				for _, n := range items[i].nodes {
+					x[n] += d
+				}
+				items[i].left += d
+				items[i].right += d
+			}
+			left = min(left, items[i].left)
+			right = max(right, items[i].right)
+		}
+
+		return left - t.margin[c], right + t.margin[c]
+	}
+
+	separateCluster("")
+}
+
+// ClustersBoundingBoxLayout sets box of each cluster to fit its nodes and child clusters, with margin.
+// Clusters without nodes get empty box.
+type ClustersBoundingBoxLayout struct{}
+
+func (l ClustersBoundingBoxLayout) UpdateGraphLayout(g Graph) {
+	if len(g.Clusters) == 0 {
+		return
+	}
+	t := newClusterTree(g)
+
+	var update func(c ClusterID) (minx, miny, maxx, maxy int, ok bool)
+	update = func(c ClusterID) (minx, miny, maxx, maxy int, ok bool) {
+		minx, miny, maxx, maxy = math.MaxInt, math.MaxInt, math.MinInt, math.MinInt
+		extend := func(x0, y0, x1, y1 int) {
+			minx, miny = min(minx, x0), min(miny, y0)
+			maxx, maxy = max(maxx, x1), max(maxy, y1)
+			ok = true
+		}
+
+		for _, n := range g.Clusters[c].Nodes {
+			if node, found := g.Nodes[n]; found {
+				extend(node.X, node.Y, node.X+node.W, node.Y+node.H)
+			}
+		}
+		for _, child := range t.children[c] {
+			if x0, y0, x1, y1, found := update(child); found {
+				extend(x0, y0, x1, y1)
+			}
+		}
+
+		cluster := g.Clusters[c]
+		if !ok {
+			cluster.Position, cluster.W, cluster.H = Position{}, 0, 0
+			g.Clusters[c] = cluster
+			return minx, miny, maxx, maxy, false
+		}
+
+		m := cluster.Margin
+		minx, miny, maxx, maxy = minx-m, miny-m, maxx+m, maxy+m
+		cluster.Position = Position{X: minx, Y: miny}
+		cluster.W = maxx - minx
+		cluster.H = maxy - miny
+		g.Clusters[c] = cluster
+		return minx, miny, maxx, maxy, true
+	}
+
+	for _, c := range t.children[""] {
+		update(c)
+	}
+}
@@ -0,0 +1,140 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+func newLayersLayout() layout.SugiyamaLayersStrategyGraphLayout {
+	return layout.SugiyamaLayersStrategyGraphLayout{
+		CycleRemover:   layout.NewSimpleCycleRemover(),
+		LevelsAssigner: layout.NewLayeredGraph,
+		OrderingAssigner: layout.WarfieldOrderingOptimizer{
+			Epochs:                   20,
+			LayerOrderingInitializer: layout.BFSOrderingInitializer{},
+			LayerOrderingOptimizer: layout.CompositeLayerOrderingOptimizer{
+				Optimizers: []layout.LayerOrderingOptimizer{
+					layout.WMedianOrderingOptimizer{},
+					layout.SwitchAdjacentOrderingOptimizer{},
+				},
+			},
+		}.Optimize,
+		NodesHorizontalCoordinatesAssigner: layout.BrandesKopfLayersNodesHorizontalAssigner{
+			Delta: 25,
+		},
+		NodesVerticalCoordinatesAssigner: layout.BasicNodesVerticalCoordinatesAssigner{
+			MarginLayers:   25,
+			FakeNodeHeight: 25,
+		},
+		EdgePathAssigner: layout.StraightEdgePathAssigner{}.UpdateGraphLayout,
+	}
+}
+
+func newClusteredGraph() layout.Graph {
+	g := layout.Graph{
+		Nodes:    map[uint64]layout.Node{},
+		Edges:    map[[2]uint64]layout.Edge{},
+		Clusters: map[string]layout.Cluster{},
+	}
+	for i := uint64(1); i <= 9; i++ {
+		g.Nodes[i] = layout.Node{W: 40, H: 20}
+	}
+	for _, e := range [][2]uint64{{1, 2}, {1, 3}, {2, 4}, {3, 5}, {4, 7}, {5, 8}, {1, 6}, {6, 7}, {2, 5}, {1, 8}, {9, 4}, {3, 9}} {
+		g.Edges[e] = layout.Edge{}
+	}
+	g.Clusters["a"] = layout.Cluster{Nodes: []uint64{2, 3}, Margin: 5}
+	g.Clusters["b"] = layout.Cluster{Nodes: []uint64{4, 5}, Margin: 5}
+	g.Clusters["c"] = layout.Cluster{Parent: "a", Nodes: []uint64{6}, Margin: 5}
+	return g
+}
+
+func boxesOverlap(a, b layout.Node) bool {
+	return a.X < b.X+b.W && b.X < a.X+a.W && a.Y < b.Y+b.H && b.Y < a.Y+a.H
+}
+
+func TestClustersLayers(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		g := newClusteredGraph()
+		newLayersLayout().UpdateGraphLayout(g)
+
+		box := func(id string) layout.Node {
+			c := g.Clusters[id]
+			return layout.Node{Position: c.Position, W: c.W, H: c.H}
+		}
+
+		inCluster := map[uint64]string{}
+		for id, c := range g.Clusters {
+			if c.W == 0 || c.H == 0 {
+				t.Fatalf("cluster(%s) has empty box", id)
+			}
+			for _, n := range c.Nodes {
+				inCluster[n] = id
+				node := g.Nodes[n]
+				b := box(id)
+				if node.X < b.X || node.Y < b.Y || node.X+node.W > b.X+b.W || node.Y+node.H > b.Y+b.H {
+					t.Errorf("node(%d) %+v is not inside cluster(%s) %+v", n, node, id, b)
+				}
+			}
+		}
+
+		if a, c := box("a"), box("c"); c.X < a.X || c.X+c.W > a.X+a.W {
+			t.Errorf("cluster(c) %+v is not inside parent cluster(a) %+v", c, a)
+		}
+		if boxesOverlap(box("a"), box("b")) {
+			t.Errorf("clusters overlap: %+v %+v", box("a"), box("b"))
+		}
+		for n, node := range g.Nodes {
+			for _, id := range []string{"a", "b", "c"} {
+				// nodes of cluster c are also in its parent cluster a
+				if inCluster[n] == id || (id == "a" && inCluster[n] == "c") {
+					continue
+				}
+				if boxesOverlap(node, box(id)) {
+					t.Errorf("node(%d) %+v overlaps cluster(%s) %+v", n, node, id, box(id))
+				}
+			}
+		}
+	}
+}
+
+func TestValidateClusters(t *testing.T) {
+	tests := map[string]map[string]layout.Cluster{
+		"empty id":       {"": {}},
+		"unknown parent": {"a": {Parent: "b"}},
+		"two clusters":   {"a": {Nodes: []uint64{1}}, "b": {Nodes: []uint64{1}}},
+		"parent cycle":   {"a": {Parent: "b"}, "b": {Parent: "c"}, "c": {Parent: "a"}},
+		"own parent":     {"a": {Parent: "a"}},
+	}
+	for name, clusters := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := layout.Graph{Nodes: map[uint64]layout.Node{1: {W: 10, H: 10}}, Edges: map[[2]uint64]layout.Edge{}, Clusters: clusters}
+			if err := g.ValidateClusters(); err == nil {
+				t.Errorf("expected error")
+			}
+			if _, err := newLayersLayout().LayoutLayered(g); err == nil {
+				t.Errorf("expected error of layout")
+			}
+		})
+	}
+
+	if err := newClusteredGraph().ValidateClusters(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestClustersSplitByHints(t *testing.T) {
+	g := layout.Graph{
+		Nodes:      map[uint64]layout.Node{1: {W: 10, H: 10}, 2: {W: 10, H: 10}, 3: {W: 10, H: 10}, 4: {W: 10, H: 10}},
+		Edges:      map[[2]uint64]layout.Edge{{1, 2}: {}, {1, 3}: {}, {1, 4}: {}},
+		Clusters:   map[string]layout.Cluster{"a": {Nodes: []uint64{2, 4}}},
+		LayerHints: map[uint64]layout.LayerHint{3: {Layer: 1, Order: 1, FixedOrder: true}},
+	}
+	// cluster is not contiguous, its box can overlap node but layout finishes
+	if _, err := newLayersLayout().LayoutLayered(g); err != nil {
+		t.Fatal(err)
+	}
+	if c := g.Clusters["a"]; c.W == 0 || c.H == 0 {
+		t.Errorf("cluster has empty box: %+v", c)
+	}
+}
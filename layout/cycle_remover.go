@@ -48,9 +48,17 @@ func getCycle(roots []uint64, neighbors map[uint64][]uint64) []uint64 {
 	return nil
 }
 
+// reverseEdge keeps edge data, path is reversed too
 func reverseEdge(g Graph, e [2]uint64) {
+	edge := g.Edges[e]
 	delete(g.Edges, e)
-	g.Edges[[2]uint64{e[1], e[0]}] = Edge{}
+
+	path := make([]Position, len(edge.Path))
+	for i, p := range edge.Path {
+		path[len(path)-1-i] = p
+	}
+	edge.Path = path
+	g.Edges[[2]uint64{e[1], e[0]}] = edge
 }
 
 func (s SimpleCycleRemover) RemoveCycles(g Graph) {
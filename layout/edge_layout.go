@@ -20,7 +20,8 @@ func DirectEdge(from, to Node) Edge {
 type DirectEdgesLayout struct{}
 
 func (l DirectEdgesLayout) UpdateGraphLayout(g Graph) {
-	for e := range g.Edges {
-		g.Edges[e] = DirectEdge(g.Nodes[e[0]], g.Nodes[e[1]])
+	for e, edge := range g.Edges {
+		edge.Path = DirectEdge(g.Nodes[e[0]], g.Nodes[e[1]]).Path
+		g.Edges[e] = edge
 	}
 }
@@ -8,6 +8,7 @@ type Force interface {
 }
 
 // ForceGraphLayout will simulate node movement due to forces.
+// Pinned nodes apply forces to other nodes, but are not moved, and other nodes are moved off them at the end.
 type ForceGraphLayout struct {
 	Delta    float64 // how much move each step
 	MaxSteps int     // limit of iterations
@@ -24,9 +25,9 @@ func (l ForceGraphLayout) UpdateGraphLayout(g Graph) {
 			l.Forces[i].UpdateForce(g, f)
 		}
 
-		// delete tiny forces
-		for i := range g.Nodes {
-			if math.Hypot(f[i][0], f[i][1]) < l.Epsilon {
+		// delete tiny forces, pinned nodes do not move
+		for i, node := range g.Nodes {
+			if node.Pinned || math.Hypot(f[i][0], f[i][1]) < l.Epsilon {
 				delete(f, i)
 			}
 		}
@@ -37,16 +38,21 @@ func (l ForceGraphLayout) UpdateGraphLayout(g Graph) {
 		}
 
 		// move by delta
-		for i := range g.Nodes {
-			x := g.Nodes[i].X + int((f[i][0] * l.Delta))
-			y := g.Nodes[i].Y + int((f[i][1] * l.Delta))
-			g.Nodes[i] = Node{
-				Position: Position{X: x, Y: y},
-				W:        g.Nodes[i].W,
-				H:        g.Nodes[i].H,
+		for i, node := range g.Nodes {
+			if node.Pinned {
+				continue
 			}
+			node.X += int((f[i][0] * l.Delta))
+			node.Y += int((f[i][1] * l.Delta))
+			g.Nodes[i] = node
 		}
 	}
+
+	positions := make(map[uint64]Position, len(g.Nodes))
+	for i, node := range g.Nodes {
+		positions[i] = node.Position
+	}
+	updatePositions(g, positions)
 }
 
 // SpringForce is linear by distance.
@@ -138,3 +144,23 @@ func (l GravityForce) UpdateForce(g Graph, f map[uint64][2]float64) {
 		}
 	}
 }
+
+// AnchorForce pulls nodes towards their anchor positions, linear by distance.
+// Used to keep nodes close to previous drawing in incremental layouts.
+type AnchorForce struct {
+	K       float64 // has to be positive
+	Anchors map[uint64]Position
+}
+
+func (l AnchorForce) UpdateForce(g Graph, f map[uint64][2]float64) {
+	for i, node := range g.Nodes {
+		a, ok := l.Anchors[i]
+		if !ok {
+			continue
+		}
+		f[i] = [2]float64{
+			f[i][0] + l.K*float64(a.X-node.X),
+			f[i][1] + l.K*float64(a.Y-node.Y),
+		}
+	}
+}
@@ -3,7 +3,11 @@ package layout
 import (
 	"log"
 	"math"
+	"sort"
 
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
 	gnlayout "gonum.org/v1/gonum/graph/layout"
 	gnsimple "gonum.org/v1/gonum/graph/simple"
 	gnr2 "gonum.org/v1/gonum/spatial/r2"
@@ -13,12 +17,31 @@ func gonumNodeID(id uint64) int64 {
 	return int64(float64(id))
 }
 
-func toGonumGraph(g Graph) *gnsimple.UndirectedGraph {
+func toGonumGraph(g Graph) orderedGraph {
 	gn := gnsimple.NewUndirectedGraph()
 	for e := range g.Edges {
 		gn.SetEdge(gn.NewEdge(gnsimple.Node(gonumNodeID(e[0])), gnsimple.Node(gonumNodeID(e[1]))))
 	}
-	return gn
+	return orderedGraph{gn}
+}
+
+// orderedGraph iterates nodes by ID, so that gonum layouts do not depend on map order.
+type orderedGraph struct {
+	*gnsimple.UndirectedGraph
+}
+
+func (g orderedGraph) Nodes() graph.Nodes {
+	return sortedNodes(g.UndirectedGraph.Nodes())
+}
+
+func (g orderedGraph) From(id int64) graph.Nodes {
+	return sortedNodes(g.UndirectedGraph.From(id))
+}
+
+func sortedNodes(it graph.Nodes) graph.Nodes {
+	nodes := graph.NodesOf(it)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID() < nodes[j].ID() })
+	return iterator.NewOrderedNodes(nodes)
 }
 
 type gnLayoutGetter interface {
@@ -53,22 +76,22 @@ func updateGraphByGonumLayout(g Graph, gnLayout gnLayoutGetter, scaleX float64,
 	log.Printf("update gonum layout: gonum layout(%f x %f) our layout (%f x %f)", gnw, gnh, w, h)
 
 	// update our coodinates and scale
+	// gonum does not support fixed nodes, so layout is moved to fit pinned nodes and nodes are moved off them instead
+	computed := make(map[uint64]Position, len(g.Nodes))
 	for nodeID := range g.Nodes {
 		gnNode := gnLayout.Coord2(gonumNodeID(nodeID))
-
-		g.Nodes[nodeID] = Node{
-			Position: Position{
-				X: int(gnNode.X * w / gnw),
-				Y: int(gnNode.Y * h / gnh),
-			},
-			W: g.Nodes[nodeID].W,
-			H: g.Nodes[nodeID].H,
+		computed[nodeID] = Position{
+			X: int(gnNode.X * w / gnw),
+			Y: int(gnNode.Y * h / gnh),
 		}
 	}
+	updatePositions(g, computed)
 }
 
 // This works, but not as pretty.
+// Seed makes initial random positions of nodes same between runs, global random source is used when it is 0.
 type EadesGonumLayout struct {
+	Seed      uint64
 	Updates   int
 	Repulsion float64
 	Rate      float64
@@ -86,6 +109,9 @@ func (l EadesGonumLayout) UpdateGraphLayout(g Graph) {
 		Rate:      l.Rate,
 		Theta:     l.Theta,
 	}
+	if l.Seed != 0 {
+		eades.Src = rand.NewSource(l.Seed)
+	}
 	optimizer := gnlayout.NewOptimizerR2(gn, eades.Update)
 	for optimizer.Update() {
 	}
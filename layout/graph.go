@@ -9,15 +9,19 @@ type Position struct {
 
 // Graph tells how to position nodes and paths for edges
 type Graph struct {
-	Edges map[[2]NodeID]Edge
-	Nodes map[NodeID]Node
+	Edges      map[[2]NodeID]Edge
+	Nodes      map[NodeID]Node
+	Clusters   map[ClusterID]Cluster // optional, groups of nodes drawn in common box
+	Ranks      []RankConstraint      // optional, constraints on layers of nodes in layered layouts
+	LayerHints map[NodeID]LayerHint  // optional, fixed layer or order of nodes in layered layouts
 }
 
 // Node is how to position node and its dimensions
 type Node struct {
 	Position
-	W int
-	H int
+	W      int
+	H      int
+	Pinned bool // layouts do not move pinned node, rest of graph is placed around it
 }
 
 func (n Node) CenterXY() Position {
@@ -28,7 +32,16 @@ func (n Node) CenterXY() Position {
 
 // Edge is path of points that edge goes through
 type Edge struct {
-	Path []Position // [start: {x,y}, ... finish: {x,y}]
+	Path   []Position // [start: {x,y}, ... finish: {x,y}]
+	MinLen int        // minimum number of layers edge spans in layered layouts, values below 1 mean 1
+	Weight *int       // importance of edge, heavier edges are kept shorter and straighter, nil or negative means 1, 0 ignores edge
+}
+
+func edgeWeight(e Edge) int {
+	if e.Weight == nil || *e.Weight < 0 {
+		return 1
+	}
+	return *e.Weight
 }
 
 func (g Graph) Copy() Graph {
@@ -40,8 +53,24 @@ func (g Graph) Copy() Graph {
 		ng.Nodes[id] = n
 	}
 	for id, e := range g.Edges {
-		ng.Edges[id] = Edge{Path: make([]Position, len(e.Path))}
-		copy(ng.Edges[id].Path, e.Path)
+		e.Path = append([]Position(nil), e.Path...)
+		ng.Edges[id] = e
+	}
+	if g.Clusters != nil {
+		ng.Clusters = make(map[ClusterID]Cluster, len(g.Clusters))
+		for id, c := range g.Clusters {
+			c.Nodes = append([]NodeID(nil), c.Nodes...)
+			ng.Clusters[id] = c
+		}
+	}
+	if g.LayerHints != nil {
+		ng.LayerHints = make(map[NodeID]LayerHint, len(g.LayerHints))
+		for id, h := range g.LayerHints {
+			ng.LayerHints[id] = h
+		}
+	}
+	for _, r := range g.Ranks {
+		ng.Ranks = append(ng.Ranks, RankConstraint{Rank: r.Rank, Nodes: append([]NodeID(nil), r.Nodes...)})
 	}
 	return ng
 }
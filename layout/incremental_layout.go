@@ -0,0 +1,133 @@
+package layout
+
+import "math"
+
+// IncrementalLayout lays out graph that changed since Previous was laid out, keeping drawing as close to Previous as possible.
+// Nodes that are in Previous start from their previous positions, new nodes start at center of their known neighbors.
+// Layouts that start from current positions (ForceGraphLayout) keep the mental map, AnchorForce helps them further.
+// For layered layouts, use PreviousOrderingInitializer with layered graph of previous layout, and KeepInitial of WarfieldOrderingOptimizer.
+// After Layout, drawing is moved so that nodes that are in both graphs move the least.
+type IncrementalLayout struct {
+	Previous Graph
+	Layout   Layout
+}
+
+func (l IncrementalLayout) UpdateGraphLayout(g Graph) {
+	l.Update(g)
+}
+
+// Update lays out graph and tells how far nodes moved from previous drawing.
+func (l IncrementalLayout) Update(g Graph) Movement {
+	l.initPositions(g)
+
+	l.Layout.UpdateGraphLayout(g)
+
+	// pinned nodes define position of drawing already
+	hasPinned := false
+	for _, node := range g.Nodes {
+		hasPinned = hasPinned || node.Pinned
+	}
+	if !hasPinned {
+		var dx, dy, n int
+		for id, node := range g.Nodes {
+			if prev, ok := l.Previous.Nodes[id]; ok {
+				dx += prev.X - node.X
+				dy += prev.Y - node.Y
+				n++
+			}
+		}
+		if n > 0 {
+			translate(g, Position{X: dx / n, Y: dy / n})
+		}
+	}
+
+	return NodesMovement(l.Previous, g)
+}
+
+// initPositions sets previous positions to known nodes, and positions of new nodes to center of their known neighbors.
+func (l IncrementalLayout) initPositions(g Graph) {
+	for id, node := range g.Nodes {
+		if prev, ok := l.Previous.Nodes[id]; ok && !node.Pinned {
+			node.Position = prev.Position
+			g.Nodes[id] = node
+		}
+	}
+
+	neighbors := make(map[uint64][]uint64)
+	for e := range g.Edges {
+		neighbors[e[0]] = append(neighbors[e[0]], e[1])
+		neighbors[e[1]] = append(neighbors[e[1]], e[0])
+	}
+	for id, node := range g.Nodes {
+		if _, ok := l.Previous.Nodes[id]; ok || node.Pinned {
+			continue
+		}
+		var x, y, n int
+		for _, nb := range neighbors[id] {
+			if prev, ok := l.Previous.Nodes[nb]; ok {
+				c := prev.CenterXY()
+				x += c.X
+				y += c.Y
+				n++
+			}
+		}
+		if n > 0 {
+			node.Position = Position{X: x/n - node.W/2, Y: y/n - node.H/2}
+			g.Nodes[id] = node
+		}
+	}
+}
+
+// translate moves all nodes that are not pinned, edges and clusters.
+func translate(g Graph, d Position) {
+	if d == (Position{}) {
+		return
+	}
+	for id, node := range g.Nodes {
+		if !node.Pinned {
+			node.X += d.X
+			node.Y += d.Y
+			g.Nodes[id] = node
+		}
+	}
+	for _, e := range g.Edges {
+		for i, p := range e.Path {
+			e.Path[i] = Position{X: p.X + d.X, Y: p.Y + d.Y}
+		}
+	}
+	for id, c := range g.Clusters {
+		c.X += d.X
+		c.Y += d.Y
+		g.Clusters[id] = c
+	}
+}
+
+// Movement is how far nodes moved between two drawings.
+// Only nodes that are in both drawings are considered.
+type Movement struct {
+	Nodes map[NodeID]float64 // distance each node moved
+	Total float64
+	Mean  float64
+	Max   float64
+}
+
+// NodesMovement computes distances that nodes moved from previous to current drawing.
+func NodesMovement(previous, current Graph) Movement {
+	m := Movement{Nodes: make(map[NodeID]float64)}
+	for id, node := range current.Nodes {
+		prev, ok := previous.Nodes[id]
+		if !ok {
+			continue
+		}
+		d := math.Hypot(float64(node.X-prev.X), float64(node.Y-prev.Y))
+		m.Nodes[id] = d
+		m.Total += d
+		if d > m.Max {
+			m.Max = d
+		}
+	}
+	if len(m.Nodes) > 0 {
+		m.Mean = m.Total / float64(len(m.Nodes))
+	}
+	return m
+}
@@ -0,0 +1,165 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+func TestIncrementalLayers(t *testing.T) {
+	_, g0, err := parseJSONLGraph(smallJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg0 := newLayersLayout().UpdateGraphLayoutLayered(*g0)
+
+	g1 := g0.Copy()
+	var leaf uint64
+	for n := range g1.Nodes {
+		leaf = max(leaf, n)
+	}
+	g1.Nodes[leaf+1] = layout.Node{W: 30, H: 20}
+	g1.Edges[[2]uint64{leaf, leaf + 1}] = layout.Edge{}
+
+	layers := newLayersLayout()
+	var lg1 layout.LayeredGraph
+	layers.OrderingAssigner = func(g layout.Graph, lg layout.LayeredGraph) {
+		layout.WarfieldOrderingOptimizer{
+			LayerOrderingInitializer: layout.PreviousOrderingInitializer{Previous: lg0},
+			LayerOrderingOptimizer:   layout.WMedianOrderingOptimizer{},
+			KeepInitial:              true,
+		}.Optimize(g, lg)
+		lg1 = lg
+	}
+
+	m := layout.IncrementalLayout{Previous: *g0, Layout: layers}.Update(g1)
+	if len(m.Nodes) != len(g0.Nodes) {
+		t.Errorf("movement has %d nodes, expected %d", len(m.Nodes), len(g0.Nodes))
+	}
+
+	// nodes that stay on same layer keep their relative order
+	for a, pa := range lg0.NodePosition {
+		for b, pb := range lg0.NodePosition {
+			if lg0.Dummy[a] || lg0.Dummy[b] || pa.Layer != pb.Layer || !pa.IsLeftOf(pb) {
+				continue
+			}
+			qa, qb := lg1.NodePosition[a], lg1.NodePosition[b]
+			if qa.Layer == qb.Layer && !qa.IsLeftOf(qb) {
+				t.Errorf("nodes(%d, %d) changed order", a, b)
+			}
+		}
+	}
+}
+
+func TestIncrementalForces(t *testing.T) {
+	_, g0, err := parseJSONLGraph(smallJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// forces do not separate nodes at same position
+	for n, node := range g0.Nodes {
+		node.Position = layout.Position{X: int(n%5) * 100, Y: int(n/5) * 100}
+		g0.Nodes[n] = node
+	}
+	forces := []layout.Force{
+		layout.GravityForce{K: -50},
+		layout.SpringForce{K: 0.2, L: 200, EdgesOnly: true},
+	}
+	layout.ForceGraphLayout{Delta: 1, MaxSteps: 1000, Epsilon: 1.5, Forces: forces}.UpdateGraphLayout(*g0)
+
+	anchors := map[uint64]layout.Position{}
+	for n, node := range g0.Nodes {
+		anchors[n] = node.Position
+	}
+
+	g1 := g0.Copy()
+	var leaf uint64
+	for n := range g1.Nodes {
+		leaf = max(leaf, n)
+	}
+	g1.Nodes[leaf+1] = layout.Node{W: 30, H: 20}
+	g1.Edges[[2]uint64{leaf, leaf + 1}] = layout.Edge{}
+
+	m := layout.IncrementalLayout{
+		Previous: *g0,
+		Layout: layout.ForceGraphLayout{
+			Delta:    1,
+			MaxSteps: 100,
+			Epsilon:  1.5,
+			Forces:   append(forces, layout.AnchorForce{K: 1, Anchors: anchors}),
+		},
+	}.Update(g1)
+
+	if len(m.Nodes) != len(g0.Nodes) {
+		t.Errorf("movement has %d nodes, expected %d", len(m.Nodes), len(g0.Nodes))
+	}
+	for n, d := range m.Nodes {
+		if d > 50 {
+			t.Errorf("node(%d) moved too far from %+v to %+v: %f", n, g0.Nodes[n].Position, g1.Nodes[n].Position, d)
+		}
+	}
+	if p := g1.Nodes[leaf+1].Position; p == (layout.Position{}) {
+		t.Errorf("new node is not laid out: %+v", p)
+	}
+}
+
+// fixedInitializer sets given order of nodes in layers.
+type fixedInitializer [][]uint64
+
+func (o fixedInitializer) Init(segments map[[2]uint64]bool, layers [][]uint64) {
+	for i := range layers {
+		copy(layers[i], o[i])
+	}
+}
+
+// swapOptimizer reverses order of layer, it never removes crossings of two nodes.
+type swapOptimizer struct{ calls *int }
+
+func (o swapOptimizer) Optimize(segments map[[2]uint64]bool, layers [][]uint64, idx int, downUp bool) {
+	*o.calls++
+	l := layers[idx]
+	for i, j := 0, len(l)-1; i < j; i, j = i+1, j-1 {
+		l[i], l[j] = l[j], l[i]
+	}
+}
+
+func TestWarfieldKeepsInitialOrdering(t *testing.T) {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{1: {W: 10, H: 10}, 2: {W: 10, H: 10}, 3: {W: 10, H: 10}, 4: {W: 10, H: 10}},
+		Edges: map[[2]uint64]layout.Edge{{1, 3}: {}, {2, 4}: {}},
+	}
+	tests := []struct {
+		name     string
+		initial  [][]uint64
+		keep     bool
+		calls    int
+		expected [][]uint64
+	}{
+		{"no crossings", [][]uint64{{1, 2}, {3, 4}}, true, 0, [][]uint64{{1, 2}, {3, 4}}},
+		{"not better", [][]uint64{{1, 2}, {4, 3}}, true, 4, [][]uint64{{1, 2}, {4, 3}}},
+		{"not kept by default", [][]uint64{{1, 2}, {3, 4}}, false, 2, [][]uint64{{2, 1}, {4, 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lg := layout.NewLayeredGraph(g)
+			calls := 0
+			layout.WarfieldOrderingOptimizer{
+				Epochs:                   2,
+				LayerOrderingInitializer: fixedInitializer(tt.initial),
+				LayerOrderingOptimizer:   swapOptimizer{calls: &calls},
+				KeepInitial:              tt.keep,
+			}.Optimize(g, lg)
+
+			if calls != tt.calls {
+				t.Errorf("optimizer called %d times, expected %d", calls, tt.calls)
+			}
+			for l, layer := range tt.expected {
+				for o, n := range layer {
+					if p := lg.NodePosition[n]; p.Layer != l || p.Order != o {
+						t.Errorf("node(%d) is at %d:%d, expected %d:%d", n, p.Layer, p.Order, l, o)
+					}
+				}
+			}
+		})
+	}
+}
@@ -29,6 +29,10 @@ type LayeredGraph struct {
 	Dummy        map[uint64]bool          // fake nodes
 	NodePosition map[uint64]LayerPosition // node -> {layer, ordering in layer}
 	Edges        map[[2]uint64][]uint64   // real long/short edge -> {real, fake, fake, fake, real} nodes
+	Ranks        []RankConstraint         // rank constraints on real nodes
+	MinLen       map[[2]uint64]int        // real edge -> minimum number of layers it spans, when more than 1
+	Weights      map[[2]uint64]int        // segment -> weight of real edge it belongs to, when not 1
+	Hints        map[uint64]LayerHint     // real node -> fixed layer or order
 }
 
 func (g LayeredGraph) Layers() [][]uint64 {
@@ -46,13 +50,18 @@ func (g LayeredGraph) Layers() [][]uint64 {
 
 	for layerIdx := 0; layerIdx < len(layers); layerIdx++ {
 		sort.Slice(layers[layerIdx], func(i, j int) bool {
-			return g.NodePosition[layers[layerIdx][i]].IsLeftOf(g.NodePosition[layers[layerIdx][j]])
+			a, b := layers[layerIdx][i], layers[layerIdx][j]
+			if g.NodePosition[a] == g.NodePosition[b] {
+				return a < b
+			}
+			return g.NodePosition[a].IsLeftOf(g.NodePosition[b])
 		})
 	}
 
 	return layers
 }
 
+// Validate checks that segments go down and that rank constraints and minimum lengths of edges are satisfied.
 func (g LayeredGraph) Validate() error {
 	for e := range g.Segments {
 		from := g.NodePosition[e[0]].Layer
@@ -61,7 +70,7 @@ func (g LayeredGraph) Validate() error {
 			return fmt.Errorf("edge(%v) is wrong direction, got from level(%d) to level(%d)", e, from, to)
 		}
 	}
-	return nil
+	return g.validateConstraints()
 }
 
 func (g LayeredGraph) String() string {
@@ -87,6 +96,29 @@ func (g LayeredGraph) String() string {
 	return out
 }
 
+// sortedEdges are keys of edges ordered by source and then by target, so that results do not depend on map iteration.
+func sortedEdges[V any](edges map[[2]uint64]V) [][2]uint64 {
+	keys := make([][2]uint64, 0, len(edges))
+	for e := range edges {
+		keys = append(keys, e)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
+
+// segmentWeight is weight of segment, defaults to 1.
+func segmentWeight(weights map[[2]uint64]int, segment [2]uint64) int {
+	if w, ok := weights[segment]; ok {
+		return w
+	}
+	return 1
+}
+
 // IsInnerSegment tells when edge is between two Dummy nodes.
 func (g LayeredGraph) IsInnerSegment(segment [2]uint64) bool {
 	return g.Dummy[segment[0]] && g.Dummy[segment[1]]
@@ -19,7 +19,9 @@ func (l StraightEdgePathAssigner) UpdateGraphLayout(g Graph, lg LayeredGraph, al
 			path[i] = allNodesXY[n]
 		}
 
-		g.Edges[e] = Edge{Path: path}
+		edge := g.Edges[e]
+		edge.Path = path
+		g.Edges[e] = edge
 		numAssignedEdges++
 	}
 
@@ -1,22 +1,164 @@
 package layout
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 // Expects that graph g does not have cycles.
+// Layers are assigned by longest path from roots, honoring rank constraints, layer hints and minimum lengths of edges.
+// Rank constraints and layer hints that contradict edges or each other are dropped, and logged.
 // This step creates fake nodes and splits long edges into segments.
 func NewLayeredGraph(g Graph) LayeredGraph {
-	positions := assignLevels(g)
+	constraints := newLayerConstraints(g)
+	return newLayeredGraph(g, constraints.nodeLayers(constraints.assign()))
+}
+
+// WeightedLevelsAssigner starts with longest path layering and moves nodes between layers
+// to reduce total length of edges multiplied by their weights, so heavy edges are short.
+// Each move puts node to best layer between its parents and children, like in network simplex of Graphviz dot.
+// Nodes with min, max, source or sink rank constraints, or with fixed layer, are not moved.
+type WeightedLevelsAssigner struct {
+	Iterations int // limit of passes over all nodes
+}
+
+// NewLayeredGraph expects that graph g does not have cycles.
+func (a WeightedLevelsAssigner) NewLayeredGraph(g Graph) LayeredGraph {
+	constraints := newLayerConstraints(g)
+	layers := constraints.assign()
+	constraints.shorten(layers, a.Iterations)
+	constraints.compact(layers)
+	return newLayeredGraph(g, constraints.nodeLayers(layers))
+}
+
+// newLayeredGraph creates fake nodes and splits long edges into segments, given layers of real nodes.
+// Constraints that layers do not satisfy are dropped.
+func newLayeredGraph(g Graph, positions map[uint64]LayerPosition) LayeredGraph {
 	edges := makeEdges(g, positions)
-	return LayeredGraph{
+	lg := LayeredGraph{
 		NodePosition: positions,
 		Segments:     makeSegments(edges),
 		Dummy:        makeDummy(edges),
 		Edges:        edges,
+		Ranks:        g.Ranks,
+		MinLen:       makeMinLen(g),
+		Weights:      makeWeights(g, edges),
+		Hints:        g.LayerHints,
+	}
+	lg.dropViolated()
+	return lg
+}
+
+// shorten moves groups within bounds set by their edges to layer that minimizes weighted length of their edges.
+// Weighted length of edges of group changes linearly with its layer, so best layer is one of bounds.
+func (c layerConstraints) shorten(layer map[uint64]int, iterations int) {
+	in := make(map[uint64][]uint64)
+	out := make(map[uint64][]uint64)
+	for e := range c.minLen {
+		out[e[0]] = append(out[e[0]], e[1])
+		in[e[1]] = append(in[e[1]], e[0])
+	}
+
+	top, bottom := 0, 0
+	hasSink := false
+	for gr, l := range layer {
+		if c.rank[gr][RankSource] {
+			top = 1
+		}
+		if c.rank[gr][RankSink] {
+			hasSink = true
+		}
+		bottom = max(bottom, l)
+	}
+	if hasSink {
+		bottom--
+	}
+
+	groups := make([]uint64, 0, len(layer))
+	for gr := range layer {
+		if _, ok := c.fixed[gr]; !ok && len(c.rank[gr]) == 0 {
+			groups = append(groups, gr)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i] < groups[j] })
+
+	for it := 0; it < iterations; it++ {
+		moved := false
+		for _, gr := range groups {
+			lo, hi := top, bottom
+			slope := 0
+			for _, u := range in[gr] {
+				e := [2]uint64{u, gr}
+				lo = max(lo, layer[u]+c.minLen[e])
+				slope += c.weight[e]
+			}
+			for _, v := range out[gr] {
+				e := [2]uint64{gr, v}
+				hi = min(hi, layer[v]-c.minLen[e])
+				slope -= c.weight[e]
+			}
+			if lo > hi {
+				continue
+			}
+
+			next := layer[gr]
+			switch {
+			case slope > 0:
+				next = lo
+			case slope < 0:
+				next = hi
+			}
+			if next != layer[gr] {
+				layer[gr] = next
+				moved = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+}
+
+// compact removes layers that have no nodes and no edges going through them.
+// Layers above fixed layers are kept, so that fixed layers do not change.
+func (c layerConstraints) compact(layer map[uint64]int) {
+	used := map[int]bool{}
+	for _, l := range c.fixed {
+		for i := 0; i <= l; i++ {
+			used[i] = true
+		}
+	}
+	for _, l := range layer {
+		used[l] = true
+	}
+	for e := range c.minLen {
+		for l := layer[e[0]]; l <= layer[e[1]]; l++ {
+			used[l] = true
+		}
+	}
+
+	levels := make([]int, 0, len(used))
+	for l := range used {
+		levels = append(levels, l)
+	}
+	sort.Ints(levels)
+
+	index := make(map[int]int, len(levels))
+	for i, l := range levels {
+		index[l] = i
+	}
+	for gr, l := range layer {
+		layer[gr] = index[l]
 	}
 }
 
 func maxNodeID(g Graph) uint64 {
 	var maxNodeID uint64
+	for n := range g.Nodes {
+		if n > maxNodeID {
+			maxNodeID = n
+		}
+	}
 	for e := range g.Edges {
 		if e[0] > maxNodeID {
 			maxNodeID = e[0]
@@ -28,33 +170,30 @@ func maxNodeID(g Graph) uint64 {
 	return maxNodeID
 }
 
-func assignLevels(g Graph) map[uint64]LayerPosition {
-	nodeYX := make(map[uint64]LayerPosition, len(g.Nodes))
-	neighbors := make(map[uint64][]uint64)
-	for e := range g.Edges {
-		neighbors[e[0]] = append(neighbors[e[0]], e[1])
-	}
-	for _, root := range g.Roots() {
-		nodeYX[root] = LayerPosition{}
-		for que := []uint64{root}; len(que) > 0; {
-			// pop
-			p := que[0]
-			if len(que) > 1 {
-				que = que[1:]
-			} else {
-				que = nil
-			}
+// makeMinLen collects edges that have to span more than one layer
+func makeMinLen(g Graph) map[[2]uint64]int {
+	minLen := map[[2]uint64]int{}
+	for e, edge := range g.Edges {
+		if l := edgeMinLen(edge); l > 1 {
+			minLen[e] = l
+		}
+	}
+	return minLen
+}
 
-			// set max depth for each child
-			for _, child := range neighbors[p] {
-				if l := nodeYX[p].Layer + 1; l > nodeYX[child].Layer {
-					nodeYX[child] = LayerPosition{Layer: l, Order: 0}
-				}
-				que = append(que, child)
-			}
+// makeWeights sets weight of real edge to all its segments, when it is not 1
+func makeWeights(g Graph, edges map[[2]uint64][]uint64) map[[2]uint64]int {
+	weights := map[[2]uint64]int{}
+	for e, nodes := range edges {
+		w := edgeWeight(g.Edges[e])
+		if w == 1 {
+			continue
+		}
+		for i := 1; i < len(nodes); i++ {
+			weights[[2]uint64{nodes[i-1], nodes[i]}] = w
 		}
 	}
-	return nodeYX
+	return weights
 }
 
 // for each long edge breaks it down to multiple segments, for short edge just adds it
@@ -100,7 +239,7 @@ func makeEdges(g Graph, nodeYX map[uint64]LayerPosition) map[[2]uint64][]uint64
 	edges := make(map[[2]uint64][]uint64, len(g.Edges))
 
 	nextFakeNodeID := maxNodeID(g) + 1
-	for e := range g.Edges {
+	for _, e := range sortedEdges(g.Edges) {
 		fromLayer := nodeYX[e[0]].Layer
 		toLayer := nodeYX[e[1]].Layer
 
@@ -14,9 +14,12 @@ type NodesVerticalCoordinatesAssigner interface {
 }
 
 // Kozo Sugiyama algorithm breaks down layered graph construction in phases.
+// If graph has clusters, their boxes are set to fit members after nodes are positioned.
+// Pinned nodes keep their positions and rest of drawing is moved to fit them, nodes on pinned nodes are moved off them.
+// Layer hints of nodes fix their layer and order.
 type SugiyamaLayersStrategyGraphLayout struct {
 	CycleRemover                       CycleRemover
-	LevelsAssigner                     func(g Graph) LayeredGraph
+	LevelsAssigner                     func(g Graph) LayeredGraph // should honor g.Ranks and Edge.MinLen
 	OrderingAssigner                   func(g Graph, lg LayeredGraph)
 	NodesHorizontalCoordinatesAssigner NodesHorizontalCoordinatesAssigner
 	NodesVerticalCoordinatesAssigner   NodesVerticalCoordinatesAssigner
@@ -25,11 +28,34 @@ type SugiyamaLayersStrategyGraphLayout struct {
 
 // UpdateGraphLayout breaks down layered graph construction in phases.
 func (l SugiyamaLayersStrategyGraphLayout) UpdateGraphLayout(g Graph) {
+	l.UpdateGraphLayoutLayered(g)
+}
+
+// UpdateGraphLayoutLayered is UpdateGraphLayout that also returns layered graph with final ordering.
+// It can be used as previous ordering for next layout of changed graph.
+// It panics when levels assigner makes invalid layered graph, LayoutLayered returns error instead.
+func (l SugiyamaLayersStrategyGraphLayout) UpdateGraphLayoutLayered(g Graph) LayeredGraph {
+	lg, err := l.LayoutLayered(g)
+	if err != nil {
+		panic(err)
+	}
+	return lg
+}
+
+// LayoutLayered is UpdateGraphLayoutLayered that returns error when clusters are not valid,
+// or when levels assigner makes invalid layered graph, like edges that do not go down. Graph is not laid out then.
+// Rank constraints and layer hints that contradict edges are not errors, levels assigners drop them.
+func (l SugiyamaLayersStrategyGraphLayout) LayoutLayered(g Graph) (LayeredGraph, error) {
+	if err := g.ValidateClusters(); err != nil {
+		return LayeredGraph{}, err
+	}
+
 	l.CycleRemover.RemoveCycles(g)
 
 	lg := l.LevelsAssigner(g)
 	if err := lg.Validate(); err != nil {
-		panic(err)
+		l.CycleRemover.Restore(g)
+		return LayeredGraph{}, err
 	}
 
 	l.OrderingAssigner(g, lg)
@@ -43,20 +69,47 @@ func (l SugiyamaLayersStrategyGraphLayout) UpdateGraphLayout(g Graph) {
 		allNodesXY[n] = Position{X: nodeX[n], Y: nodeY[n]}
 	}
 
+	// move drawing to fit pinned nodes, pinned nodes stay where they are
+	computed := make(map[uint64]Position, len(g.Nodes))
+	for n, node := range g.Nodes {
+		computed[n] = Position{X: nodeX[n] - node.W/2, Y: nodeY[n] - node.H/2}
+	}
+	if offset, ok := pinnedOffset(g, computed); ok {
+		for n, p := range allNodesXY {
+			allNodesXY[n] = Position{X: p.X + offset.X, Y: p.Y + offset.Y}
+		}
+		free := make(map[uint64]Position, len(g.Nodes))
+		for n, node := range g.Nodes {
+			if node.Pinned {
+				allNodesXY[n] = node.CenterXY()
+			} else {
+				free[n] = Position{X: allNodesXY[n].X - node.W/2, Y: allNodesXY[n].Y - node.H/2}
+			}
+		}
+		separatePinned(g, free)
+		for n, p := range free {
+			allNodesXY[n] = Position{X: p.X + g.Nodes[n].W/2, Y: p.Y + g.Nodes[n].H/2}
+		}
+	}
+
 	// export coordinates for edges
 	l.EdgePathAssigner(g, lg, allNodesXY)
 
 	// export coordinates to real nodes
 	for n, node := range g.Nodes {
-		g.Nodes[n] = Node{
-			Position: Position{
-				X: nodeX[n] - node.W/2,
-				Y: nodeY[n] - node.H/2,
-			},
-			W: node.W,
-			H: node.H,
+		if node.Pinned {
+			continue
+		}
+		node.Position = Position{
+			X: allNodesXY[n].X - node.W/2,
+			Y: allNodesXY[n].Y - node.H/2,
 		}
+		g.Nodes[n] = node
 	}
 
+	ClustersBoundingBoxLayout{}.UpdateGraphLayout(g)
+
 	l.CycleRemover.Restore(g)
+
+	return lg, nil
 }
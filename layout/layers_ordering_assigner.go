@@ -14,13 +14,34 @@ type LayerOrderingOptimizer interface {
 	Optimize(segments map[[2]uint64]bool, layers [][]uint64, idx int, downUp bool)
 }
 
+// WeightedLayerOrderingOptimizer is LayerOrderingOptimizer that uses weights of segments.
+// Weights of segments that are not in weights map are 1.
+type WeightedLayerOrderingOptimizer interface {
+	LayerOrderingOptimizer
+	OptimizeWeighted(segments map[[2]uint64]bool, weights map[[2]uint64]int, layers [][]uint64, idx int, downUp bool)
+}
+
+// optimizeWeighted passes weights to optimizers that use them.
+func optimizeWeighted(o LayerOrderingOptimizer, segments map[[2]uint64]bool, weights map[[2]uint64]int, layers [][]uint64, idx int, downUp bool) {
+	if w, ok := o.(WeightedLayerOrderingOptimizer); ok {
+		w.OptimizeWeighted(segments, weights, layers, idx, downUp)
+		return
+	}
+	o.Optimize(segments, layers, idx, downUp)
+}
+
 type CompositeLayerOrderingOptimizer struct {
 	Optimizers []LayerOrderingOptimizer
 }
 
 func (o CompositeLayerOrderingOptimizer) Optimize(segments map[[2]uint64]bool, layers [][]uint64, idx int, downUp bool) {
+	o.OptimizeWeighted(segments, nil, layers, idx, downUp)
+}
+
+// OptimizeWeighted passes weights to optimizers that use them.
+func (o CompositeLayerOrderingOptimizer) OptimizeWeighted(segments map[[2]uint64]bool, weights map[[2]uint64]int, layers [][]uint64, idx int, downUp bool) {
 	for _, q := range o.Optimizers {
-		q.Optimize(segments, layers, idx, downUp)
+		optimizeWeighted(q, segments, weights, layers, idx, downUp)
 	}
 }
 
@@ -28,10 +49,14 @@ func (o CompositeLayerOrderingOptimizer) Optimize(segments map[[2]uint64]bool, l
 // Goes up and down number of iterations across all layers.
 // Considers upper and lower fixed and permutes ordering in layer.
 // Used in Graphviz/dot.
+// Members of each cluster are kept contiguous in each layer, and clusters are in same order across layers.
+// Nodes with fixed order in layer hints are kept at their order.
+// Epochs stop when there are no crossings.
 type WarfieldOrderingOptimizer struct {
 	Epochs                   int
 	LayerOrderingInitializer LayerOrderingInitializer
 	LayerOrderingOptimizer   LayerOrderingOptimizer
+	KeepInitial              bool // initial ordering is kept unless some epoch has fewer crossings, for PreviousOrderingInitializer
 }
 
 func (o WarfieldOrderingOptimizer) Optimize(g Graph, lg LayeredGraph) {
@@ -39,17 +64,43 @@ func (o WarfieldOrderingOptimizer) Optimize(g Graph, lg LayeredGraph) {
 	layers := lg.Layers()
 	o.LayerOrderingInitializer.Init(lg.Segments, layers)
 
+	var clusters *clusterTree
+	if len(g.Clusters) > 0 {
+		t := newClusterTree(g)
+		t.addFakeNodes(lg)
+		t.arrangeAll(layers)
+		clusters = &t
+	}
+	for j := range layers {
+		applyOrderHints(g.LayerHints, layers[j])
+	}
+
 	bestN := -1
 	bestLayers := newLayersFrom(layers)
+	if o.KeepInitial {
+		bestN = numCrossings(lg.Segments, layers)
+	}
 
-	for t := 0; t < o.Epochs; t++ {
+	for t := 0; t < o.Epochs && bestN != 0; t++ {
 		downUp := (t % 2) == 0
 		for i := range layers {
 			j := i
 			if downUp {
 				j = len(layers) - 1 - i
 			}
-			o.LayerOrderingOptimizer.Optimize(lg.Segments, layers, j, downUp)
+			optimizeWeighted(o.LayerOrderingOptimizer, lg.Segments, lg.Weights, layers, j, downUp)
+			if clusters != nil {
+				clusters.arrange(layers, j)
+			}
+			applyOrderHints(g.LayerHints, layers[j])
+		}
+
+		// order of clusters can change while going through layers
+		if clusters != nil {
+			clusters.arrangeAll(layers)
+			for j := range layers {
+				applyOrderHints(g.LayerHints, layers[j])
+			}
 		}
 
 		N := numCrossings(lg.Segments, layers)
@@ -58,9 +109,6 @@ func (o WarfieldOrderingOptimizer) Optimize(g Graph, lg LayeredGraph) {
 			copyLayers(bestLayers, layers)
 		}
 		log.Printf("warfield ordering optimizer:\t epoch(%d)\t best(%d)\t current(%d)\n", t, bestN, N)
-		if N == 0 {
-			break
-		}
 	}
 
 	// store to graph
@@ -71,6 +119,48 @@ func (o WarfieldOrderingOptimizer) Optimize(g Graph, lg LayeredGraph) {
 	}
 }
 
+// applyOrderHints moves nodes with fixed order to their position in layer, other nodes keep relative order.
+// Orders outside of layer are moved to closest position in layer.
+func applyOrderHints(hints map[uint64]LayerHint, layer []uint64) {
+	if len(hints) == 0 {
+		return
+	}
+
+	var fixed, free []uint64
+	for _, n := range layer {
+		if hints[n].FixedOrder {
+			fixed = append(fixed, n)
+		} else {
+			free = append(free, n)
+		}
+	}
+	if len(fixed) == 0 {
+		return
+	}
+	sort.Slice(fixed, func(i, j int) bool {
+		if hints[fixed[i]].Order != hints[fixed[j]].Order {
+			return hints[fixed[i]].Order < hints[fixed[j]].Order
+		}
+		return fixed[i] < fixed[j]
+	})
+
+	taken := make([]bool, len(layer))
+	for _, n := range fixed {
+		i := min(max(hints[n].Order, 0), len(layer)-1)
+		for taken[i] {
+			i = (i + 1) % len(layer)
+		}
+		taken[i] = true
+		layer[i] = n
+	}
+	for i := range layer {
+		if !taken[i] {
+			layer[i] = free[0]
+			free = free[1:]
+		}
+	}
+}
+
 // BFSOrderingInitializer will set order in each layer by traversing BFS from roots.
 type BFSOrderingInitializer struct{}
 
@@ -123,6 +213,86 @@ func (o BFSOrderingInitializer) Init(segments map[[2]uint64]bool, layers [][]uin
 	}
 }
 
+// PreviousOrderingInitializer keeps ordering of nodes from previous layered graph, to preserve mental map.
+// Fake nodes are matched by edge and layer they belong to.
+// New nodes are put at average position of their upper neighbors, or at the end of layer if there are none.
+type PreviousOrderingInitializer struct {
+	Previous LayeredGraph
+}
+
+func (o PreviousOrderingInitializer) Init(segments map[[2]uint64]bool, layers [][]uint64) {
+	// previous relative position of real nodes and fake nodes of edges at each layer
+	prevLayers := o.Previous.Layers()
+	prev := map[uint64]float64{}
+	prevFake := map[[3]uint64]float64{}
+	for n, p := range o.Previous.NodePosition {
+		v := (float64(p.Order) + 0.5) / float64(len(prevLayers[p.Layer]))
+		if !o.Previous.Dummy[n] {
+			prev[n] = v
+		}
+	}
+	for e, nodes := range o.Previous.Edges {
+		for _, n := range nodes {
+			if o.Previous.Dummy[n] {
+				p := o.Previous.NodePosition[n]
+				prevFake[[3]uint64{e[0], e[1], uint64(p.Layer)}] = (float64(p.Order) + 0.5) / float64(len(prevLayers[p.Layer]))
+			}
+		}
+	}
+
+	// fake nodes have one upper and one lower neighbor, chains of them lead to real edge ends
+	up := map[uint64][]uint64{}
+	down := map[uint64]uint64{}
+	for e := range segments {
+		up[e[1]] = append(up[e[1]], e[0])
+		down[e[0]] = e[1]
+	}
+	// new real nodes with single parent and child look the same, they are not found in previous fake nodes
+	isFake := func(n uint64) bool {
+		_, known := prev[n]
+		_, hasDown := down[n]
+		return !known && len(up[n]) == 1 && hasDown
+	}
+
+	pos := map[uint64]float64{}
+	for y, layer := range layers {
+		for _, n := range layer {
+			if v, ok := prev[n]; ok {
+				pos[n] = v
+				continue
+			}
+
+			if isFake(n) {
+				from, to := n, n
+				for isFake(from) {
+					from = up[from][0]
+				}
+				for isFake(to) {
+					to = down[to]
+				}
+				if v, ok := prevFake[[3]uint64{from, to, uint64(y)}]; ok {
+					pos[n] = v
+					continue
+				}
+			}
+
+			// new node
+			sum, cnt := 0.0, 0
+			for _, u := range up[n] {
+				if v, ok := pos[u]; ok {
+					sum += v
+					cnt++
+				}
+			}
+			pos[n] = 1
+			if cnt > 0 {
+				pos[n] = sum / float64(cnt)
+			}
+		}
+		sort.SliceStable(layer, func(i, j int) bool { return pos[layer[i]] < pos[layer[j]] })
+	}
+}
+
 // RandomLayerOrderingInitializer assigns random ordering in each layer
 type RandomLayerOrderingInitializer struct{}
 
@@ -137,9 +307,14 @@ func (o RandomLayerOrderingInitializer) Init(_ map[[2]uint64]bool, layers [][]ui
 // Median has property of stable vertical edges which is especially useful for "long" edges (fake nodes).
 // Eades and Wormald, 1994
 // This is used in dot/Graphviz, Figure 3-2 in Graphviz dot paper TSE93.
+// Neighbors connected by heavier segments pull node more, weighted median is used when weights differ.
 type WMedianOrderingOptimizer struct{}
 
 func (o WMedianOrderingOptimizer) Optimize(segments map[[2]uint64]bool, layers [][]uint64, y int, downUp bool) {
+	o.OptimizeWeighted(segments, nil, layers, y, downUp)
+}
+
+func (o WMedianOrderingOptimizer) OptimizeWeighted(segments map[[2]uint64]bool, weights map[[2]uint64]int, layers [][]uint64, y int, downUp bool) {
 	w := map[uint64]float64{}
 
 	for i, node := range layers[y] {
@@ -151,10 +326,16 @@ func (o WMedianOrderingOptimizer) Optimize(segments map[[2]uint64]bool, layers [
 		}
 
 		P := make([]float64, len(xs))
+		W := make([]int, len(xs))
 		for i, v := range xs {
 			P[i] = float64(v)
+			if downUp {
+				W[i] = segmentWeight(weights, [2]uint64{node, layers[y+1][v]})
+			} else {
+				W[i] = segmentWeight(weights, [2]uint64{layers[y-1][v], node})
+			}
 		}
-		w[node] = median(P)
+		w[node] = weightedMedian(P, W)
 	}
 
 	sort.Slice(layers[y], func(i, j int) bool { return w[layers[y][i]] < w[layers[y][j]] })
@@ -214,6 +395,40 @@ func median(P []float64) float64 {
 	}
 }
 
+// weightedMedian is position such that neighbors on each side have at most half of total weight.
+// Same as median when all weights are equal.
+// P has to be sorted.
+func weightedMedian(P []float64, W []int) float64 {
+	total := 0
+	uniform := true
+	for _, w := range W {
+		total += w
+		if w != W[0] {
+			uniform = false
+		}
+	}
+	if uniform {
+		return median(P)
+	}
+
+	acc := 0
+	for i, w := range W {
+		acc += w
+		switch {
+		case 2*acc == total:
+			// next neighbor that has weight, edges of weight 0 do not pull node
+			j := i + 1
+			for W[j] == 0 {
+				j++
+			}
+			return (P[i] + P[j]) / 2
+		case 2*acc > total:
+			return P[i]
+		}
+	}
+	return P[len(P)-1]
+}
+
 // SwitchAdjacentOrderingOptimizer will try swapping two adjacent nodes in a layer will improve crossings.
 // This is used in dot/Graphviz, Figure 3-3 in Graphviz dot paper TSE93 and called "transpose".
 type SwitchAdjacentOrderingOptimizer struct{}
@@ -0,0 +1,430 @@
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// RankType is kind of layer constraint, same as `rank` attribute of subgraphs in Graphviz dot.
+type RankType int
+
+const (
+	RankSame   RankType = iota // all nodes on same layer
+	RankMin                    // all nodes on top layer
+	RankMax                    // all nodes on bottom layer
+	RankSource                 // all nodes on top layer, and only these nodes there
+	RankSink                   // all nodes on bottom layer, and only these nodes there
+)
+
+func (r RankType) String() string {
+	switch r {
+	case RankSame:
+		return "same"
+	case RankMin:
+		return "min"
+	case RankMax:
+		return "max"
+	case RankSource:
+		return "source"
+	case RankSink:
+		return "sink"
+	default:
+		return fmt.Sprintf("RankType(%d)", int(r))
+	}
+}
+
+// RankConstraint tells to which layer nodes should be assigned.
+// All nodes of constraint are also on same layer.
+type RankConstraint struct {
+	Rank  RankType
+	Nodes []NodeID
+}
+
+// layerConstraints are rank constraints and edge lengths prepared for levels assignment.
+// Nodes that have to be on same layer are merged in groups, layers are assigned to groups.
+type layerConstraints struct {
+	group  map[uint64]uint64            // node -> group representative
+	minLen map[[2]uint64]int            // edge between groups -> minimum length
+	weight map[[2]uint64]int            // edge between groups -> total weight of edges
+	rank   map[uint64]map[RankType]bool // group -> constraints
+	fixed  map[uint64]int               // group -> layer from hints
+}
+
+func edgeMinLen(e Edge) int {
+	if e.MinLen < 1 {
+		return 1
+	}
+	return e.MinLen
+}
+
+func newLayerConstraints(g Graph) layerConstraints {
+	c := layerConstraints{
+		group:  make(map[uint64]uint64, len(g.Nodes)),
+		minLen: make(map[[2]uint64]int, len(g.Edges)),
+		weight: make(map[[2]uint64]int, len(g.Edges)),
+		rank:   make(map[uint64]map[RankType]bool),
+		fixed:  make(map[uint64]int),
+	}
+
+	var find func(n uint64) uint64
+	find = func(n uint64) uint64 {
+		p, ok := c.group[n]
+		if !ok || p == n {
+			c.group[n] = n
+			return n
+		}
+		r := find(p)
+		c.group[n] = r
+		return r
+	}
+
+	for n := range g.Nodes {
+		find(n)
+	}
+	for e := range g.Edges {
+		find(e[0])
+		find(e[1])
+	}
+
+	// nodes that are not in graph are ignored,
+	// constraints that would put edge inside layer or make cycle between layers are dropped
+	var ranks []RankConstraint
+	for _, r := range g.Ranks {
+		var nodes []uint64
+		for _, n := range r.Nodes {
+			if _, ok := c.group[n]; ok {
+				nodes = append(nodes, n)
+			}
+		}
+		if len(nodes) == 0 {
+			continue
+		}
+
+		// groups of nodes of constraint are merged in group of first node
+		joined := make(map[uint64]bool, len(nodes))
+		for _, n := range nodes {
+			joined[find(n)] = true
+		}
+		merged := make(map[uint64]uint64, len(c.group))
+		for n := range c.group {
+			merged[n] = find(n)
+			if joined[merged[n]] {
+				merged[n] = find(nodes[0])
+			}
+		}
+		if !acyclicGroups(g, merged) {
+			log.Printf("layers: rank(%s) constraint of nodes(%v) contradicts edges, it is ignored", r.Rank, nodes)
+			continue
+		}
+		c.group = merged
+		ranks = append(ranks, RankConstraint{Rank: r.Rank, Nodes: nodes})
+	}
+
+	for _, r := range ranks {
+		if r.Rank == RankSame {
+			continue
+		}
+		gr := c.group[r.Nodes[0]]
+		if c.rank[gr] == nil {
+			c.rank[gr] = map[RankType]bool{}
+		}
+		c.rank[gr][r.Rank] = true
+	}
+
+	for n, h := range g.LayerHints {
+		if gr, ok := c.group[n]; ok && h.FixedLayer {
+			c.fixed[gr] = max(c.fixed[gr], h.Layer)
+		}
+	}
+
+	for e, edge := range g.Edges {
+		ge := [2]uint64{c.group[e[0]], c.group[e[1]]}
+		if ge[0] == ge[1] {
+			continue
+		}
+		if l := edgeMinLen(edge); l > c.minLen[ge] {
+			c.minLen[ge] = l
+		}
+		c.weight[ge] += edgeWeight(edge)
+	}
+
+	return c
+}
+
+// acyclicGroups tells if edges between groups do not make cycles, and no edge is inside group.
+func acyclicGroups(g Graph, group map[uint64]uint64) bool {
+	out := make(map[uint64][]uint64)
+	indegree := make(map[uint64]int)
+	for e := range g.Edges {
+		from, to := group[e[0]], group[e[1]]
+		if e[0] == e[1] {
+			continue
+		}
+		if from == to {
+			return false
+		}
+		out[from] = append(out[from], to)
+		indegree[to]++
+	}
+
+	var que []uint64
+	for n, gr := range group {
+		if n == gr && indegree[gr] == 0 {
+			que = append(que, gr)
+		}
+	}
+	visited := 0
+	for len(que) > 0 {
+		gr := que[0]
+		que = que[1:]
+		visited++
+		for _, child := range out[gr] {
+			indegree[child]--
+			if indegree[child] == 0 {
+				que = append(que, child)
+			}
+		}
+	}
+
+	groups := 0
+	for n, gr := range group {
+		if n == gr {
+			groups++
+		}
+	}
+	return visited == groups
+}
+
+// assign computes longest path layering of groups, honoring minimum lengths of edges and rank constraints.
+// Groups that are part of cycle are assigned after their predecessors that are not part of cycle,
+// which will be reported as violation by validation of layered graph.
+func (c layerConstraints) assign() map[uint64]int {
+	groups := make(map[uint64]bool)
+	for _, gr := range c.group {
+		groups[gr] = true
+	}
+
+	hasSource := false
+	for _, r := range c.rank {
+		if r[RankSource] {
+			hasSource = true
+		}
+	}
+
+	out := make(map[uint64][]uint64)
+	indegree := make(map[uint64]int)
+	for e := range c.minLen {
+		out[e[0]] = append(out[e[0]], e[1])
+		indegree[e[1]]++
+	}
+
+	layer := make(map[uint64]int, len(groups))
+	var que []uint64
+	for gr := range groups {
+		layer[gr] = 0
+		if hasSource && !c.rank[gr][RankSource] {
+			layer[gr] = 1
+		}
+		if l, ok := c.fixed[gr]; ok {
+			layer[gr] = max(layer[gr], l)
+		}
+		if indegree[gr] == 0 {
+			que = append(que, gr)
+		}
+	}
+
+	var order []uint64
+	for len(que) > 0 {
+		p := que[0]
+		que = que[1:]
+		order = append(order, p)
+		for _, child := range out[p] {
+			if l := layer[p] + c.minLen[[2]uint64{p, child}]; l > layer[child] {
+				layer[child] = l
+			}
+			indegree[child]--
+			if indegree[child] == 0 {
+				que = append(que, child)
+			}
+		}
+	}
+
+	// push sinks and max groups to the bottom, only if it does not break edges
+	maxLayer, maxNotSink := 0, -1
+	for gr, l := range layer {
+		if l > maxLayer {
+			maxLayer = l
+		}
+		if !c.rank[gr][RankSink] && l > maxNotSink {
+			maxNotSink = l
+		}
+	}
+
+	sinkLayer := -1
+	for gr, l := range layer {
+		if c.rank[gr][RankSink] {
+			sinkLayer = max(sinkLayer, l, maxNotSink+1)
+		}
+	}
+	if sinkLayer >= 0 {
+		maxLayer = max(maxLayer, sinkLayer)
+	}
+
+	for _, gr := range order {
+		if _, ok := c.fixed[gr]; ok || len(out[gr]) > 0 {
+			continue
+		}
+		switch {
+		case c.rank[gr][RankSink]:
+			layer[gr] = sinkLayer
+		case c.rank[gr][RankMax]:
+			layer[gr] = maxLayer
+		}
+	}
+
+	return layer
+}
+
+// nodeLayers expands layers of groups to all nodes.
+func (c layerConstraints) nodeLayers(groupLayer map[uint64]int) map[uint64]LayerPosition {
+	positions := make(map[uint64]LayerPosition, len(c.group))
+	for n, gr := range c.group {
+		positions[n] = LayerPosition{Layer: groupLayer[gr]}
+	}
+	return positions
+}
+
+// dropViolated removes rank constraints and fixed layers of hints that layers could not satisfy,
+// because they contradict edges or each other. They are best effort, like in Graphviz dot, and are logged.
+func (g *LayeredGraph) dropViolated() {
+	probe := LayeredGraph{NodePosition: g.NodePosition, Dummy: g.Dummy}
+
+	var ranks []RankConstraint
+	for _, r := range g.Ranks {
+		probe.Ranks = append(append([]RankConstraint(nil), ranks...), r)
+		if err := probe.validateConstraints(); err != nil {
+			log.Printf("layers: rank(%s) constraint of nodes(%v) is ignored: %v", r.Rank, r.Nodes, err)
+			continue
+		}
+		ranks = append(ranks, r)
+	}
+	probe.Ranks = nil
+
+	hints := make(map[uint64]LayerHint, len(g.Hints))
+	for n, h := range g.Hints {
+		probe.Hints = map[uint64]LayerHint{n: h}
+		if err := probe.validateConstraints(); err != nil {
+			log.Printf("layers: fixed layer of node(%d) is ignored: %v", n, err)
+			h.FixedLayer = false
+		}
+		hints[n] = h
+	}
+
+	g.Ranks, g.Hints = ranks, hints
+}
+
+// validateConstraints reports all rank constraints, layer hints and minimum edge lengths that are violated in layered graph.
+func (g LayeredGraph) validateConstraints() error {
+	var errs []error
+
+	edges := make([][2]uint64, 0, len(g.MinLen))
+	for e := range g.MinLen {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+	for _, e := range edges {
+		from := g.NodePosition[e[0]].Layer
+		to := g.NodePosition[e[1]].Layer
+		if to-from < g.MinLen[e] {
+			errs = append(errs, fmt.Errorf("edge(%v) spans %d layers, but minimum length is %d", e, to-from, g.MinLen[e]))
+		}
+	}
+
+	hinted := make([]uint64, 0, len(g.Hints))
+	for n, h := range g.Hints {
+		if _, ok := g.NodePosition[n]; ok && h.FixedLayer {
+			hinted = append(hinted, n)
+		}
+	}
+	sort.Slice(hinted, func(i, j int) bool { return hinted[i] < hinted[j] })
+	for _, n := range hinted {
+		if l := g.NodePosition[n].Layer; l != g.Hints[n].Layer {
+			errs = append(errs, fmt.Errorf("node(%d) is on layer(%d), but its hint requires layer(%d)", n, l, g.Hints[n].Layer))
+		}
+	}
+
+	if len(g.Ranks) == 0 {
+		return errors.Join(errs...)
+	}
+
+	minLayer, maxLayer := -1, -1
+	for n, p := range g.NodePosition {
+		if g.Dummy[n] {
+			continue
+		}
+		if minLayer < 0 || p.Layer < minLayer {
+			minLayer = p.Layer
+		}
+		if p.Layer > maxLayer {
+			maxLayer = p.Layer
+		}
+	}
+
+	exclusive := map[int]map[uint64]bool{} // layer -> nodes allowed in it
+	for _, r := range g.Ranks {
+		var nodes []uint64
+		for _, n := range r.Nodes {
+			if _, ok := g.NodePosition[n]; ok {
+				nodes = append(nodes, n)
+			}
+		}
+		if len(nodes) == 0 {
+			continue
+		}
+
+		target := g.NodePosition[nodes[0]].Layer
+		switch r.Rank {
+		case RankMin, RankSource:
+			target = minLayer
+		case RankMax, RankSink:
+			target = maxLayer
+		}
+
+		for _, n := range nodes {
+			if l := g.NodePosition[n].Layer; l != target {
+				errs = append(errs, fmt.Errorf("node(%d) is on layer(%d), but rank(%s) constraint requires layer(%d)", n, l, r.Rank, target))
+			}
+		}
+
+		if r.Rank == RankSource || r.Rank == RankSink {
+			if exclusive[target] == nil {
+				exclusive[target] = map[uint64]bool{}
+			}
+			for _, n := range nodes {
+				exclusive[target][n] = true
+			}
+		}
+	}
+
+	for l, allowed := range exclusive {
+		var others []uint64
+		for n, p := range g.NodePosition {
+			if p.Layer == l && !allowed[n] {
+				others = append(others, n)
+			}
+		}
+		if len(others) > 0 {
+			sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+			errs = append(errs, fmt.Errorf("nodes(%v) are on layer(%d) reserved for source or sink rank constraint", others, l))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,147 @@
+package layout_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+func TestRankConstraints(t *testing.T) {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{},
+		Edges: map[[2]uint64]layout.Edge{
+			{1, 2}: {},
+			{2, 3}: {},
+			{1, 4}: {MinLen: 3},
+			{5, 6}: {},
+		},
+		Ranks: []layout.RankConstraint{
+			{Rank: layout.RankSame, Nodes: []uint64{2, 6}},
+			{Rank: layout.RankMax, Nodes: []uint64{3}},
+			{Rank: layout.RankSource, Nodes: []uint64{1}},
+		},
+	}
+	for i := uint64(1); i <= 6; i++ {
+		g.Nodes[i] = layout.Node{W: 10, H: 10}
+	}
+
+	lg := layout.NewLayeredGraph(g)
+	if err := lg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	layer := func(n uint64) int { return lg.NodePosition[n].Layer }
+	if layer(2) != layer(6) {
+		t.Errorf("same rank nodes on layers %d and %d", layer(2), layer(6))
+	}
+	if layer(4)-layer(1) < 3 {
+		t.Errorf("edge with minimum length 3 spans %d layers", layer(4)-layer(1))
+	}
+	if layer(1) != 0 {
+		t.Errorf("source node on layer %d", layer(1))
+	}
+	if layer(5) == 0 {
+		t.Errorf("node is on layer of source")
+	}
+	if layer(3) != layer(4) {
+		t.Errorf("max node on layer %d, expected %d", layer(3), layer(4))
+	}
+}
+
+func TestRankConstraintsSink(t *testing.T) {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{1: {}, 2: {}, 3: {}, 4: {}},
+		Edges: map[[2]uint64]layout.Edge{{1, 2}: {}, {2, 3}: {}},
+		Ranks: []layout.RankConstraint{{Rank: layout.RankSink, Nodes: []uint64{4}}},
+	}
+
+	lg := layout.NewLayeredGraph(g)
+	if err := lg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if l := lg.NodePosition[4].Layer; l != 3 {
+		t.Errorf("sink node on layer %d, expected 3", l)
+	}
+}
+
+func TestRankConstraintsMinWithParents(t *testing.T) {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{1: {}, 2: {}, 3: {}},
+		Edges: map[[2]uint64]layout.Edge{{1, 2}: {}, {2, 3}: {}},
+		Ranks: []layout.RankConstraint{{Rank: layout.RankMin, Nodes: []uint64{3}}},
+	}
+
+	// min rank of node with parents is dropped, edges keep going down
+	lg := layout.NewLayeredGraph(g)
+	if err := lg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(lg.Ranks) != 0 {
+		t.Errorf("expected dropped constraint, got %v", lg.Ranks)
+	}
+	if l := lg.NodePosition[3].Layer; l != 2 {
+		t.Errorf("node on layer %d, expected 2", l)
+	}
+}
+
+func TestRankConstraintsSameAcrossEdge(t *testing.T) {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{1: {}, 2: {}, 3: {}, 4: {}},
+		Edges: map[[2]uint64]layout.Edge{{1, 2}: {}, {2, 3}: {}, {1, 4}: {}},
+		Ranks: []layout.RankConstraint{
+			{Rank: layout.RankSame, Nodes: []uint64{1, 2}}, // edge inside layer
+			{Rank: layout.RankSame, Nodes: []uint64{3, 4}},
+			{Rank: layout.RankSame, Nodes: []uint64{4, 2}}, // cycle with previous constraint
+		},
+	}
+
+	lg := layout.NewLayeredGraph(g)
+	if err := lg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(lg.Ranks) != 1 || lg.Ranks[0].Nodes[0] != 3 {
+		t.Errorf("expected only constraint of nodes 3 and 4, got %v", lg.Ranks)
+	}
+	if lg.NodePosition[3].Layer != lg.NodePosition[4].Layer {
+		t.Errorf("same rank nodes on layers %d and %d", lg.NodePosition[3].Layer, lg.NodePosition[4].Layer)
+	}
+}
+
+func TestRankConstraintsFixedLayerAboveParent(t *testing.T) {
+	g := layout.Graph{
+		Nodes:      map[uint64]layout.Node{1: {}, 2: {}, 3: {}},
+		Edges:      map[[2]uint64]layout.Edge{{1, 2}: {}, {2, 3}: {}},
+		LayerHints: map[uint64]layout.LayerHint{3: {Layer: 1, FixedLayer: true, Order: 2, FixedOrder: true}},
+	}
+
+	lg := layout.NewLayeredGraph(g)
+	if err := lg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if h := lg.Hints[3]; h.FixedLayer || !h.FixedOrder {
+		t.Errorf("expected only fixed layer to be dropped, got %+v", h)
+	}
+	if !g.LayerHints[3].FixedLayer {
+		t.Errorf("hints of graph should not change")
+	}
+}
+
+func TestLayoutLayeredInvalidLevels(t *testing.T) {
+	l := layout.SugiyamaLayersStrategyGraphLayout{
+		CycleRemover: layout.NewSimpleCycleRemover(),
+		LevelsAssigner: func(g layout.Graph) layout.LayeredGraph {
+			lg := layout.NewLayeredGraph(g)
+			lg.NodePosition[2] = lg.NodePosition[1] // edge inside layer
+			return lg
+		},
+	}
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{1: {}, 2: {}},
+		Edges: map[[2]uint64]layout.Edge{{1, 2}: {}},
+	}
+
+	if _, err := l.LayoutLayered(g); err == nil || !strings.Contains(err.Error(), "wrong direction") {
+		t.Errorf("expected error of edge direction, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+func weight(w int) *int { return &w }
+
+func TestWeightedLevelsAssigner(t *testing.T) {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{1: {}, 2: {}, 3: {}, 4: {}, 5: {}},
+		Edges: map[[2]uint64]layout.Edge{
+			{1, 2}: {},
+			{2, 3}: {},
+			{3, 4}: {Weight: weight(10)},
+			{5, 4}: {Weight: weight(5)},
+		},
+	}
+
+	lg := layout.WeightedLevelsAssigner{Iterations: 10}.NewLayeredGraph(g)
+	if err := lg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if l := lg.NodePosition[4].Layer - lg.NodePosition[5].Layer; l != 1 {
+		t.Errorf("heavy edge spans %d layers, expected 1", l)
+	}
+	if l := lg.NodePosition[1].Layer; l != 0 {
+		t.Errorf("root is on layer %d, expected 0", l)
+	}
+}
+
+func TestWeightedEdgeIsStraight(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		g := layout.Graph{
+			Nodes: map[uint64]layout.Node{1: {W: 10, H: 10}, 2: {W: 10, H: 10}, 3: {W: 10, H: 10}, 4: {W: 10, H: 10}},
+			Edges: map[[2]uint64]layout.Edge{
+				{1, 2}: {},
+				{1, 3}: {},
+				{2, 4}: {},
+				{3, 4}: {Weight: weight(10)},
+			},
+		}
+
+		newLayersLayout().UpdateGraphLayout(g)
+
+		if x3, x4 := g.Nodes[3].CenterXY().X, g.Nodes[4].CenterXY().X; x3 != x4 {
+			t.Errorf("heavy edge is not vertical: %d != %d", x3, x4)
+		}
+	}
+}
+
+func TestZeroWeightEdgeIsIgnored(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		g := layout.Graph{
+			Nodes: map[uint64]layout.Node{1: {W: 10, H: 10}, 2: {W: 10, H: 10}, 3: {W: 10, H: 10}, 4: {W: 10, H: 10}, 5: {W: 10, H: 10}},
+			Edges: map[[2]uint64]layout.Edge{
+				{1, 2}: {},
+				{1, 3}: {},
+				{1, 5}: {},
+				{2, 4}: {Weight: weight(0)},
+				{3, 4}: {Weight: weight(0)},
+				{5, 4}: {},
+			},
+		}
+
+		newLayersLayout().UpdateGraphLayout(g)
+
+		if x5, x4 := g.Nodes[5].CenterXY().X, g.Nodes[4].CenterXY().X; x5 != x4 {
+			t.Errorf("only edge with weight is not vertical: %d != %d", x5, x4)
+		}
+	}
+}
+
+// reverseOptimizer is optimizer that does not use weights.
+type reverseOptimizer struct{ calls *int }
+
+func (o reverseOptimizer) Optimize(segments map[[2]uint64]bool, layers [][]uint64, idx int, downUp bool) {
+	*o.calls++
+}
+
+func TestWeightedOrderingOptimizer(t *testing.T) {
+	calls := 0
+	o := layout.CompositeLayerOrderingOptimizer{
+		Optimizers: []layout.LayerOrderingOptimizer{layout.WMedianOrderingOptimizer{}, reverseOptimizer{calls: &calls}},
+	}
+
+	segments := map[[2]uint64]bool{{1, 4}: true, {2, 4}: true, {3, 4}: true, {2, 5}: true}
+	weights := map[[2]uint64]int{{3, 4}: 10}
+	layers := [][]uint64{{1, 2, 3}, {4, 5}}
+
+	// heavy segment pulls node 4 to the right of node 5
+	o.OptimizeWeighted(segments, weights, layers, 1, false)
+	if layers[1][0] != 5 || layers[1][1] != 4 {
+		t.Errorf("unexpected order %v", layers[1])
+	}
+	if calls != 1 {
+		t.Errorf("optimizer without weights is called %d times", calls)
+	}
+}
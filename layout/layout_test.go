@@ -2,6 +2,7 @@ package layout_test
 
 import (
 	_ "embed"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
@@ -72,10 +73,14 @@ func writeSVG(gd graph.Graph, gl layout.Graph) string {
 		ID:          "svg-root",
 		Definitions: []svg.Renderable{},
 		Body:        graph,
+		Margin:      10,
 	}
 	return svgContainer.Render()
 }
 
+// update writes example SVGs to testdata, instead of comparing with them.
+var update = flag.Bool("update", false, "update SVGs in testdata")
+
 //go:embed testdata/gin.jsonl
 var ginJSONL string
 
@@ -137,6 +142,7 @@ func TestE2E(t *testing.T) {
 			l: layout.SequenceLayout{
 				Layouts: []layout.Layout{
 					layout.EadesGonumLayout{
+						Seed:      1,
 						Repulsion: 1,
 						Rate:      0.05,
 						Updates:   30,
@@ -197,17 +203,22 @@ func TestE2E(t *testing.T) {
 
 				l.l.UpdateGraphLayout(*gl)
 				svgResult := writeSVG(*gd, *gl)
-
-				outputfile, err := os.Create(name)
-				if err != nil {
-					t.Error(err)
+				if again := writeSVG(*gd, *gl); again != svgResult {
+					t.Errorf("svg of same layout is not stable")
+				}
+				if *update {
+					if err := os.WriteFile(name, []byte(svgResult), 0o644); err != nil {
+						t.Error(err)
+					}
+					return
 				}
 
-				if _, err := outputfile.WriteString(svgResult); err != nil {
-					t.Error(err)
+				expected, err := os.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
 				}
-				if err := outputfile.Close(); err != nil {
-					t.Error(err)
+				if string(expected) != svgResult {
+					t.Errorf("svg differs from %s, run tests with -update when change is expected", name)
 				}
 			})
 		}
@@ -0,0 +1,107 @@
+package layout
+
+import "sort"
+
+// LayerHint fixes layer and/or order within layer of node in layered layouts.
+// Layer and Order are used only when corresponding Fixed flag is set.
+type LayerHint struct {
+	Layer      int
+	Order      int
+	FixedLayer bool
+	FixedOrder bool
+}
+
+// pinnedOffset is translation that moves computed top left positions of pinned nodes closest to their pinned positions.
+// Layouts apply it to all nodes, so that rest of graph is placed around pinned nodes.
+// Returns false if graph has no pinned nodes.
+func pinnedOffset(g Graph, computed map[uint64]Position) (Position, bool) {
+	var dx, dy, n int
+	for id, node := range g.Nodes {
+		if !node.Pinned {
+			continue
+		}
+		p, ok := computed[id]
+		if !ok {
+			continue
+		}
+		dx += node.X - p.X
+		dy += node.Y - p.Y
+		n++
+	}
+	if n == 0 {
+		return Position{}, false
+	}
+	return Position{X: dx / n, Y: dy / n}, true
+}
+
+// updatePositions sets computed top left positions to nodes that are not pinned.
+// Computed positions are translated to fit pinned nodes, and nodes are moved off pinned nodes.
+func updatePositions(g Graph, computed map[uint64]Position) {
+	offset, _ := pinnedOffset(g, computed)
+	free := make(map[uint64]Position, len(computed))
+	for id, p := range computed {
+		if node, ok := g.Nodes[id]; ok && !node.Pinned {
+			free[id] = Position{X: p.X + offset.X, Y: p.Y + offset.Y}
+		}
+	}
+	separatePinned(g, free)
+	for id, p := range free {
+		node := g.Nodes[id]
+		node.Position = p
+		g.Nodes[id] = node
+	}
+}
+
+// pinnedMargin is space between pinned nodes and nodes moved off them.
+const pinnedMargin = 10
+
+// separatePinned moves top left positions of nodes that are not pinned off boxes of pinned nodes,
+// by shortest way out of each box. Node moved off one box can land on other box, so it is repeated for each pinned node.
+func separatePinned(g Graph, free map[uint64]Position) {
+	var pinned []uint64
+	for id, node := range g.Nodes {
+		if node.Pinned {
+			pinned = append(pinned, id)
+		}
+	}
+	if len(pinned) == 0 {
+		return
+	}
+	sort.Slice(pinned, func(i, j int) bool { return pinned[i] < pinned[j] })
+
+	for id, p := range free {
+		node := g.Nodes[id]
+		for range pinned {
+			moved := false
+			for _, pid := range pinned {
+				pin := g.Nodes[pid]
+				left := pin.X - pinnedMargin - (p.X + node.W)
+				right := pin.X + pin.W + pinnedMargin - p.X
+				up := pin.Y - pinnedMargin - (p.Y + node.H)
+				down := pin.Y + pin.H + pinnedMargin - p.Y
+				if left >= 0 || right <= 0 || up >= 0 || down <= 0 {
+					continue
+				}
+				dx, dy := left, 0
+				for _, d := range [][2]int{{right, 0}, {0, up}, {0, down}} {
+					if abs(d[0])+abs(d[1]) < abs(dx)+abs(dy) {
+						dx, dy = d[0], d[1]
+					}
+				}
+				p = Position{X: p.X + dx, Y: p.Y + dy}
+				moved = true
+			}
+			if !moved {
+				break
+			}
+		}
+		free[id] = p
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
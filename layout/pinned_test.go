@@ -0,0 +1,104 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+func newPinnedGraph() layout.Graph {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{},
+		Edges: map[[2]uint64]layout.Edge{{1, 2}: {}, {1, 3}: {}, {2, 4}: {}, {3, 4}: {}, {4, 5}: {}},
+	}
+	for i := uint64(1); i <= 5; i++ {
+		g.Nodes[i] = layout.Node{W: 20, H: 10}
+	}
+	g.Nodes[1] = layout.Node{Position: layout.Position{X: 500, Y: -300}, W: 20, H: 10, Pinned: true}
+	g.Nodes[5] = layout.Node{Position: layout.Position{X: -100, Y: 800}, W: 20, H: 10, Pinned: true}
+	return g
+}
+
+func pinnedLayouts() map[string]layout.Layout {
+	return map[string]layout.Layout{
+		"forces": layout.ForceGraphLayout{
+			Delta:    1,
+			MaxSteps: 100,
+			Epsilon:  1.5,
+			Forces: []layout.Force{
+				layout.GravityForce{K: -50},
+				layout.SpringForce{K: 0.2, L: 200, EdgesOnly: true},
+			},
+		},
+		"eades":  layout.EadesGonumLayout{Repulsion: 1, Rate: 0.05, Updates: 30, Theta: 0.2, ScaleX: 0.5, ScaleY: 0.5},
+		"isomap": layout.IsomapR2GonumLayout{ScaleX: 0.5, ScaleY: 0.5},
+		"layers": newLayersLayout(),
+		"scaler": &layout.ScalerLayout{Scale: 2},
+	}
+}
+
+func TestPinnedNodes(t *testing.T) {
+	for name, l := range pinnedLayouts() {
+		t.Run(name, func(t *testing.T) {
+			g := newPinnedGraph()
+			l.UpdateGraphLayout(g)
+
+			if p := g.Nodes[1].Position; p != (layout.Position{X: 500, Y: -300}) {
+				t.Errorf("pinned node moved to %+v", p)
+			}
+			if p := g.Nodes[5].Position; p != (layout.Position{X: -100, Y: 800}) {
+				t.Errorf("pinned node moved to %+v", p)
+			}
+		})
+	}
+}
+
+func TestPinnedNodesOverlap(t *testing.T) {
+	for name, l := range pinnedLayouts() {
+		if name == "scaler" {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			// large pinned node in middle of drawing
+			g := newPinnedGraph()
+			g.Nodes[3] = layout.Node{Position: layout.Position{X: 0, Y: 0}, W: 400, H: 400, Pinned: true}
+			l.UpdateGraphLayout(g)
+
+			for id, node := range g.Nodes {
+				if node.Pinned {
+					continue
+				}
+				for pid, pin := range g.Nodes {
+					if pin.Pinned && node.X < pin.X+pin.W && pin.X < node.X+node.W && node.Y < pin.Y+pin.H && pin.Y < node.Y+node.H {
+						t.Errorf("node %d at %+v overlaps pinned node %d at %+v", id, node.Position, pid, pin.Position)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLayerHints(t *testing.T) {
+	g := newPinnedGraph()
+	g.LayerHints = map[uint64]layout.LayerHint{
+		2: {Layer: 2, FixedLayer: true},
+		3: {Order: 1, FixedOrder: true},
+	}
+
+	lg := layout.NewLayeredGraph(g)
+	if err := lg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if l := lg.NodePosition[2].Layer; l != 2 {
+		t.Errorf("node is on layer %d, expected 2", l)
+	}
+
+	layout.WarfieldOrderingOptimizer{
+		Epochs:                   10,
+		LayerOrderingInitializer: layout.RandomLayerOrderingInitializer{},
+		LayerOrderingOptimizer:   layout.WMedianOrderingOptimizer{},
+	}.Optimize(g, lg)
+	if o := lg.NodePosition[3].Order; o != 1 {
+		t.Errorf("node has order %d, expected 1", o)
+	}
+}
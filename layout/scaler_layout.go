@@ -1,23 +1,19 @@
 package layout
 
 // ScalerLayout will scale existing layout by constant factor.
+// Pinned nodes are not moved.
 type ScalerLayout struct {
 	Scale float64
 }
 
 func (l *ScalerLayout) UpdateGraphLayout(g Graph) {
-	for i := range g.Nodes {
-		x := float64(g.Nodes[i].X)
-		y := float64(g.Nodes[i].Y)
-
-		g.Nodes[i] = Node{
-			Position: Position{
-				X: int(x * l.Scale),
-				Y: int(y * l.Scale),
-			},
-			W: g.Nodes[i].W,
-			H: g.Nodes[i].H,
+	for i, node := range g.Nodes {
+		if node.Pinned {
+			continue
 		}
+		node.X = int(float64(node.X) * l.Scale)
+		node.Y = int(float64(node.Y) * l.Scale)
+		g.Nodes[i] = node
 	}
 
 	// can not recompute edge layout as some paths are complex and not direct
@@ -30,8 +26,9 @@ func (l *ScalerLayout) UpdateGraphLayout(g Graph) {
 		}
 
 		// if edge was not previously set adding at least two nodes for start and end
-		if len(g.Edges[e].Path) == 0 {
-			g.Edges[e] = Edge{Path: make([]Position, 2)}
+		if edge := g.Edges[e]; len(edge.Path) == 0 {
+			edge.Path = make([]Position, 2)
+			g.Edges[e] = edge
 		}
 
 		// end and start should use center coordinates of nodes
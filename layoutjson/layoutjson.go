@@ -0,0 +1,337 @@
+// Package layoutjson is stable JSON schema of laid out graph, so positions computed in Go can be rendered elsewhere.
+//
+// Coordinates are integers with y pointing down, as in layout package.
+// Node and cluster position is top left corner of their box, edge path goes from center of source node to center of target node.
+//
+//	{
+//	  "bbox": {"x": 0, "y": 0, "w": 120, "h": 80},
+//	  "nodes": [{"id": "a", "label": "A", "x": 0, "y": 0, "w": 40, "h": 20}],
+//	  "edges": [{"from": "a", "to": "b", "path": [{"x": 20, "y": 10}, {"x": 20, "y": 70}], "label": {"text": "ab", "x": 20, "y": 40}}],
+//	  "clusters": [{"id": "c", "x": 0, "y": 0, "w": 120, "h": 80, "nodes": ["a"]}]
+//	}
+package layoutjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+// Graph is laid out graph. Nodes, edges and clusters are ordered, so output is deterministic.
+type Graph struct {
+	BBox     Box       `json:"bbox"` // box that contains nodes, clusters and edges
+	Nodes    []Node    `json:"nodes"`
+	Edges    []Edge    `json:"edges"`
+	Clusters []Cluster `json:"clusters,omitempty"`
+}
+
+// Box is top left corner and size.
+type Box struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Point is point of edge path.
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Node is node box, Label is text drawn in node, when it is different from ID.
+type Node struct {
+	ID     string `json:"id"`
+	Label  string `json:"label,omitempty"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	W      int    `json:"w"`
+	H      int    `json:"h"`
+	Pinned bool   `json:"pinned,omitempty"`
+}
+
+// Edge is path of edge, MinLen and Weight are same as in layout.Edge.
+type Edge struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Path   []Point `json:"path"`
+	Label  *Label  `json:"label,omitempty"`
+	MinLen int     `json:"min_len,omitempty"`
+	Weight *int    `json:"weight,omitempty"`
+}
+
+// Label is text of edge and position of its center.
+type Label struct {
+	Text string `json:"text"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+// NewLabel is label with text at middle of path, by length of path.
+func NewLabel(text string, path []Point) *Label {
+	l := &Label{Text: text}
+	if len(path) == 0 {
+		return l
+	}
+
+	var total float64
+	for i := 1; i < len(path); i++ {
+		total += math.Hypot(float64(path[i].X-path[i-1].X), float64(path[i].Y-path[i-1].Y))
+	}
+	l.X, l.Y = path[0].X, path[0].Y
+	for i, rest := 1, total/2; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		d := math.Hypot(float64(b.X-a.X), float64(b.Y-a.Y))
+		if d >= rest && d > 0 {
+			t := rest / d
+			l.X = a.X + int(math.Round(t*float64(b.X-a.X)))
+			l.Y = a.Y + int(math.Round(t*float64(b.Y-a.Y)))
+			break
+		}
+		rest -= d
+	}
+	return l
+}
+
+// Cluster is box of group of nodes, clusters with Parent are nested.
+type Cluster struct {
+	ID     string   `json:"id"`
+	Label  string   `json:"label,omitempty"`
+	Parent string   `json:"parent,omitempty"`
+	X      int      `json:"x"`
+	Y      int      `json:"y"`
+	W      int      `json:"w"`
+	H      int      `json:"h"`
+	Margin int      `json:"margin,omitempty"`
+	Nodes  []string `json:"nodes,omitempty"` // direct members
+}
+
+// FromLayout converts layout graph to schema.
+// Node IDs are decimal layout node IDs, nodes are ordered by their IDs, edges by their ends, clusters by their IDs.
+func FromLayout(g layout.Graph) *Graph {
+	out := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	id := func(n layout.NodeID) string { return strconv.FormatUint(n, 10) }
+
+	nodes := make([]layout.NodeID, 0, len(g.Nodes))
+	for n := range g.Nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+	for _, n := range nodes {
+		node := g.Nodes[n]
+		out.Nodes = append(out.Nodes, Node{ID: id(n), X: node.X, Y: node.Y, W: node.W, H: node.H, Pinned: node.Pinned})
+	}
+
+	edges := make([][2]layout.NodeID, 0, len(g.Edges))
+	for e := range g.Edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+	for _, e := range edges {
+		edge := g.Edges[e]
+		path := make([]Point, 0, len(edge.Path))
+		for _, p := range edge.Path {
+			path = append(path, Point{X: p.X, Y: p.Y})
+		}
+		out.Edges = append(out.Edges, Edge{From: id(e[0]), To: id(e[1]), Path: path, MinLen: edge.MinLen, Weight: edge.Weight})
+	}
+
+	clusters := make([]layout.ClusterID, 0, len(g.Clusters))
+	for c := range g.Clusters {
+		clusters = append(clusters, c)
+	}
+	sort.Strings(clusters)
+	for _, c := range clusters {
+		cluster := g.Clusters[c]
+		members := append([]layout.NodeID(nil), cluster.Nodes...)
+		sort.Slice(members, func(i, j int) bool { return members[i] < members[j] })
+		var ids []string
+		for _, n := range members {
+			ids = append(ids, id(n))
+		}
+		out.Clusters = append(out.Clusters, Cluster{
+			ID:     c,
+			Parent: cluster.Parent,
+			X:      cluster.X,
+			Y:      cluster.Y,
+			W:      cluster.W,
+			H:      cluster.H,
+			Margin: cluster.Margin,
+			Nodes:  ids,
+		})
+	}
+
+	out.BBox = out.boundingBox()
+	return out
+}
+
+func (g *Graph) boundingBox() Box {
+	var minx, miny, maxx, maxy int
+	first := true
+	add := func(x0, y0, x1, y1 int) {
+		if first {
+			minx, miny, maxx, maxy = x0, y0, x1, y1
+			first = false
+			return
+		}
+		minx, miny = min(minx, x0), min(miny, y0)
+		maxx, maxy = max(maxx, x1), max(maxy, y1)
+	}
+	for _, n := range g.Nodes {
+		add(n.X, n.Y, n.X+n.W, n.Y+n.H)
+	}
+	for _, c := range g.Clusters {
+		add(c.X, c.Y, c.X+c.W, c.Y+c.H)
+	}
+	for _, e := range g.Edges {
+		for _, p := range e.Path {
+			add(p.X, p.Y, p.X, p.Y)
+		}
+	}
+	return Box{X: minx, Y: miny, W: maxx - minx, H: maxy - miny}
+}
+
+// Layout converts schema to layout graph, together with layout node IDs of node IDs.
+// Layout node IDs are assigned in order of nodes, starting from 1.
+func (g *Graph) Layout() (layout.Graph, map[string]layout.NodeID, error) {
+	lg := layout.Graph{
+		Nodes: make(map[layout.NodeID]layout.Node, len(g.Nodes)),
+		Edges: make(map[[2]layout.NodeID]layout.Edge, len(g.Edges)),
+	}
+	ids := make(map[string]layout.NodeID, len(g.Nodes))
+
+	for _, n := range g.Nodes {
+		if n.ID == "" {
+			return layout.Graph{}, nil, errors.New("layoutjson: node without id")
+		}
+		if _, ok := ids[n.ID]; ok {
+			return layout.Graph{}, nil, fmt.Errorf("layoutjson: duplicate node(%s)", n.ID)
+		}
+		if n.W < 0 || n.H < 0 {
+			return layout.Graph{}, nil, fmt.Errorf("layoutjson: node(%s) has negative size", n.ID)
+		}
+		id := layout.NodeID(len(ids) + 1)
+		ids[n.ID] = id
+		lg.Nodes[id] = layout.Node{Position: layout.Position{X: n.X, Y: n.Y}, W: n.W, H: n.H, Pinned: n.Pinned}
+	}
+
+	for _, e := range g.Edges {
+		from, ok := ids[e.From]
+		if !ok {
+			return layout.Graph{}, nil, fmt.Errorf("layoutjson: edge(%s,%s) from node not found", e.From, e.To)
+		}
+		to, ok := ids[e.To]
+		if !ok {
+			return layout.Graph{}, nil, fmt.Errorf("layoutjson: edge(%s,%s) to node not found", e.From, e.To)
+		}
+		if from == to {
+			return layout.Graph{}, nil, fmt.Errorf("layoutjson: edge(%s,%s) is self loop", e.From, e.To)
+		}
+		key := [2]layout.NodeID{from, to}
+		if _, ok := lg.Edges[key]; ok {
+			return layout.Graph{}, nil, fmt.Errorf("layoutjson: duplicate edge(%s,%s)", e.From, e.To)
+		}
+		var path []layout.Position
+		for _, p := range e.Path {
+			path = append(path, layout.Position{X: p.X, Y: p.Y})
+		}
+		lg.Edges[key] = layout.Edge{Path: path, MinLen: e.MinLen, Weight: e.Weight}
+	}
+
+	if len(g.Clusters) > 0 {
+		lg.Clusters = make(map[layout.ClusterID]layout.Cluster, len(g.Clusters))
+	}
+	for _, c := range g.Clusters {
+		if c.ID == "" {
+			return layout.Graph{}, nil, errors.New("layoutjson: cluster without id")
+		}
+		if _, ok := lg.Clusters[c.ID]; ok {
+			return layout.Graph{}, nil, fmt.Errorf("layoutjson: duplicate cluster(%s)", c.ID)
+		}
+		cluster := layout.Cluster{
+			Position: layout.Position{X: c.X, Y: c.Y},
+			W:        c.W,
+			H:        c.H,
+			Parent:   c.Parent,
+			Margin:   c.Margin,
+		}
+		for _, n := range c.Nodes {
+			id, ok := ids[n]
+			if !ok {
+				return layout.Graph{}, nil, fmt.Errorf("layoutjson: cluster(%s) node(%s) not found", c.ID, n)
+			}
+			cluster.Nodes = append(cluster.Nodes, id)
+		}
+		lg.Clusters[c.ID] = cluster
+	}
+	for _, c := range g.Clusters {
+		if _, ok := lg.Clusters[c.Parent]; c.Parent != "" && !ok {
+			return layout.Graph{}, nil, fmt.Errorf("layoutjson: cluster(%s) parent(%s) not found", c.ID, c.Parent)
+		}
+	}
+	if err := lg.ValidateClusters(); err != nil {
+		return layout.Graph{}, nil, fmt.Errorf("layoutjson: %w", err)
+	}
+
+	return lg, ids, nil
+}
+
+// Update sets boxes of nodes and clusters and paths of edges from layout graph, like graph from Layout with ids.
+// IDs, labels and order are kept, edge labels are moved to middle of their paths.
+func (g *Graph) Update(lg layout.Graph, ids map[string]layout.NodeID) {
+	for i, n := range g.Nodes {
+		node := lg.Nodes[ids[n.ID]]
+		g.Nodes[i].X, g.Nodes[i].Y, g.Nodes[i].W, g.Nodes[i].H = node.X, node.Y, node.W, node.H
+	}
+	for i, e := range g.Edges {
+		edge := lg.Edges[[2]layout.NodeID{ids[e.From], ids[e.To]}]
+		path := make([]Point, 0, len(edge.Path))
+		for _, p := range edge.Path {
+			path = append(path, Point{X: p.X, Y: p.Y})
+		}
+		g.Edges[i].Path = path
+		if e.Label != nil {
+			g.Edges[i].Label = NewLabel(e.Label.Text, path)
+		}
+	}
+	for i, c := range g.Clusters {
+		cluster := lg.Clusters[c.ID]
+		g.Clusters[i].X, g.Clusters[i].Y, g.Clusters[i].W, g.Clusters[i].H = cluster.X, cluster.Y, cluster.W, cluster.H
+	}
+	g.BBox = g.boundingBox()
+}
+
+// Marshal encodes graph as indented JSON.
+func Marshal(g *Graph) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g); err != nil {
+		return nil, fmt.Errorf("layoutjson: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// Unmarshal decodes graph from JSON and checks that it can be converted to layout graph.
+// Unknown fields are ignored.
+func Unmarshal(data []byte) (*Graph, error) {
+	var g Graph
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("layoutjson: %w", err)
+	}
+	if _, _, err := g.Layout(); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
@@ -0,0 +1,248 @@
+package layoutjson_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+	"github.com/gverger/go-graph-layout/layoutjson"
+)
+
+func TestMarshal(t *testing.T) {
+	two := 2
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{
+			1: {Position: layout.Position{X: 0, Y: 0}, W: 54, H: 36},
+			2: {Position: layout.Position{X: 0, Y: 100}, W: 54, H: 36, Pinned: true},
+		},
+		Edges: map[[2]uint64]layout.Edge{
+			{1, 2}: {Path: []layout.Position{{X: 27, Y: 18}, {X: 27, Y: 118}}, Weight: &two},
+		},
+		Clusters: map[string]layout.Cluster{
+			"a": {Position: layout.Position{X: -8, Y: 92}, W: 70, H: 52, Nodes: []uint64{2}, Margin: 8},
+		},
+	}
+
+	jg := layoutjson.FromLayout(g)
+	jg.Nodes[0].Label = "first"
+	jg.Edges[0].Label = &layoutjson.Label{Text: "next", X: 27, Y: 68}
+
+	data, err := layoutjson.Marshal(jg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "bbox": {
+    "x": -8,
+    "y": 0,
+    "w": 70,
+    "h": 144
+  },
+  "nodes": [
+    {
+      "id": "1",
+      "label": "first",
+      "x": 0,
+      "y": 0,
+      "w": 54,
+      "h": 36
+    },
+    {
+      "id": "2",
+      "x": 0,
+      "y": 100,
+      "w": 54,
+      "h": 36,
+      "pinned": true
+    }
+  ],
+  "edges": [
+    {
+      "from": "1",
+      "to": "2",
+      "path": [
+        {
+          "x": 27,
+          "y": 18
+        },
+        {
+          "x": 27,
+          "y": 118
+        }
+      ],
+      "label": {
+        "text": "next",
+        "x": 27,
+        "y": 68
+      },
+      "weight": 2
+    }
+  ],
+  "clusters": [
+    {
+      "id": "a",
+      "x": -8,
+      "y": 92,
+      "w": 70,
+      "h": 52,
+      "margin": 8,
+      "nodes": [
+        "2"
+      ]
+    }
+  ]
+}
+`
+	if string(data) != expected {
+		t.Errorf("wrong output:\n%s", data)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{
+			1: {W: 20, H: 10},
+			2: {W: 30, H: 10},
+			3: {W: 20, H: 15},
+			4: {W: 20, H: 10},
+		},
+		Edges: map[[2]uint64]layout.Edge{
+			{1, 2}: {},
+			{1, 3}: {MinLen: 2},
+			{2, 4}: {},
+			{1, 4}: {},
+		},
+		Clusters: map[string]layout.Cluster{
+			"outer": {Nodes: []uint64{2}, Margin: 5},
+			"inner": {Parent: "outer", Nodes: []uint64{4}, Margin: 5},
+		},
+	}
+	layout.SugiyamaLayersStrategyGraphLayout{
+		CycleRemover:   layout.NewSimpleCycleRemover(),
+		LevelsAssigner: layout.NewLayeredGraph,
+		OrderingAssigner: layout.WarfieldOrderingOptimizer{
+			Epochs:                   10,
+			LayerOrderingInitializer: layout.BFSOrderingInitializer{},
+			LayerOrderingOptimizer:   layout.WMedianOrderingOptimizer{},
+		}.Optimize,
+		NodesHorizontalCoordinatesAssigner: layout.BrandesKopfLayersNodesHorizontalAssigner{Delta: 25},
+		NodesVerticalCoordinatesAssigner:   layout.BasicNodesVerticalCoordinatesAssigner{MarginLayers: 25, FakeNodeHeight: 25},
+		EdgePathAssigner:                   layout.StraightEdgePathAssigner{}.UpdateGraphLayout,
+	}.UpdateGraphLayout(g)
+
+	out := layoutjson.FromLayout(g)
+	out.Edges[0].Label = layoutjson.NewLabel("first", out.Edges[0].Path)
+	data, err := layoutjson.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jg, err := layoutjson.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(jg.Edges[0].Label, out.Edges[0].Label) || jg.Edges[1].Label != nil {
+		t.Errorf("round trip changed labels: %+v, expected %+v", jg.Edges[0].Label, out.Edges[0].Label)
+	}
+	back, ids, err := jg.Layout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id, n := range ids {
+		if id != jg.Nodes[n-1].ID {
+			t.Errorf("node %s got id %d", id, n)
+		}
+	}
+	if !reflect.DeepEqual(g, back) {
+		t.Errorf("round trip changed graph:\n%+v\n%+v", g, back)
+	}
+
+	again := layoutjson.FromLayout(back)
+	again.Edges[0].Label = layoutjson.NewLabel("first", again.Edges[0].Path)
+	againData, err := layoutjson.Marshal(again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(againData) != string(data) {
+		t.Errorf("round trip changed JSON:\n%s\n%s", data, againData)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	g, err := layoutjson.Unmarshal([]byte(`{"nodes": [{"id": "b", "label": "B"}, {"id": "a"}],
+"edges": [{"from": "b", "to": "a", "label": {"text": "ba"}}], "clusters": [{"id": "c", "nodes": ["a"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg, ids, err := g.Layout()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg.Nodes[ids["b"]] = layout.Node{W: 10, H: 10}
+	lg.Nodes[ids["a"]] = layout.Node{Position: layout.Position{X: 0, Y: 50}, W: 10, H: 10}
+	lg.Edges[[2]uint64{ids["b"], ids["a"]}] = layout.Edge{Path: []layout.Position{{X: 5, Y: 5}, {X: 5, Y: 55}}}
+	lg.Clusters["c"] = layout.Cluster{Position: layout.Position{X: -5, Y: 45}, W: 20, H: 20, Nodes: lg.Clusters["c"].Nodes}
+
+	g.Update(lg, ids)
+	if g.Nodes[0].ID != "b" || g.Nodes[0].Label != "B" || g.Nodes[1].Y != 50 || g.Nodes[1].W != 10 {
+		t.Errorf("unexpected nodes: %+v", g.Nodes)
+	}
+	if l := g.Edges[0].Label; len(g.Edges[0].Path) != 2 || *l != (layoutjson.Label{Text: "ba", X: 5, Y: 30}) {
+		t.Errorf("unexpected edge: %+v %+v", g.Edges[0], l)
+	}
+	if c := g.Clusters[0]; c.X != -5 || c.H != 20 || !reflect.DeepEqual(c.Nodes, []string{"a"}) {
+		t.Errorf("unexpected cluster: %+v", c)
+	}
+	if g.BBox != (layoutjson.Box{X: -5, Y: 0, W: 20, H: 65}) {
+		t.Errorf("unexpected bbox: %+v", g.BBox)
+	}
+}
+
+func TestNewLabel(t *testing.T) {
+	tests := []struct {
+		path     []layoutjson.Point
+		expected layoutjson.Label
+	}{
+		{nil, layoutjson.Label{Text: "a"}},
+		{[]layoutjson.Point{{X: 5, Y: 5}}, layoutjson.Label{Text: "a", X: 5, Y: 5}},
+		{[]layoutjson.Point{{X: 0, Y: 0}, {X: 0, Y: 100}}, layoutjson.Label{Text: "a", X: 0, Y: 50}},
+		{[]layoutjson.Point{{X: 0, Y: 0}, {X: 0, Y: 20}, {X: 60, Y: 20}}, layoutjson.Label{Text: "a", X: 20, Y: 20}},
+	}
+	for _, tt := range tests {
+		if l := layoutjson.NewLabel("a", tt.path); *l != tt.expected {
+			t.Errorf("label of path %v is %+v, expected %+v", tt.path, *l, tt.expected)
+		}
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := map[string]string{
+		"not json":          `{"nodes": [`,
+		"node without id":   `{"nodes": [{"x": 1}]}`,
+		"duplicate node":    `{"nodes": [{"id": "a"}, {"id": "a"}]}`,
+		"negative size":     `{"nodes": [{"id": "a", "w": -1}]}`,
+		"unknown from":      `{"nodes": [{"id": "a"}], "edges": [{"from": "b", "to": "a"}]}`,
+		"unknown to":        `{"nodes": [{"id": "a"}], "edges": [{"from": "a", "to": "b"}]}`,
+		"self loop":         `{"nodes": [{"id": "a"}], "edges": [{"from": "a", "to": "a"}]}`,
+		"duplicate edge":    `{"nodes": [{"id": "a"}, {"id": "b"}], "edges": [{"from": "a", "to": "b"}, {"from": "a", "to": "b"}]}`,
+		"cluster no id":     `{"nodes": [{"id": "a"}], "clusters": [{"nodes": ["a"]}]}`,
+		"duplicate cluster": `{"clusters": [{"id": "c"}, {"id": "c"}]}`,
+		"cluster node":      `{"nodes": [{"id": "a"}], "clusters": [{"id": "c", "nodes": ["b"]}]}`,
+		"cluster parent":    `{"clusters": [{"id": "c", "parent": "d"}]}`,
+		"two clusters":      `{"nodes": [{"id": "a"}], "clusters": [{"id": "c", "nodes": ["a"]}, {"id": "d", "nodes": ["a"]}]}`,
+		"parent cycle":      `{"clusters": [{"id": "c", "parent": "d"}, {"id": "d", "parent": "c"}]}`,
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := layoutjson.Unmarshal([]byte(input))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.HasPrefix(err.Error(), "layoutjson: ") {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
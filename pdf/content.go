@@ -0,0 +1,202 @@
+package pdf
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+// content is content stream of graph, with coordinates of graph: y axis points down.
+type content struct {
+	b      strings.Builder
+	font   *textFont
+	alphas map[uint8]bool // opacities of graphics states used by content
+}
+
+func newContent() *content {
+	return &content{font: newTextFont(), alphas: make(map[uint8]bool)}
+}
+
+func (c *content) op(format string, args ...any) {
+	fmt.Fprintf(&c.b, format, args...)
+	c.b.WriteByte('\n')
+}
+
+// num is number with 2 decimals, it is enough for points of graphs.
+func num(v float64) string {
+	s := strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func colorNum(v uint8) string {
+	return strconv.FormatFloat(math.Round(float64(v)/255*1000)/1000, 'f', -1, 64)
+}
+
+// paint sets color and opacity of following fill or stroke, it is inside q and Q operators.
+func (c *content) paint(col color.NRGBA, op string) {
+	c.op("q")
+	if col.A < 0xff {
+		c.alphas[col.A] = true
+		c.op("/%s gs", alphaName(col.A))
+	}
+	c.op("%s %s %s %s", colorNum(col.R), colorNum(col.G), colorNum(col.B), op)
+}
+
+func alphaName(a uint8) string {
+	return fmt.Sprintf("GS%d", a)
+}
+
+func (c *content) path(points [][2]float64, closed bool) {
+	for i, p := range points {
+		if i == 0 {
+			c.op("%s %s m", num(p[0]), num(p[1]))
+		} else {
+			c.op("%s %s l", num(p[0]), num(p[1]))
+		}
+	}
+	if closed {
+		c.op("h")
+	}
+}
+
+func (c *content) fill(points [][2]float64, col color.NRGBA) {
+	if len(points) < 3 {
+		return
+	}
+	c.paint(col, "rg")
+	c.path(points, true)
+	c.op("f")
+	c.op("Q")
+}
+
+// stroke strokes path with round joins, path is written by draw.
+func (c *content) stroke(draw func(), width float64, dash []float64, col color.NRGBA) {
+	if width <= 0 {
+		return
+	}
+	c.paint(col, "RG")
+	c.op("%s w 1 j", num(width))
+	if len(dash) > 0 {
+		d := make([]string, 0, len(dash))
+		for _, v := range dash {
+			d = append(d, num(v))
+		}
+		c.op("[%s] 0 d", strings.Join(d, " "))
+	}
+	draw()
+	c.op("S")
+	c.op("Q")
+}
+
+// shape fills and strokes closed outline, lines are stroked like outline.
+func (c *content) shape(outline [][2]float64, lines [][][2]float64, p svg.Paint) {
+	if fill, ok := svg.ParseColor(p.Fill); ok {
+		c.fill(outline, fill)
+	}
+	stroke, ok := svg.ParseColor(p.Stroke)
+	if !ok || len(outline) == 0 {
+		return
+	}
+	c.stroke(func() { c.path(outline, true) }, p.StrokeWidth, p.Dash, stroke)
+	for _, l := range lines {
+		c.stroke(func() { c.path(l, false) }, p.StrokeWidth, p.Dash, stroke)
+	}
+}
+
+// edge strokes path of edge and draws its arrows. Curved edges are quadratic Bézier curves
+// with inner points as control points, that join at middles of segments.
+func (c *content) edge(path [][2]int, head, tail svg.Arrow, p svg.Paint, curved bool) {
+	stroke, ok := svg.ParseColor(p.Stroke)
+	if !ok || len(path) < 2 {
+		return
+	}
+	points := make([][2]float64, 0, len(path))
+	for _, pt := range path {
+		points = append(points, [2]float64{float64(pt[0]), float64(pt[1])})
+	}
+	c.stroke(func() {
+		if !curved || len(points) < 3 {
+			c.path(points, false)
+			return
+		}
+		start := points[0]
+		c.op("%s %s m", num(start[0]), num(start[1]))
+		for i := 1; i < len(points)-1; i++ {
+			ctrl, end := points[i], points[i+1]
+			if i < len(points)-2 {
+				end = [2]float64{(ctrl[0] + end[0]) / 2, (ctrl[1] + end[1]) / 2}
+			}
+			// quadratic curve as cubic curve
+			c1 := [2]float64{start[0] + 2*(ctrl[0]-start[0])/3, start[1] + 2*(ctrl[1]-start[1])/3}
+			c2 := [2]float64{end[0] + 2*(ctrl[0]-end[0])/3, end[1] + 2*(ctrl[1]-end[1])/3}
+			c.op("%s %s %s %s %s %s c", num(c1[0]), num(c1[1]), num(c2[0]), num(c2[1]), num(end[0]), num(end[1]))
+			start = end
+		}
+	}, p.StrokeWidth, p.Dash, stroke)
+
+	last := len(points) - 1
+	c.arrow(head, points[last], points[last-1], p.StrokeWidth, stroke)
+	c.arrow(tail, points[0], points[1], p.StrokeWidth, stroke)
+}
+
+func (c *content) arrow(a svg.Arrow, tip, from [2]float64, strokeWidth float64, col color.NRGBA) {
+	arrow, ok := svg.Arrowhead(a, tip, from, strokeWidth)
+	if !ok {
+		return
+	}
+	if arrow.Open {
+		c.stroke(func() { c.path(arrow.Points, false) }, arrow.Width, nil, col)
+		return
+	}
+	c.fill(arrow.Points, col)
+}
+
+// text shows line of text with embedded font. Text matrix flips y axis back, so glyphs are not upside down.
+func (c *content) text(line svg.TextLine, size int, fontColor string) {
+	col, ok := svg.ParseColor(fontColor)
+	if !ok || line.Text == "" {
+		return
+	}
+	glyphs, width := c.font.encode(line.Text)
+	x := float64(line.X)
+	switch line.Anchor {
+	case "middle":
+		x -= width * float64(size) / 2
+	case "end":
+		x -= width * float64(size)
+	}
+	c.paint(col, "rg")
+	c.op("BT /%s %d Tf 1 0 0 -1 %s %d Tm %s Tj ET", fontName, size, num(x), line.Y, glyphs)
+	c.op("Q")
+}
+
+// resources is resource dictionary of content.
+func (c *content) resources(font int) string {
+	var b strings.Builder
+	b.WriteString("<<")
+	if font > 0 {
+		fmt.Fprintf(&b, " /Font << /%s %d 0 R >>", fontName, font)
+	}
+	if len(c.alphas) > 0 {
+		alphas := make([]int, 0, len(c.alphas))
+		for a := range c.alphas {
+			alphas = append(alphas, int(a))
+		}
+		sort.Ints(alphas)
+		b.WriteString(" /ExtGState <<")
+		for _, a := range alphas {
+			fmt.Fprintf(&b, " /%s << /ca %s /CA %[2]s >>", alphaName(uint8(a)), colorNum(uint8(a)))
+		}
+		b.WriteString(" >>")
+	}
+	b.WriteString(" >>")
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// fontName is name of embedded font in resources of content.
+const fontName = "F1"
+
+var goRegular = sync.OnceValue(func() *sfnt.Font {
+	f, err := sfnt.Parse(goregular.TTF)
+	if err != nil {
+		panic(err)
+	}
+	return f
+})
+
+// textFont is Go Regular font embedded whole in PDF. Text is encoded by glyph indices,
+// so any glyph of font can be shown, and used glyphs are mapped back to text for search and copy.
+type textFont struct {
+	f    *sfnt.Font
+	buf  sfnt.Buffer
+	upem float64
+	used map[sfnt.GlyphIndex]rune
+}
+
+func newTextFont() *textFont {
+	f := goRegular()
+	return &textFont{f: f, upem: float64(f.UnitsPerEm()), used: make(map[sfnt.GlyphIndex]rune)}
+}
+
+// encode is hex string of glyphs of text and width of text in ems.
+func (f *textFont) encode(text string) (string, float64) {
+	var (
+		b     strings.Builder
+		width float64
+	)
+	b.WriteString("<")
+	for _, r := range text {
+		g, err := f.f.GlyphIndex(&f.buf, r)
+		if err != nil {
+			g = 0
+		}
+		if _, ok := f.used[g]; !ok {
+			f.used[g] = r
+		}
+		fmt.Fprintf(&b, "%04X", uint16(g))
+		width += f.advance(g)
+	}
+	b.WriteString(">")
+	return b.String(), width
+}
+
+// advance is advance of glyph in ems.
+func (f *textFont) advance(g sfnt.GlyphIndex) float64 {
+	adv, err := f.f.GlyphAdvance(&f.buf, g, fixed.I(int(f.upem)), font.HintingNone)
+	if err != nil {
+		return 0
+	}
+	return float64(adv) / 64 / f.upem
+}
+
+// units are font units in thousandths of em, like widths and metrics of PDF fonts.
+func (f *textFont) units(v fixed.Int26_6) int {
+	return int(float64(v) / 64 * 1000 / f.upem)
+}
+
+// write writes font objects and is reference to font.
+func (f *textFont) write(w *writer) int {
+	font0, cidFont, descriptor, file, toUnicode := w.alloc(), w.alloc(), w.alloc(), w.alloc(), w.alloc()
+
+	ppem := fixed.I(int(f.upem))
+	metrics, _ := f.f.Metrics(&f.buf, ppem, font.HintingNone)
+	bounds, _ := f.f.Bounds(&f.buf, ppem, font.HintingNone)
+
+	glyphs := f.glyphs()
+	var widths strings.Builder
+	for _, g := range glyphs {
+		fmt.Fprintf(&widths, "%d [%d] ", g, int(f.advance(g)*1000))
+	}
+
+	w.object(font0, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /GoRegular /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", cidFont, toUnicode))
+	w.object(cidFont, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /GoRegular /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>", descriptor, strings.TrimSpace(widths.String())))
+	w.object(descriptor, fmt.Sprintf("<< /Type /FontDescriptor /FontName /GoRegular /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.units(bounds.Min.X), -f.units(bounds.Max.Y), f.units(bounds.Max.X), -f.units(bounds.Min.Y),
+		f.units(metrics.Ascent), -f.units(metrics.Descent), f.units(metrics.CapHeight), file))
+	w.stream(file, fmt.Sprintf("/Length1 %d", len(goregular.TTF)), goregular.TTF)
+	w.stream(toUnicode, "", f.toUnicode(glyphs))
+	return font0
+}
+
+func (f *textFont) glyphs() []sfnt.GlyphIndex {
+	glyphs := make([]sfnt.GlyphIndex, 0, len(f.used))
+	for g := range f.used {
+		glyphs = append(glyphs, g)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+	return glyphs
+}
+
+// toUnicode is CMap from glyphs to text. Missing glyph is not mapped, as it is shown for many runes.
+func (f *textFont) toUnicode(glyphs []sfnt.GlyphIndex) []byte {
+	var b strings.Builder
+	b.WriteString(`/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def
+/CMapName /Adobe-Identity-UCS def
+/CMapType 2 def
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+`)
+	var mapped []sfnt.GlyphIndex
+	for _, g := range glyphs {
+		if g != 0 {
+			mapped = append(mapped, g)
+		}
+	}
+	// blocks of mappings have at most 100 entries
+	for len(mapped) > 0 {
+		block := mapped[:min(100, len(mapped))]
+		mapped = mapped[len(block):]
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(block))
+		for _, g := range block {
+			fmt.Fprintf(&b, "<%04X> <", uint16(g))
+			for _, u := range utf16.Encode([]rune{f.used[g]}) {
+				fmt.Fprintf(&b, "%04X", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString(`endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end
+`)
+	return []byte(b.String())
+}
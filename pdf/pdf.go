@@ -0,0 +1,177 @@
+// Package pdf writes laid out graphs to vector PDF documents in pure Go.
+//
+// Graphs are drawn like SVG of svg package: same themes, styles, node shapes and arrows.
+// Text is shown with Go Regular font, which is embedded in document, so it looks same in all viewers and can be searched.
+// Document has one page as large as graph, or large graphs are split into pages of fixed size.
+//
+//	err := pdf.Renderer{PageWidth: pdf.A4Width, PageHeight: pdf.A4Height}.Write(w, svg.FromLayout(g))
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gverger/go-graph-layout/layout"
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+// Sizes of common pages in points.
+const (
+	A4Width      = 595.28
+	A4Height     = 841.89
+	A3Width      = 841.89
+	A3Height     = 1190.55
+	LetterWidth  = 612
+	LetterHeight = 792
+)
+
+// PageSize parses size of pages: a4, a3, letter, or width and height in points like 595x842.
+// Empty size is 0, that is size of graph.
+func PageSize(s string) (width, height float64, err error) {
+	switch strings.ToLower(s) {
+	case "":
+		return 0, 0, nil
+	case "a4":
+		return A4Width, A4Height, nil
+	case "a3":
+		return A3Width, A3Height, nil
+	case "letter":
+		return LetterWidth, LetterHeight, nil
+	}
+	w, h, ok := strings.Cut(s, "x")
+	if ok {
+		width, err = strconv.ParseFloat(w, 64)
+		if err == nil {
+			height, err = strconv.ParseFloat(h, 64)
+		}
+	}
+	if !ok || err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid page size %q", s)
+	}
+	return width, height, nil
+}
+
+// DefaultScale is points per unit of layout, same as points per pixel in CSS, so PDF has size of SVG.
+const DefaultScale = 0.75
+
+// Renderer writes graphs to PDF documents.
+type Renderer struct {
+	Title      string     // title of document
+	Scale      float64    // points per unit of layout, DefaultScale when 0
+	Margin     int        // space around graph, in units of layout
+	Theme      *svg.Theme // svg.DefaultTheme when nil, pages are transparent when theme has no background
+	Curved     bool       // edges are smooth Bézier curves through points of their paths, instead of polylines
+	PageWidth  float64    // width of pages in points, 0 is width of graph
+	PageHeight float64    // height of pages in points, 0 is height of graph
+}
+
+// Write writes graph as PDF document. When graph is larger than page, it is split into tiles,
+// pages are ordered by rows from top left corner of graph.
+// Graph without theme is sized with theme of renderer.
+func (r Renderer) Write(w io.Writer, g svg.Graph) error {
+	theme := svg.DefaultTheme
+	if r.Theme != nil {
+		theme = *r.Theme
+	}
+	scale := r.Scale
+	if scale <= 0 {
+		scale = DefaultScale
+	}
+
+	if g.Theme == nil {
+		g.Theme = &theme
+	}
+	x, y, gw, gh := g.Bounds()
+	x, y, gw, gh = x-r.Margin, y-r.Margin, gw+2*r.Margin, gh+2*r.Margin
+	c := draw(g, theme, r.Curved, [4]float64{float64(x), float64(y), float64(x + gw), float64(y + gh)})
+
+	width, height := float64(gw)*scale, float64(gh)*scale
+	pageWidth, pageHeight := r.PageWidth, r.PageHeight
+	if pageWidth <= 0 {
+		pageWidth = width
+	}
+	if pageHeight <= 0 {
+		pageHeight = height
+	}
+	cols := max(1, int(math.Ceil(width/pageWidth-1e-9)))
+	rows := max(1, int(math.Ceil(height/pageHeight-1e-9)))
+
+	pw := newWriter(w)
+	catalog, pages, info, graph := pw.alloc(), pw.alloc(), pw.alloc(), pw.alloc()
+	font := 0
+	if len(c.font.used) > 0 {
+		font = c.font.write(pw)
+	}
+
+	// graph is drawn once in form that all pages show, form flips y axis of graph that points down
+	pw.stream(graph, fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [%d %d %d %d] /Matrix [%s 0 0 %s %s %s] /Resources %s",
+		x, y, x+gw, y+gh, num(scale), num(-scale), num(-float64(x)*scale), num(float64(y+gh)*scale), c.resources(font)), []byte(c.b.String()))
+
+	kids := ""
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			page, contents := pw.alloc(), pw.alloc()
+			kids += fmt.Sprintf(" %d 0 R", page)
+			// top of first row is top of graph
+			tx, ty := -float64(col)*pageWidth, pageHeight-height+float64(row)*pageHeight
+			pw.stream(contents, "", []byte(fmt.Sprintf("q 1 0 0 1 %s %s cm /Graph Do Q\n", num(tx), num(ty))))
+			pw.object(page, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /XObject << /Graph %d 0 R >> >> /Contents %d 0 R >>",
+				pages, num(pageWidth), num(pageHeight), graph, contents))
+		}
+	}
+	pw.object(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s ] /Count %d >>", kids, rows*cols))
+	pw.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	pw.object(info, fmt.Sprintf("<< /Title %s /Producer (go-graph-layout) >>", textString(r.Title)))
+	return pw.finish(catalog, info)
+}
+
+// WriteLayout writes layout with nodes titled by their IDs.
+func (r Renderer) WriteLayout(w io.Writer, g layout.Graph) error {
+	return r.Write(w, svg.FromLayout(g))
+}
+
+// draw is content of graph in same order as in SVG: clusters below edges and nodes, nodes on top.
+// Background fills bounds, that are left, top, right and bottom sides.
+func draw(g svg.Graph, theme svg.Theme, curved bool, bounds [4]float64) *content {
+	c := newContent()
+	if bg, ok := svg.ParseColor(theme.Background); ok {
+		c.fill([][2]float64{{bounds[0], bounds[1]}, {bounds[2], bounds[1]}, {bounds[2], bounds[3]}, {bounds[0], bounds[3]}}, bg)
+	}
+
+	g.Walk(drawer{c: c, theme: theme, curved: curved})
+	return c
+}
+
+// drawer draws parts of graph to content.
+type drawer struct {
+	c      *content
+	theme  svg.Theme
+	curved bool
+}
+
+func (d drawer) VisitCluster(cl svg.Cluster) {
+	p := d.theme.ClusterPaint(cl.Style)
+	d.c.shape(cl.Outline(), nil, p)
+	d.c.text(cl.TitleLine(), p.FontSize, p.FontColor)
+}
+
+func (d drawer) VisitEdge(e svg.Edge) {
+	d.c.edge(e.Path, e.Head, e.Tail, d.theme.EdgePaint(e.Style), d.curved)
+}
+
+func (d drawer) VisitNode(n svg.Node) {
+	p := d.theme.NodePaint(n.Style)
+	geom := n.Geometry()
+	d.c.shape(geom.Outline, geom.Lines, p)
+	if sep, ok := svg.ParseColor(d.theme.DataSeparator); ok {
+		for _, s := range geom.Separators {
+			d.c.stroke(func() { d.c.path([][2]float64{s[0], s[1]}, false) }, 1, nil, sep)
+		}
+	}
+	for _, line := range geom.Text {
+		d.c.text(line, geom.FontSize, p.FontColor)
+	}
+}
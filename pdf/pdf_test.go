@@ -0,0 +1,265 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+func testGraph() svg.Graph {
+	return svg.Graph{
+		Nodes: map[uint64]svg.Node{
+			1: {ID: "1", X: 0, Y: 0, Title: "table", NodeData: map[string]any{"a": 1}},
+			2: {ID: "2", X: 100, Y: 200, W: 40, H: 20, Title: "ellipse", Shape: svg.ShapeEllipse, Style: svg.Style{Fill: "#ff000080"}},
+		},
+		Edges: map[[2]uint64]svg.Edge{
+			{1, 2}: {Path: [][2]int{{20, 20}, {40, 100}, {120, 210}}, Head: svg.ArrowNormal, Style: svg.Style{Dash: "5 3"}},
+		},
+		Clusters: map[string]svg.Cluster{"c": {ID: "c", X: -10, Y: -10, W: 100, H: 100, Title: "cluster"}},
+	}
+}
+
+// document is PDF parsed enough to check its structure: objects are found with cross-reference table
+// and streams are decompressed.
+type document struct {
+	objects map[int]string
+	streams map[int]string
+	trailer string
+}
+
+func parse(t *testing.T, data []byte) document {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.7\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("invalid header or end of file")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref does not point to xref")
+	}
+	lines := strings.Split(string(data[xref:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+
+	doc := document{objects: map[int]string{}, streams: map[int]string{}, trailer: strings.Join(lines[count+2:], "\n")}
+	objectRe := regexp.MustCompile(`(?s)^(\d+) 0 obj\n(.*?)\n(stream\n|endobj\n)`)
+	for id := 1; id < count; id++ {
+		entry := lines[id+2] + "\n"
+		if len(entry) != 20 {
+			t.Fatalf("xref entry %q is not 20 bytes", entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		m := objectRe.FindSubmatch(data[offset:])
+		if m == nil || string(m[1]) != strconv.Itoa(id) {
+			t.Fatalf("xref of object %d does not point to it", id)
+		}
+		doc.objects[id] = string(m[2])
+		if string(m[3]) == "stream\n" {
+			length, _ := strconv.Atoi(regexp.MustCompile(`/Length (\d+)`).FindStringSubmatch(string(m[2]))[1])
+			start := offset + len(m[0])
+			z, err := zlib.NewReader(bytes.NewReader(data[start : start+length]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := io.ReadAll(z)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(data[start+length:], []byte("\nendstream\nendobj\n")) {
+				t.Fatalf("length of stream %d is wrong", id)
+			}
+			doc.streams[id] = string(s)
+		}
+	}
+	return doc
+}
+
+// find is ID of object that matches pattern.
+func (d document) find(t *testing.T, pattern string) []int {
+	t.Helper()
+	var ids []int
+	for id := 1; id <= len(d.objects); id++ {
+		if strings.Contains(d.objects[id], pattern) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func render(t *testing.T, r Renderer, g svg.Graph) document {
+	t.Helper()
+	var b bytes.Buffer
+	if err := r.Write(&b, g); err != nil {
+		t.Fatal(err)
+	}
+	return parse(t, b.Bytes())
+}
+
+func TestWrite(t *testing.T) {
+	doc := render(t, Renderer{Title: "Graph (1)", Margin: 10}, testGraph())
+
+	if !strings.Contains(doc.trailer, "/Root 1 0 R") || !strings.Contains(doc.objects[1], "/Type /Catalog") {
+		t.Errorf("unexpected trailer %q", doc.trailer)
+	}
+	if info := doc.objects[3]; !strings.Contains(info, `/Title (Graph \(1\))`) {
+		t.Errorf("unexpected info %q", info)
+	}
+
+	// bounds are (-10, -10) to (140, 220) with margin
+	pages := doc.find(t, "/Type /Page ")
+	if len(pages) != 1 || !strings.Contains(doc.objects[pages[0]], "/MediaBox [0 0 127.5 187.5]") {
+		t.Fatalf("expected one page of size of graph, got %v", pages)
+	}
+	forms := doc.find(t, "/Subtype /Form")
+	if len(forms) != 1 || !strings.Contains(doc.objects[forms[0]], "/BBox [-20 -20 150 230] /Matrix [0.75 0 0 -0.75 15 172.5]") {
+		t.Fatalf("unexpected form %v", doc.objects[forms[0]])
+	}
+
+	content := doc.streams[forms[0]]
+	for _, op := range []string{
+		"[5 3] 0 d\n27 46 m\n40 100 l\n113 201 l\nS", // dashed edge clipped to nodes
+		"1 0 0 rg",                  // fill of ellipse
+		"/GS128 gs",                 // with opacity
+		"/F1 9 Tf 1 0 0 -1",         // text
+		"0.827 0.827 0.827 RG\n1 w", // separator of table
+	} {
+		if !strings.Contains(content, op) {
+			t.Errorf("missing %q in content:\n%s", op, content)
+		}
+	}
+	if !strings.Contains(doc.objects[forms[0]], "/ExtGState << /GS128 << /ca 0.502 /CA 0.502 >> >>") {
+		t.Errorf("missing graphics state of opacity: %s", doc.objects[forms[0]])
+	}
+
+	// text is searchable: glyphs of text are mapped to runes
+	fonts := doc.find(t, "/Subtype /Type0")
+	if len(fonts) != 1 {
+		t.Fatalf("expected font")
+	}
+	toUnicode, _ := strconv.Atoi(regexp.MustCompile(`/ToUnicode (\d+) 0 R`).FindStringSubmatch(doc.objects[fonts[0]])[1])
+	if !strings.Contains(doc.streams[toUnicode], "<0057> <0074>") {
+		t.Errorf("missing mapping of t:\n%s", doc.streams[toUnicode])
+	}
+	if files := doc.find(t, "/Length1 "); len(files) != 1 {
+		t.Errorf("expected embedded font file")
+	}
+}
+
+func TestWriteCurved(t *testing.T) {
+	doc := render(t, Renderer{Curved: true}, testGraph())
+	content := doc.streams[doc.find(t, "/Subtype /Form")[0]]
+	// quadratic curve from (27, 46) with control point (40, 100) to (113, 201)
+	if !strings.Contains(content, "27 46 m\n35.67 82 64.33 133.67 113 201 c\nS") {
+		t.Errorf("missing curve in content:\n%s", content)
+	}
+}
+
+func TestWritePages(t *testing.T) {
+	// graph is 170 by 250 units, 127.5 by 187.5 points
+	tests := []struct {
+		name     string
+		r        Renderer
+		pages    int
+		mediaBox string
+		moves    []string
+	}{
+		{"one page", Renderer{Margin: 10, PageWidth: 200, PageHeight: 200}, 1, "[0 0 200 200]", []string{"0 12.5"}},
+		{"columns", Renderer{Margin: 10, PageWidth: 50}, 3, "[0 0 50 187.5]", []string{"0 0", "-50 0", "-100 0"}},
+		{"rows", Renderer{Margin: 10, PageHeight: 100}, 2, "[0 0 127.5 100]", []string{"0 -87.5", "0 12.5"}},
+		{"scale", Renderer{Margin: 10, Scale: 1.5, PageWidth: 255, PageHeight: 375}, 1, "[0 0 255 375]", []string{"0 0"}},
+		{"tiles", Renderer{Margin: 10, PageWidth: 100, PageHeight: 100}, 4, "[0 0 100 100]", []string{"0 -87.5", "-100 -87.5", "0 12.5", "-100 12.5"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := render(t, tc.r, testGraph())
+			pages := doc.find(t, "/Type /Page ")
+			if len(pages) != tc.pages {
+				t.Fatalf("expected %d pages, got %d", tc.pages, len(pages))
+			}
+			if !strings.Contains(doc.objects[doc.find(t, "/Type /Pages")[0]], fmt.Sprintf("/Count %d", tc.pages)) {
+				t.Errorf("unexpected count of pages")
+			}
+			for i, page := range pages {
+				if !strings.Contains(doc.objects[page], "/MediaBox "+tc.mediaBox) {
+					t.Errorf("unexpected page %s", doc.objects[page])
+				}
+				contents, _ := strconv.Atoi(regexp.MustCompile(`/Contents (\d+) 0 R`).FindStringSubmatch(doc.objects[page])[1])
+				if expected := "q 1 0 0 1 " + tc.moves[i] + " cm /Graph Do Q\n"; doc.streams[contents] != expected {
+					t.Errorf("page %d: expected %q, got %q", i, expected, doc.streams[contents])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteLayout(t *testing.T) {
+	var b bytes.Buffer
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{1: {W: 30, H: 20}, 2: {Position: layout.Position{X: 0, Y: 60}, W: 30, H: 20}},
+		Edges: map[[2]uint64]layout.Edge{{1, 2}: {Path: []layout.Position{{X: 15, Y: 10}, {X: 15, Y: 70}}}},
+	}
+	if err := (Renderer{}).WriteLayout(&b, g); err != nil {
+		t.Fatal(err)
+	}
+	doc := parse(t, b.Bytes())
+	if pages := doc.find(t, "/MediaBox [0 0 22.5 60]"); len(pages) != 1 {
+		t.Errorf("expected page of size of layout")
+	}
+}
+
+func TestWriteWithoutText(t *testing.T) {
+	g := svg.Graph{Nodes: map[uint64]svg.Node{1: {ID: "1", W: 10, H: 10, Shape: svg.ShapeRect}}}
+	doc := render(t, Renderer{}, g)
+	if fonts := doc.find(t, "/Type /Font"); len(fonts) != 0 {
+		t.Errorf("font should be embedded only for text")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("failed") }
+
+func TestWriteError(t *testing.T) {
+	if err := (Renderer{}).Write(failingWriter{}, testGraph()); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	tests := map[string][2]float64{"": {0, 0}, "a4": {A4Width, A4Height}, "Letter": {LetterWidth, LetterHeight}, "842x595.5": {842, 595.5}}
+	for s, expected := range tests {
+		w, h, err := PageSize(s)
+		if err != nil || w != expected[0] || h != expected[1] {
+			t.Errorf("%q: expected %v, got %v %v %v", s, expected, w, h, err)
+		}
+	}
+	for _, s := range []string{"a5", "100", "100x", "x100", "0x100", "-1x100"} {
+		if _, _, err := PageSize(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestTextString(t *testing.T) {
+	for s, expected := range map[string]string{
+		"":           "()",
+		`a (b) \ c`:  `(a \(b\) \\ c)`,
+		"é":          "<FEFF00E9>",
+		"\U0001F600": "<FEFFD83DDE00>",
+	} {
+		if got := textString(s); got != expected {
+			t.Errorf("%q: expected %s, got %s", s, expected, got)
+		}
+	}
+}
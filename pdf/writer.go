@@ -0,0 +1,110 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// writer writes objects of PDF file and cross-reference table that points to them.
+// First error stops writing, it is returned by finish.
+type writer struct {
+	w       *bufio.Writer
+	n       int64
+	err     error
+	offsets []int64 // offsets of objects by number minus one
+}
+
+func newWriter(w io.Writer) *writer {
+	pw := &writer{w: bufio.NewWriter(w)}
+	// binary comment tells tools that file is binary
+	pw.printf("%%PDF-1.7\n%%\xe2\xe3\xcf\xd3\n")
+	return pw
+}
+
+func (w *writer) printf(format string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(w.w, format, args...)
+	w.n += int64(n)
+	w.err = err
+}
+
+func (w *writer) write(data []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(data)
+	w.n += int64(n)
+	w.err = err
+}
+
+// alloc reserves number of object, so objects can reference objects that are written later.
+func (w *writer) alloc() int {
+	w.offsets = append(w.offsets, -1)
+	return len(w.offsets)
+}
+
+func (w *writer) object(id int, dict string) {
+	w.offsets[id-1] = w.n
+	w.printf("%d 0 obj\n%s\nendobj\n", id, dict)
+}
+
+// stream writes stream object with data compressed, dict has entries of stream dictionary without length and filter.
+func (w *writer) stream(id int, dict string, data []byte) {
+	var b bytes.Buffer
+	z := zlib.NewWriter(&b)
+	z.Write(data)
+	z.Close()
+
+	w.offsets[id-1] = w.n
+	if dict != "" {
+		dict += " "
+	}
+	w.printf("%d 0 obj\n<< %s/Filter /FlateDecode /Length %d >>\nstream\n", id, dict, b.Len())
+	w.write(b.Bytes())
+	w.printf("\nendstream\nendobj\n")
+}
+
+// finish writes cross-reference table and trailer.
+func (w *writer) finish(root, info int) error {
+	xref := w.n
+	w.printf("xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for id, offset := range w.offsets {
+		if offset < 0 && w.err == nil {
+			w.err = fmt.Errorf("object %d is not written", id+1)
+		}
+		w.printf("%010d 00000 n \n", offset)
+	}
+	w.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, root, info, xref)
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// textString is PDF string of text outside of content streams, like title in document information.
+// ASCII is written as literal string, other text as UTF-16 with byte order mark.
+func textString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r >= 0x80 || r < 0x20 {
+			ascii = false
+		}
+	}
+	if ascii {
+		return "(" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s) + ")"
+	}
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
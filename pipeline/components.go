@@ -0,0 +1,151 @@
+package pipeline
+
+import (
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+// DefaultRegistry has components of layout package.
+//
+//	layout:               layers, forces, eades, isomap, sequence, direct_edges, scaler, clusters_bounding_box
+//	cycle_remover:        simple
+//	levels_assigner:      longest_path, weighted
+//	ordering_assigner:    warfield
+//	ordering_initializer: bfs, random
+//	ordering_optimizer:   wmedian, switch_adjacent, random, composite
+//	horizontal_assigner:  brandes_kopf
+//	vertical_assigner:    basic
+//	edge_path_assigner:   straight
+//	force:                gravity, spring
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.Register(KindLayout, "layers", func(p *Params) any {
+		return layout.SugiyamaLayersStrategyGraphLayout{
+			CycleRemover:                       Component[layout.CycleRemover](p, KindCycleRemover, "cycle_remover", "simple"),
+			LevelsAssigner:                     Component[func(layout.Graph) layout.LayeredGraph](p, KindLevelsAssigner, "levels_assigner", "longest_path"),
+			OrderingAssigner:                   Component[func(layout.Graph, layout.LayeredGraph)](p, KindOrderingAssigner, "ordering_assigner", "warfield"),
+			NodesHorizontalCoordinatesAssigner: Component[layout.NodesHorizontalCoordinatesAssigner](p, KindHorizontalAssigner, "horizontal_assigner", "brandes_kopf"),
+			NodesVerticalCoordinatesAssigner:   Component[layout.NodesVerticalCoordinatesAssigner](p, KindVerticalAssigner, "vertical_assigner", "basic"),
+			EdgePathAssigner:                   Component[func(layout.Graph, layout.LayeredGraph, map[uint64]layout.Position)](p, KindEdgePathAssigner, "edge_path_assigner", "straight"),
+		}
+	})
+	// layouts that only place nodes draw edges as straight lines, unless direct_edges is false
+	withEdges := func(p *Params, l layout.Layout) layout.Layout {
+		if !p.Bool("direct_edges", true) {
+			return l
+		}
+		return layout.SequenceLayout{Layouts: []layout.Layout{l, layout.DirectEdgesLayout{}}}
+	}
+	r.Register(KindLayout, "forces", func(p *Params) any {
+		return withEdges(p, layout.ForceGraphLayout{
+			Delta:    p.Float("delta", 1),
+			MaxSteps: p.Iterations("max_steps", 5000),
+			Epsilon:  p.Float("epsilon", 1.5),
+			Forces: Components[layout.Force](p, KindForce, "forces", []any{
+				map[string]any{"type": "gravity", "k": -50.0},
+				map[string]any{"type": "spring", "k": 0.2, "l": 200.0, "edges_only": true},
+			}),
+		})
+	})
+	r.Register(KindLayout, "eades", func(p *Params) any {
+		return withEdges(p, layout.EadesGonumLayout{
+			Repulsion: p.Float("repulsion", 1),
+			Rate:      p.Float("rate", 0.05),
+			Updates:   p.Iterations("updates", 30),
+			Theta:     p.Float("theta", 0.2),
+			ScaleX:    p.Float("scale_x", 0.5),
+			ScaleY:    p.Float("scale_y", 0.5),
+		})
+	})
+	r.Register(KindLayout, "isomap", func(p *Params) any {
+		return withEdges(p, layout.IsomapR2GonumLayout{
+			ScaleX: p.Float("scale_x", 0.5),
+			ScaleY: p.Float("scale_y", 0.5),
+		})
+	})
+	r.Register(KindLayout, "sequence", func(p *Params) any {
+		return layout.SequenceLayout{Layouts: Components[layout.Layout](p, KindLayout, "layouts", nil)}
+	})
+	r.Register(KindLayout, "direct_edges", func(p *Params) any {
+		return layout.DirectEdgesLayout{}
+	})
+	r.Register(KindLayout, "scaler", func(p *Params) any {
+		return &layout.ScalerLayout{Scale: p.Float("scale", 1)}
+	})
+	r.Register(KindLayout, "clusters_bounding_box", func(p *Params) any {
+		return layout.ClustersBoundingBoxLayout{}
+	})
+
+	r.Register(KindCycleRemover, "simple", func(p *Params) any {
+		return layout.NewSimpleCycleRemover()
+	})
+
+	r.Register(KindLevelsAssigner, "longest_path", func(p *Params) any {
+		return layout.NewLayeredGraph
+	})
+	r.Register(KindLevelsAssigner, "weighted", func(p *Params) any {
+		return layout.WeightedLevelsAssigner{Iterations: p.Iterations("iterations", 10)}.NewLayeredGraph
+	})
+
+	r.Register(KindOrderingAssigner, "warfield", func(p *Params) any {
+		return layout.WarfieldOrderingOptimizer{
+			Epochs:                   p.Iterations("epochs", 100),
+			LayerOrderingInitializer: Component[layout.LayerOrderingInitializer](p, KindOrderingInitializer, "initializer", "bfs"),
+			LayerOrderingOptimizer: Component[layout.LayerOrderingOptimizer](p, KindOrderingOptimizer, "optimizer", map[string]any{
+				"type":       "composite",
+				"optimizers": []any{"wmedian", "switch_adjacent"},
+			}),
+		}.Optimize
+	})
+
+	r.Register(KindOrderingInitializer, "bfs", func(p *Params) any {
+		return layout.BFSOrderingInitializer{}
+	})
+	r.Register(KindOrderingInitializer, "random", func(p *Params) any {
+		return layout.RandomLayerOrderingInitializer{}
+	})
+
+	r.Register(KindOrderingOptimizer, "wmedian", func(p *Params) any {
+		return layout.WMedianOrderingOptimizer{}
+	})
+	r.Register(KindOrderingOptimizer, "switch_adjacent", func(p *Params) any {
+		return layout.SwitchAdjacentOrderingOptimizer{}
+	})
+	r.Register(KindOrderingOptimizer, "random", func(p *Params) any {
+		return layout.RandomLayerOrderingOptimizer{Epochs: p.Iterations("epochs", 10)}
+	})
+	r.Register(KindOrderingOptimizer, "composite", func(p *Params) any {
+		return layout.CompositeLayerOrderingOptimizer{
+			Optimizers: Components[layout.LayerOrderingOptimizer](p, KindOrderingOptimizer, "optimizers", nil),
+		}
+	})
+
+	r.Register(KindHorizontalAssigner, "brandes_kopf", func(p *Params) any {
+		return layout.BrandesKopfLayersNodesHorizontalAssigner{
+			Delta:       p.NonNegativeInt("delta", 25),
+			TopDownOnly: p.Bool("top_down_only", false),
+		}
+	})
+
+	r.Register(KindVerticalAssigner, "basic", func(p *Params) any {
+		return layout.BasicNodesVerticalCoordinatesAssigner{
+			MarginLayers:   p.NonNegativeInt("margin_layers", 25),
+			FakeNodeHeight: p.NonNegativeInt("fake_node_height", 25),
+		}
+	})
+
+	r.Register(KindEdgePathAssigner, "straight", func(p *Params) any {
+		return layout.StraightEdgePathAssigner{}.UpdateGraphLayout
+	})
+
+	r.Register(KindForce, "gravity", func(p *Params) any {
+		return layout.GravityForce{K: p.Float("k", -50), EdgesOnly: p.Bool("edges_only", false)}
+	})
+	r.Register(KindForce, "spring", func(p *Params) any {
+		return layout.SpringForce{K: p.Float("k", 0.2), L: p.Float("l", 200), EdgesOnly: p.Bool("edges_only", true)}
+	})
+
+	return r
+}
@@ -0,0 +1,89 @@
+// Package pipeline builds layouts from declarative JSON or YAML configs.
+//
+// Config is tree of components, each component has name in "type" and its parameters.
+// Nested components are parameters too, they can be given by name only when defaults are fine.
+// Missing parameters take defaults.
+//
+//	type: layers
+//	ordering_assigner:
+//	  type: warfield
+//	  epochs: 50
+//	  optimizer:
+//	    type: composite
+//	    optimizers: [wmedian, switch_adjacent]
+//	horizontal_assigner: {type: brandes_kopf, delta: 40}
+//
+// Components are looked up in Registry by kind and name, DefaultRegistry has all components of layout package.
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+// Config is config of layout component.
+type Config map[string]any
+
+// ParseJSON reads config from JSON object.
+func ParseJSON(data []byte) (Config, error) {
+	var c Config
+	d := json.NewDecoder(bytes.NewReader(data))
+	if err := d.Decode(&c); err != nil {
+		return nil, fmt.Errorf("pipeline: %w", err)
+	}
+	if c == nil {
+		return nil, errors.New("pipeline: config is not an object")
+	}
+	return c, nil
+}
+
+// ParseYAML reads config from YAML mapping.
+func ParseYAML(data []byte) (Config, error) {
+	var c Config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("pipeline: %w", err)
+	}
+	if c == nil {
+		return nil, errors.New("pipeline: config is not a mapping")
+	}
+	return c, nil
+}
+
+// Build makes layout from config with components of default registry.
+func Build(c Config) (layout.Layout, error) {
+	return DefaultRegistry.Build(c)
+}
+
+// Build makes layout from config.
+// All problems of config, like unknown components or parameters, or parameters of wrong types, are reported together.
+// Layouts can keep state while running, so layout should be built for each run when layouts run concurrently.
+func (r *Registry) Build(c Config) (layout.Layout, error) {
+	return r.BuildLimited(c, Limits{})
+}
+
+// Limits of configs from untrusted sources, so that they can not make layouts run for long. 0 is no limit.
+// Work of config counts iterations, epochs and steps of all its components,
+// iterations of nested components are multiplied by iterations of components they are in.
+type Limits struct {
+	Iterations int // maximum iterations of each component
+	Work       int // maximum work of config
+}
+
+// BuildLimited is Build that rejects configs over limits.
+func (r *Registry) BuildLimited(c Config, limits Limits) (layout.Layout, error) {
+	var errs []error
+	l, work := build[layout.Layout](r, KindLayout, "", map[string]any(c), &errs, limits)
+	if limits.Work > 0 && work > limits.Work {
+		errs = append(errs, Error{Msg: fmt.Sprintf("work of config is %d iterations, maximum is %d", work, limits.Work)})
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
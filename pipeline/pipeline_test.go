@@ -0,0 +1,223 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+func testGraph() layout.Graph {
+	return layout.Graph{
+		Nodes: map[uint64]layout.Node{
+			1: {W: 20, H: 10},
+			2: {W: 20, H: 10},
+			3: {W: 20, H: 10},
+			4: {W: 20, H: 10},
+			5: {W: 20, H: 10},
+		},
+		Edges: map[[2]uint64]layout.Edge{
+			{1, 2}: {},
+			{1, 3}: {},
+			{2, 4}: {},
+			{3, 4}: {},
+			{5, 1}: {},
+			{5, 4}: {},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	configs := map[string]func() (Config, error){
+		"json": func() (Config, error) {
+			return ParseJSON([]byte(`{
+				"type": "layers",
+				"levels_assigner": {"type": "weighted", "iterations": 5},
+				"ordering_assigner": {
+					"type": "warfield",
+					"epochs": 10,
+					"initializer": "random",
+					"optimizer": {"type": "composite", "optimizers": ["wmedian", {"type": "random", "epochs": 3}]}
+				},
+				"horizontal_assigner": {"type": "brandes_kopf", "delta": 40, "top_down_only": true}
+			}`))
+		},
+		"yaml": func() (Config, error) {
+			return ParseYAML([]byte(`
+type: sequence
+layouts:
+  - type: forces
+    max_steps: 10
+    forces:
+      - {type: gravity, k: -20}
+      - {type: spring, l: 100}
+  - {type: scaler, scale: 2}
+`))
+		},
+		"defaults": func() (Config, error) {
+			return ParseYAML([]byte(`type: layers`))
+		},
+		"eades": func() (Config, error) {
+			return ParseJSON([]byte(`{"type": "eades", "updates": 5, "direct_edges": true}`))
+		},
+	}
+
+	for name, parse := range configs {
+		t.Run(name, func(t *testing.T) {
+			c, err := parse()
+			if err != nil {
+				t.Fatal(err)
+			}
+			l, err := Build(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			g := testGraph()
+			l.UpdateGraphLayout(g)
+			for e, edge := range g.Edges {
+				if len(edge.Path) < 2 {
+					t.Errorf("edge %v has no path: %v", e, edge.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildParams(t *testing.T) {
+	c, err := ParseYAML([]byte(`
+type: layers
+horizontal_assigner: {type: brandes_kopf, delta: 40}
+vertical_assigner: {type: basic, margin_layers: 7}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := Build(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := l.(layout.SugiyamaLayersStrategyGraphLayout)
+	if h := s.NodesHorizontalCoordinatesAssigner.(layout.BrandesKopfLayersNodesHorizontalAssigner); h.Delta != 40 {
+		t.Errorf("expected delta 40, got %d", h.Delta)
+	}
+	v := s.NodesVerticalCoordinatesAssigner.(layout.BasicNodesVerticalCoordinatesAssigner)
+	if v.MarginLayers != 7 || v.FakeNodeHeight != 25 {
+		t.Errorf("expected margin 7 and default fake node height 25, got %+v", v)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		config string
+		errs   []string
+	}{
+		{`{}`, []string{"pipeline: missing type of layout"}},
+		{`{"type": "circle"}`, []string{`pipeline: unknown layout "circle", expected one of: clusters_bounding_box, direct_edges, eades, forces, isomap, layers, scaler, sequence`}},
+		{`{"type": "layers", "cycles": "simple"}`, []string{`pipeline: cycles: unknown parameter of layout "layers"`}},
+		{`{"type": "layers", "ordering_assigner": {"type": "warfield", "epochs": -1}}`, []string{"pipeline: ordering_assigner.epochs: expected non negative integer, got -1"}},
+		{`{"type": "layers", "horizontal_assigner": {"type": "brandes_kopf", "delta": "wide"}}`, []string{"pipeline: horizontal_assigner.delta: expected integer, got wide"}},
+		{`{"type": "forces", "forces": [{"type": "gravity"}, {"type": "magnet"}], "epsilon": true}`, []string{
+			"pipeline: epsilon: expected number, got true",
+			`pipeline: forces[1]: unknown force "magnet", expected one of: gravity, spring`,
+		}},
+		{`{"type": "layers", "ordering_assigner": {"type": "warfield", "optimizer": {"type": "composite", "optimizers": ["wmedian", 3]}}}`, []string{
+			"pipeline: ordering_assigner.optimizer.optimizers[1]: expected ordering_optimizer name or object, got 3",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			c, err := ParseJSON([]byte(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Build(c)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := strings.Split(err.Error(), "\n"); strings.Join(got, "|") != strings.Join(tt.errs, "|") {
+				t.Errorf("expected errors:\n%s\ngot:\n%s", strings.Join(tt.errs, "\n"), err)
+			}
+			var e Error
+			if !errors.As(err, &e) {
+				t.Errorf("expected pipeline error, got %T", err)
+			}
+		})
+	}
+}
+
+func TestBuildLimited(t *testing.T) {
+	c, err := ParseJSON([]byte(`{"type": "layers", "levels_assigner": {"type": "weighted", "iterations": 20}, "ordering_assigner": {"type": "warfield", "epochs": 2000000}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DefaultRegistry.BuildLimited(c, Limits{Iterations: 1000})
+	if err == nil || err.Error() != "pipeline: ordering_assigner.epochs: 2000000 is more than maximum of 1000 iterations" {
+		t.Errorf("expected error of epochs, got %v", err)
+	}
+	if _, err := DefaultRegistry.Build(c); err != nil {
+		t.Errorf("build should not limit iterations: %v", err)
+	}
+
+	// iterations of nested components and of forces are limited too
+	c, err = ParseJSON([]byte(`{"type": "forces", "max_steps": 1001}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DefaultRegistry.BuildLimited(c, Limits{Iterations: 1000}); err == nil {
+		t.Errorf("expected error of max_steps")
+	}
+}
+
+func TestBuildLimitedWork(t *testing.T) {
+	tests := []struct {
+		config string
+		work   int
+	}{
+		{`{"type": "forces", "max_steps": 100}`, 100 * (1 + 2)},
+		{`{"type": "sequence", "layouts": ["direct_edges", "direct_edges"]}`, 1 + 2},
+		{`{"type": "sequence", "layouts": [{"type": "forces", "max_steps": 100}, {"type": "forces", "max_steps": 100}]}`, 1 + 2*300},
+		{`{"type": "layers", "ordering_assigner": {"type": "warfield", "epochs": 10, "optimizer": {"type": "random", "epochs": 5}}}`, 1 + 5 + 10*(1+1+5)},
+	}
+	for _, tt := range tests {
+		c, err := ParseJSON([]byte(tt.config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DefaultRegistry.BuildLimited(c, Limits{Work: tt.work}); err != nil {
+			t.Errorf("%s: %v", tt.config, err)
+		}
+		_, err = DefaultRegistry.BuildLimited(c, Limits{Work: tt.work - 1})
+		if expected := fmt.Sprintf("pipeline: work of config is %d iterations, maximum is %d", tt.work, tt.work-1); err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, got %v", tt.config, expected, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := ParseJSON([]byte(`[1, 2]`)); err == nil {
+		t.Error("expected error for JSON array")
+	}
+	if _, err := ParseYAML([]byte(`- layers`)); err == nil {
+		t.Error("expected error for YAML list")
+	}
+	if _, err := ParseYAML([]byte(``)); err == nil {
+		t.Error("expected error for empty YAML")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register(KindLayout, "nothing", func(p *Params) any { return layout.SequenceLayout{} })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for duplicate component")
+		}
+	}()
+	r.Register(KindLayout, "nothing", func(p *Params) any { return layout.SequenceLayout{} })
+}
@@ -0,0 +1,262 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kind is role of component in layout, components of same kind are interchangeable.
+type Kind string
+
+const (
+	KindLayout              Kind = "layout"               // layout.Layout
+	KindCycleRemover        Kind = "cycle_remover"        // layout.CycleRemover
+	KindLevelsAssigner      Kind = "levels_assigner"      // func(layout.Graph) layout.LayeredGraph
+	KindOrderingAssigner    Kind = "ordering_assigner"    // func(layout.Graph, layout.LayeredGraph)
+	KindOrderingInitializer Kind = "ordering_initializer" // layout.LayerOrderingInitializer
+	KindOrderingOptimizer   Kind = "ordering_optimizer"   // layout.LayerOrderingOptimizer
+	KindHorizontalAssigner  Kind = "horizontal_assigner"  // layout.NodesHorizontalCoordinatesAssigner
+	KindVerticalAssigner    Kind = "vertical_assigner"    // layout.NodesVerticalCoordinatesAssigner
+	KindEdgePathAssigner    Kind = "edge_path_assigner"   // func(layout.Graph, layout.LayeredGraph, map[uint64]layout.Position)
+	KindForce               Kind = "force"                // layout.Force
+)
+
+// Factory makes component from its parameters.
+// Bad parameters are reported with Params.Errorf, factory still returns component.
+type Factory func(p *Params) any
+
+// Registry maps names of components to their factories, for each kind of component.
+type Registry struct {
+	factories map[Kind]map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: map[Kind]map[string]Factory{}}
+}
+
+// Register adds component factory under name. Registering same name twice for kind panics.
+func (r *Registry) Register(kind Kind, name string, f Factory) {
+	if r.factories[kind] == nil {
+		r.factories[kind] = map[string]Factory{}
+	}
+	if _, ok := r.factories[kind][name]; ok {
+		panic(fmt.Errorf("pipeline: %s %q is already registered", kind, name))
+	}
+	r.factories[kind][name] = f
+}
+
+// Names are sorted names of registered components of kind.
+func (r *Registry) Names(kind Kind) []string {
+	names := make([]string, 0, len(r.factories[kind]))
+	for name := range r.factories[kind] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Error is problem in config, Path tells where it is, like "ordering_assigner.optimizer.optimizers[1]".
+type Error struct {
+	Path string
+	Msg  string
+}
+
+func (e Error) Error() string {
+	if e.Path == "" {
+		return "pipeline: " + e.Msg
+	}
+	return fmt.Sprintf("pipeline: %s: %s", e.Path, e.Msg)
+}
+
+// Params are parameters of component being built.
+// Getters return default value when parameter is missing, and record errors for parameters of wrong type.
+// Parameters that are not read by factory are reported as unknown.
+type Params struct {
+	registry   *Registry
+	path       string
+	values     map[string]any
+	used       map[string]bool
+	errs       *[]error
+	limits     Limits
+	iterations int // sum of Iterations read by factory
+	work       int // sum of work of nested components
+}
+
+func (p *Params) paramPath(name string) string {
+	if p.path == "" {
+		return name
+	}
+	return p.path + "." + name
+}
+
+// Errorf records error of parameter.
+func (p *Params) Errorf(name string, format string, args ...any) {
+	*p.errs = append(*p.errs, Error{Path: p.paramPath(name), Msg: fmt.Sprintf(format, args...)})
+}
+
+func (p *Params) get(name string) (any, bool) {
+	p.used[name] = true
+	v, ok := p.values[name]
+	return v, ok && v != nil
+}
+
+// Float is number parameter.
+func (p *Params) Float(name string, def float64) float64 {
+	v, ok := p.get(name)
+	if !ok {
+		return def
+	}
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	p.Errorf(name, "expected number, got %v", v)
+	return def
+}
+
+// Int is integer parameter.
+func (p *Params) Int(name string, def int) int {
+	v, ok := p.get(name)
+	if !ok {
+		return def
+	}
+	switch v := v.(type) {
+	case int:
+		return v
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+			return int(v)
+		}
+	}
+	p.Errorf(name, "expected integer, got %v", v)
+	return def
+}
+
+// NonNegativeInt is integer parameter that can not be negative.
+func (p *Params) NonNegativeInt(name string, def int) int {
+	v := p.Int(name, def)
+	if v < 0 {
+		p.Errorf(name, "expected non negative integer, got %d", v)
+		return def
+	}
+	return v
+}
+
+// Iterations is number of iterations, epochs or steps, that tells how long layout runs.
+// It can not be negative, nor larger than limit of build.
+func (p *Params) Iterations(name string, def int) int {
+	v := p.NonNegativeInt(name, def)
+	if p.limits.Iterations > 0 && v > p.limits.Iterations {
+		p.Errorf(name, "%d is more than maximum of %d iterations", v, p.limits.Iterations)
+		v = def
+	}
+	p.iterations = min(p.iterations+v, maxWork)
+	return v
+}
+
+// Bool is boolean parameter.
+func (p *Params) Bool(name string, def bool) bool {
+	v, ok := p.get(name)
+	if !ok {
+		return def
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	p.Errorf(name, "expected boolean, got %v", v)
+	return def
+}
+
+// Component builds nested component of kind from parameter, or from def config when parameter is missing.
+// Component config is either name of component, or object with name in "type" and its parameters.
+func Component[T any](p *Params, kind Kind, name string, def any) T {
+	v, ok := p.get(name)
+	if !ok {
+		v = def
+	}
+	c, work := build[T](p.registry, kind, p.paramPath(name), v, p.errs, p.limits)
+	p.work = min(p.work+work, maxWork)
+	return c
+}
+
+// Components builds list of nested components of kind, def is used when parameter is missing.
+func Components[T any](p *Params, kind Kind, name string, def []any) []T {
+	v, ok := p.get(name)
+	if !ok {
+		v = def
+	}
+	list, ok := v.([]any)
+	if !ok {
+		p.Errorf(name, "expected list of %s, got %v", kind, v)
+		return nil
+	}
+	components := make([]T, 0, len(list))
+	for i, c := range list {
+		c, work := build[T](p.registry, kind, fmt.Sprintf("%s[%d]", p.paramPath(name), i), c, p.errs, p.limits)
+		p.work = min(p.work+work, maxWork)
+		components = append(components, c)
+	}
+	return components
+}
+
+// maxWork is where work of components stops growing, so that it does not overflow.
+const maxWork = math.MaxInt32
+
+// build makes component of kind from config, together with its work.
+// Work of component is its iterations times work of its nested components, since they run in each iteration.
+func build[T any](r *Registry, kind Kind, path string, config any, errs *[]error, limits Limits) (T, int) {
+	var zero T
+	fail := func(format string, args ...any) (T, int) {
+		*errs = append(*errs, Error{Path: path, Msg: fmt.Sprintf(format, args...)})
+		return zero, 1
+	}
+
+	var values map[string]any
+	switch c := config.(type) {
+	case string:
+		values = map[string]any{"type": c}
+	case map[string]any:
+		values = c
+	case Config: // nested YAML mappings
+		values = c
+	case nil:
+		return fail("missing %s", kind)
+	default:
+		return fail("expected %s name or object, got %v", kind, config)
+	}
+
+	name, ok := values["type"].(string)
+	if !ok {
+		return fail("missing type of %s", kind)
+	}
+	factory, ok := r.factories[kind][name]
+	if !ok {
+		return fail("unknown %s %q, expected one of: %s", kind, name, strings.Join(r.Names(kind), ", "))
+	}
+
+	p := &Params{registry: r, path: path, values: values, used: map[string]bool{"type": true}, errs: errs, limits: limits}
+	component := factory(p)
+	work := int(min(int64(max(p.iterations, 1))*int64(1+p.work), maxWork))
+
+	var unknown []string
+	for k := range values {
+		if !p.used[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		p.Errorf(k, "unknown parameter of %s %q", kind, name)
+	}
+
+	t, ok := component.(T)
+	if !ok {
+		return fail("%s %q is %T, not %v", kind, name, component, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return t, work
+}
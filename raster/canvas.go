@@ -0,0 +1,240 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+// bezierSegments is number of segments of polylines that approximate quadratic Bézier curves.
+const bezierSegments = 16
+
+// joinSegments is number of segments of circles at joins of strokes.
+const joinSegments = 16
+
+var goRegular = sync.OnceValue(func() *opentype.Font {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		panic(err)
+	}
+	return f
+})
+
+// canvas draws shapes with coordinates of graph to image.
+type canvas struct {
+	img    *image.RGBA
+	scale  float64
+	x, y   float64 // point of graph at top left corner of image
+	raster vector.Rasterizer
+	faces  map[int]font.Face
+}
+
+func newCanvas(img *image.RGBA, scale, x, y float64) *canvas {
+	return &canvas{img: img, scale: scale, x: x, y: y, faces: make(map[int]font.Face)}
+}
+
+func (c *canvas) point(p [2]float64) (float64, float64) {
+	return (p[0] - c.x) * c.scale, (p[1] - c.y) * c.scale
+}
+
+// fill fills polygons. Overlapping polygons with same orientation are filled once.
+func (c *canvas) fill(polygons [][][2]float64, col color.Color) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, poly := range polygons {
+		for _, p := range poly {
+			x, y := c.point(p)
+			minX, minY = math.Min(minX, x), math.Min(minY, y)
+			maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return
+	}
+	// rasterizer is only as large as polygons, so small shapes in large images are cheap
+	r := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).Intersect(c.img.Bounds())
+	if r.Empty() {
+		return
+	}
+	c.raster.Reset(r.Dx(), r.Dy())
+	for _, poly := range polygons {
+		if len(poly) < 3 {
+			continue
+		}
+		for i, p := range poly {
+			x, y := c.point(p)
+			x, y = x-float64(r.Min.X), y-float64(r.Min.Y)
+			if i == 0 {
+				c.raster.MoveTo(float32(x), float32(y))
+			} else {
+				c.raster.LineTo(float32(x), float32(y))
+			}
+		}
+		c.raster.ClosePath()
+	}
+	c.raster.Draw(c.img, r, image.NewUniform(col), image.Point{})
+}
+
+// stroke strokes polyline with butt ends and round joins, width and dash are in units of graph.
+func (c *canvas) stroke(line [][2]float64, width float64, dash []float64, col color.Color) {
+	if width <= 0 {
+		return
+	}
+	hw := width / 2
+	var polygons [][][2]float64
+	for _, part := range dashes(line, dash) {
+		for i := 1; i < len(part); i++ {
+			a, b := part[i-1], part[i]
+			dx, dy := b[0]-a[0], b[1]-a[1]
+			length := math.Hypot(dx, dy)
+			if length == 0 {
+				continue
+			}
+			nx, ny := -dy/length*hw, dx/length*hw
+			polygons = append(polygons, clockwise([][2]float64{
+				{a[0] + nx, a[1] + ny}, {b[0] + nx, b[1] + ny}, {b[0] - nx, b[1] - ny}, {a[0] - nx, a[1] - ny},
+			}))
+		}
+		for i := 1; i < len(part)-1; i++ {
+			polygons = append(polygons, clockwise(circle(part[i], hw)))
+		}
+	}
+	c.fill(polygons, col)
+}
+
+// text draws line of text with Go Regular font, size is in units of graph.
+func (c *canvas) text(line svg.TextLine, size int, fontColor string) {
+	col, ok := svg.ParseColor(fontColor)
+	if !ok || line.Text == "" {
+		return
+	}
+	face := c.face(size)
+	if face == nil {
+		return
+	}
+	d := font.Drawer{Dst: c.img, Src: image.NewUniform(col), Face: face}
+	x, y := c.point([2]float64{float64(line.X), float64(line.Y)})
+	dot := fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(y * 64)}
+	switch line.Anchor {
+	case "middle":
+		dot.X -= d.MeasureString(line.Text) / 2
+	case "end":
+		dot.X -= d.MeasureString(line.Text)
+	}
+	d.Dot = dot
+	d.DrawString(line.Text)
+}
+
+// face is font face for size in units of graph, faces are cached by size.
+func (c *canvas) face(size int) font.Face {
+	if f, ok := c.faces[size]; ok {
+		return f
+	}
+	// at 72 DPI, size in points is size in pixels
+	f, err := opentype.NewFace(goRegular(), &opentype.FaceOptions{Size: float64(size) * c.scale, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		f = nil
+	}
+	c.faces[size] = f
+	return f
+}
+
+// dashes splits line into dashes, dash alternates lengths of dashes and gaps.
+func dashes(line [][2]float64, dash []float64) [][][2]float64 {
+	total := 0.0
+	for _, d := range dash {
+		total += d
+	}
+	if total == 0 || len(line) == 0 {
+		return [][][2]float64{line}
+	}
+	var parts [][][2]float64
+	i, remaining, on := 0, dash[0], true
+	current := [][2]float64{line[0]}
+	for k := 1; k < len(line); k++ {
+		a, b := line[k-1], line[k]
+		length := math.Hypot(b[0]-a[0], b[1]-a[1])
+		t := 0.0
+		for length-t > remaining {
+			t += remaining
+			p := [2]float64{a[0] + (b[0]-a[0])*t/length, a[1] + (b[1]-a[1])*t/length}
+			if on {
+				parts = append(parts, append(current, p))
+			}
+			current = [][2]float64{p}
+			on = !on
+			i = (i + 1) % len(dash)
+			remaining = dash[i]
+		}
+		remaining -= length - t
+		current = append(current, b)
+	}
+	if on && len(current) > 1 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// smooth is curve through first and last points, made of quadratic Bézier curves
+// with inner points as control points, that join at middles of segments.
+func smooth(points [][2]float64) [][2]float64 {
+	if len(points) < 3 {
+		return points
+	}
+	mid := func(a, b [2]float64) [2]float64 {
+		return [2]float64{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2}
+	}
+	curve := [][2]float64{points[0]}
+	start := points[0]
+	for i := 1; i < len(points)-1; i++ {
+		end := mid(points[i], points[i+1])
+		if i == len(points)-2 {
+			end = points[i+1]
+		}
+		ctrl := points[i]
+		for s := 1; s <= bezierSegments; s++ {
+			t := float64(s) / bezierSegments
+			u := 1 - t
+			curve = append(curve, [2]float64{
+				u*u*start[0] + 2*u*t*ctrl[0] + t*t*end[0],
+				u*u*start[1] + 2*u*t*ctrl[1] + t*t*end[1],
+			})
+		}
+		start = end
+	}
+	return curve
+}
+
+func circle(center [2]float64, r float64) [][2]float64 {
+	points := make([][2]float64, 0, joinSegments)
+	for i := 0; i < joinSegments; i++ {
+		a := 2 * math.Pi * float64(i) / joinSegments
+		points = append(points, [2]float64{center[0] + r*math.Cos(a), center[1] + r*math.Sin(a)})
+	}
+	return points
+}
+
+// clockwise is polygon with points in clockwise order, as y axis points down.
+// Strokes are made of many polygons that must have same orientation to not cancel where they overlap.
+func clockwise(poly [][2]float64) [][2]float64 {
+	area := 0.0
+	for i, p := range poly {
+		q := poly[(i+1)%len(poly)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	if area < 0 {
+		for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+			poly[i], poly[j] = poly[j], poly[i]
+		}
+	}
+	return poly
+}
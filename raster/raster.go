@@ -0,0 +1,175 @@
+// Package raster draws laid out graphs to images in pure Go, so thumbnails and image diffs do not need browsers.
+//
+// Graphs are drawn like SVG of svg package: same themes, styles, node shapes and arrows.
+// Text is drawn with Go Regular font, which is bundled with golang.org/x/image.
+//
+//	img := raster.Renderer{DPI: 192}.Draw(svg.FromLayout(g))
+package raster
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/gverger/go-graph-layout/layout"
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+// Renderer draws graphs to images.
+type Renderer struct {
+	DPI    float64    // dots per inch, 96 by default is one pixel per unit of layout, like in SVG
+	Margin int        // space around graph, in units of layout
+	Theme  *svg.Theme // svg.DefaultTheme when nil, background is transparent when theme has none
+	Curved bool       // edges are smooth Bézier curves through points of their paths, instead of polylines
+
+	// MaxPixels is maximum area of image written by WritePNG, 0 is no limit.
+	// Size of image comes from positions and sizes in graph, so it should be limited for graphs from untrusted sources.
+	MaxPixels int
+}
+
+// DefaultDPI is resolution where one unit of layout is one pixel.
+const DefaultDPI = 96
+
+// ErrTooLarge is error of WritePNG when image would have more than MaxPixels pixels.
+var ErrTooLarge = errors.New("raster: image is too large")
+
+func (r Renderer) scale() float64 {
+	if r.DPI <= 0 {
+		return 1
+	}
+	return r.DPI / DefaultDPI
+}
+
+// themed is graph sized with theme of renderer, when graph has no theme.
+func (r Renderer) themed(g svg.Graph) svg.Graph {
+	if g.Theme == nil {
+		g.Theme = r.Theme
+	}
+	return g
+}
+
+// size is size of image in pixels, it is float so that huge graphs do not overflow.
+func (r Renderer) size(g svg.Graph) (w, h float64) {
+	_, _, gw, gh := r.themed(g).Bounds()
+	return math.Ceil(float64(gw+2*r.Margin) * r.scale()), math.Ceil(float64(gh+2*r.Margin) * r.scale())
+}
+
+// Draw draws graph to image that fits graph and margin.
+// Graph without theme is sized with theme of renderer.
+func (r Renderer) Draw(g svg.Graph) *image.RGBA {
+	theme := svg.DefaultTheme
+	if r.Theme != nil {
+		theme = *r.Theme
+	}
+	g = r.themed(g)
+	x, y, w, h := g.Bounds()
+	x, y, w, h = x-r.Margin, y-r.Margin, w+2*r.Margin, h+2*r.Margin
+	iw, ih := r.size(g)
+	img := image.NewRGBA(image.Rect(0, 0, int(iw), int(ih)))
+	c := newCanvas(img, r.scale(), float64(x), float64(y))
+
+	if bg, ok := svg.ParseColor(theme.Background); ok {
+		c.fill([][][2]float64{{{float64(x), float64(y)}, {float64(x + w), float64(y)}, {float64(x + w), float64(y + h)}, {float64(x), float64(y + h)}}}, bg)
+	}
+
+	g.Walk(drawer{c: c, theme: theme, curved: r.Curved})
+	return img
+}
+
+// DrawLayout draws layout with nodes titled by their IDs.
+func (r Renderer) DrawLayout(g layout.Graph) *image.RGBA {
+	return r.Draw(svg.FromLayout(g))
+}
+
+// WritePNG draws graph and writes it as PNG.
+// It fails with ErrTooLarge before drawing, when image is larger than MaxPixels.
+func (r Renderer) WritePNG(w io.Writer, g svg.Graph) error {
+	if iw, ih := r.size(g); r.MaxPixels > 0 && iw*ih > float64(r.MaxPixels) {
+		return fmt.Errorf("%w: %.0fx%.0f pixels, maximum is %d", ErrTooLarge, iw, ih, r.MaxPixels)
+	}
+	return png.Encode(w, r.Draw(g))
+}
+
+// drawer draws parts of graph in same order as in SVG.
+type drawer struct {
+	c      *canvas
+	theme  svg.Theme
+	curved bool
+}
+
+func (d drawer) VisitCluster(cl svg.Cluster) {
+	p := d.theme.ClusterPaint(cl.Style)
+	d.c.shape(cl.Outline(), nil, p)
+	d.c.text(cl.TitleLine(), p.FontSize, p.FontColor)
+}
+
+func (d drawer) VisitEdge(e svg.Edge) {
+	d.c.edge(e.Path, e.Head, e.Tail, d.theme.EdgePaint(e.Style), d.curved)
+}
+
+func (d drawer) VisitNode(n svg.Node) {
+	p := d.theme.NodePaint(n.Style)
+	geom := n.Geometry()
+	d.c.shape(geom.Outline, geom.Lines, p)
+	if sep, ok := svg.ParseColor(d.theme.DataSeparator); ok {
+		for _, s := range geom.Separators {
+			d.c.stroke([][2]float64{s[0], s[1]}, 1, nil, sep)
+		}
+	}
+	for _, line := range geom.Text {
+		d.c.text(line, geom.FontSize, p.FontColor)
+	}
+}
+
+// shape fills and strokes closed outline, lines are stroked like outline.
+func (c *canvas) shape(outline [][2]float64, lines [][][2]float64, p svg.Paint) {
+	if fill, ok := svg.ParseColor(p.Fill); ok {
+		c.fill([][][2]float64{outline}, fill)
+	}
+	stroke, ok := svg.ParseColor(p.Stroke)
+	if !ok || p.StrokeWidth <= 0 || len(outline) == 0 {
+		return
+	}
+	c.stroke(append(outline, outline[0]), p.StrokeWidth, p.Dash, stroke)
+	for _, l := range lines {
+		c.stroke(l, p.StrokeWidth, p.Dash, stroke)
+	}
+}
+
+// edge strokes path of edge and draws its arrows.
+func (c *canvas) edge(path [][2]int, head, tail svg.Arrow, p svg.Paint, curved bool) {
+	stroke, ok := svg.ParseColor(p.Stroke)
+	if !ok || len(path) < 2 {
+		return
+	}
+	points := make([][2]float64, 0, len(path))
+	for _, pt := range path {
+		points = append(points, [2]float64{float64(pt[0]), float64(pt[1])})
+	}
+	line := points
+	if curved {
+		line = smooth(points)
+	}
+	c.stroke(line, p.StrokeWidth, p.Dash, stroke)
+
+	last := len(points) - 1
+	c.arrow(head, points[last], points[last-1], p.StrokeWidth, stroke)
+	c.arrow(tail, points[0], points[1], p.StrokeWidth, stroke)
+}
+
+// arrow draws arrow with tip at point, pointing away from previous point.
+func (c *canvas) arrow(a svg.Arrow, tip, from [2]float64, strokeWidth float64, col color.Color) {
+	arrow, ok := svg.Arrowhead(a, tip, from, strokeWidth)
+	if !ok {
+		return
+	}
+	if arrow.Open {
+		c.stroke(arrow.Points, arrow.Width, nil, col)
+		return
+	}
+	c.fill([][][2]float64{arrow.Points}, col)
+}
@@ -0,0 +1,265 @@
+package raster
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+	"github.com/gverger/go-graph-layout/svg"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testGraph() svg.Graph {
+	return svg.Graph{
+		Nodes: map[uint64]svg.Node{
+			1: {ID: "1", X: 10, Y: 10, Title: "table", NodeData: map[string]any{"a": 1, "b": "x"}},
+			2: {ID: "2", X: 150, Y: 120, Title: "ellipse", Shape: svg.ShapeEllipse, Style: svg.Style{Fill: "yellow"}},
+			3: {ID: "3", X: 10, Y: 200, Title: "cylinder", Shape: svg.ShapeCylinder},
+			4: {ID: "4", X: 250, Y: 10, Title: "diamond", Shape: svg.ShapeDiamond},
+		},
+		Edges: map[[2]uint64]svg.Edge{
+			{1, 2}: {Path: [][2]int{{40, 30}, {120, 60}, {180, 140}}, Head: svg.ArrowNormal},
+			{2, 3}: {Path: [][2]int{{180, 140}, {40, 220}}, Head: svg.ArrowOpen, Tail: svg.ArrowDot, Style: svg.Style{Dash: "5 3"}},
+			{4, 2}: {Path: [][2]int{{280, 30}, {280, 100}, {180, 140}}, Head: svg.ArrowDiamond, Style: svg.Style{Stroke: "red", StrokeWidth: 2}},
+		},
+		Clusters: map[string]svg.Cluster{"c": {ID: "c", X: 0, Y: 0, W: 120, H: 80, Title: "cluster"}},
+	}
+}
+
+// golden compares image to PNG in testdata. Images may differ in few pixels, as floating point math
+// of rasterizer is not same on all architectures.
+func golden(t *testing.T, name string, img image.Image) {
+	t.Helper()
+	path := "testdata/" + name
+	if *update {
+		var b bytes.Buffer
+		if err := png.Encode(&b, img); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	expected, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected.Bounds() != img.Bounds() {
+		t.Fatalf("%s has size %v, image has size %v", path, expected.Bounds(), img.Bounds())
+	}
+	different := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if !near(expected.At(x, y), img.At(x, y), 16) {
+				different++
+			}
+		}
+	}
+	if different > b.Dx()*b.Dy()/200 {
+		t.Errorf("%s differs in %d pixels, run tests with -update if change is expected", path, different)
+	}
+}
+
+func near(a, b color.Color, tolerance int) bool {
+	c1, c2 := color.NRGBAModel.Convert(a).(color.NRGBA), color.NRGBAModel.Convert(b).(color.NRGBA)
+	diff := func(x, y uint8) bool { return int(x)-int(y) > tolerance || int(y)-int(x) > tolerance }
+	return !diff(c1.R, c2.R) && !diff(c1.G, c2.G) && !diff(c1.B, c2.B) && !diff(c1.A, c2.A)
+}
+
+func TestDrawGolden(t *testing.T) {
+	theme := svg.DefaultTheme
+	theme.Background = "white"
+	golden(t, "graph.png", Renderer{Margin: 10, Theme: &theme}.Draw(testGraph()))
+	golden(t, "graph_curved.png", Renderer{DPI: 192, Margin: 10, Theme: &theme, Curved: true}.Draw(testGraph()))
+}
+
+func TestDrawDPI(t *testing.T) {
+	g := testGraph()
+	x, y, w, h := g.Bounds()
+	for _, tc := range []struct {
+		dpi  float64
+		size image.Point
+	}{
+		{0, image.Pt(w+20, h+20)},
+		{96, image.Pt(w+20, h+20)},
+		{192, image.Pt(2*(w+20), 2*(h+20))},
+		{48, image.Pt((w+21)/2, (h+21)/2)},
+	} {
+		img := Renderer{DPI: tc.dpi, Margin: 10}.Draw(g)
+		if img.Bounds().Size() != tc.size {
+			t.Errorf("dpi %v: expected size %v, got %v", tc.dpi, tc.size, img.Bounds().Size())
+		}
+	}
+
+	// graph point is at same place of image at any DPI
+	n := g.Nodes[2]
+	for _, dpi := range []float64{96, 192} {
+		scale := dpi / DefaultDPI
+		img := Renderer{DPI: dpi, Margin: 10}.Draw(g)
+		px := int(float64(n.X-x+10+5) * scale)
+		py := int(float64(n.Y-y+10+20) * scale)
+		if !near(img.At(px, py), color.RGBA{R: 0xff, G: 0xff, A: 0xff}, 0) {
+			t.Errorf("dpi %v: expected yellow fill of node, got %v", dpi, img.At(px, py))
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	g := svg.Graph{
+		Nodes: map[uint64]svg.Node{
+			1: {ID: "1", X: 0, Y: 0, W: 40, H: 20, Shape: svg.ShapeRect, Style: svg.Style{Fill: "blue"}},
+			2: {ID: "2", X: 0, Y: 100, W: 40, H: 20, Shape: svg.ShapeRect, Title: "WWW", Style: svg.Style{Fill: "white", Stroke: "lime", StrokeWidth: 2}},
+		},
+		Edges: map[[2]uint64]svg.Edge{
+			{1, 2}: {Path: [][2]int{{20, 10}, {20, 110}}, Head: svg.ArrowNormal, Style: svg.Style{Stroke: "red", StrokeWidth: 2}},
+		},
+	}
+	img := Renderer{}.Draw(g)
+
+	tests := []struct {
+		name  string
+		x, y  int
+		color color.Color
+	}{
+		{"background", 5, 60, color.RGBA{}},
+		{"node fill", 5, 5, color.RGBA{B: 0xff, A: 0xff}},
+		{"edge", 20, 50, color.RGBA{R: 0xff, A: 0xff}},
+		{"arrow", 24, 88, color.RGBA{R: 0xff, A: 0xff}},
+		{"beside arrow", 30, 88, color.RGBA{}},
+		{"node border", 0, 110, color.RGBA{G: 0xff, A: 0xff}},
+	}
+	for _, tc := range tests {
+		if got := img.At(tc.x, tc.y); !near(got, tc.color, 8) {
+			t.Errorf("%s: expected %v at (%d, %d), got %v", tc.name, tc.color, tc.x, tc.y, got)
+		}
+	}
+
+	// title is drawn inside node
+	dark := 0
+	for y := 101; y < 119; y++ {
+		for x := 1; x < 39; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r < 0x8000 {
+				dark++
+			}
+		}
+	}
+	if dark == 0 {
+		t.Errorf("expected text in node")
+	}
+}
+
+func TestDrawTheme(t *testing.T) {
+	g := svg.Graph{Nodes: map[uint64]svg.Node{1: {ID: "1", W: 40, H: 20, Style: svg.Style{Fill: "blue"}}}}
+	theme := svg.DefaultTheme
+
+	// corners of boxes are rounded with radius of theme of renderer
+	theme.NodeRadius = 0
+	if _, _, _, a := (Renderer{Theme: &theme}).Draw(g).At(0, 0).RGBA(); a == 0 {
+		t.Errorf("expected square corner")
+	}
+	theme.NodeRadius = 10
+	if _, _, _, a := (Renderer{Theme: &theme}).Draw(g).At(0, 0).RGBA(); a != 0 {
+		t.Errorf("expected rounded corner")
+	}
+}
+
+func TestDrawLayout(t *testing.T) {
+	g := layout.Graph{
+		Nodes: map[uint64]layout.Node{
+			1: {Position: layout.Position{X: 0, Y: 0}, W: 30, H: 20},
+			2: {Position: layout.Position{X: 0, Y: 60}, W: 30, H: 20},
+		},
+		Edges: map[[2]uint64]layout.Edge{
+			{1, 2}: {Path: []layout.Position{{X: 15, Y: 10}, {X: 15, Y: 70}}},
+		},
+	}
+	img := Renderer{}.DrawLayout(g)
+	if img.Bounds().Size() != image.Pt(30, 80) {
+		t.Errorf("unexpected size %v", img.Bounds().Size())
+	}
+	if _, _, _, a := img.At(15, 40).RGBA(); a == 0 {
+		t.Errorf("expected edge")
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	var b bytes.Buffer
+	if err := (Renderer{DPI: 144}).WritePNG(&b, testGraph()); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, w, h := testGraph().Bounds()
+	if img.Bounds().Dx() != (3*w+1)/2 || img.Bounds().Dy() != (3*h+1)/2 {
+		t.Errorf("unexpected size %v", img.Bounds())
+	}
+
+	// huge node is rejected before image is allocated
+	huge := svg.Graph{Nodes: map[uint64]svg.Node{1: {ID: "1", W: 40000, H: 40000}}}
+	err = Renderer{MaxPixels: 1 << 24}.WritePNG(&b, huge)
+	if !errors.Is(err, ErrTooLarge) || err.Error() != "raster: image is too large: 40000x40000 pixels, maximum is 16777216" {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestDashes(t *testing.T) {
+	line := [][2]float64{{0, 0}, {10, 0}, {10, 10}}
+	parts := dashes(line, []float64{4, 2})
+	expected := [][][2]float64{
+		{{0, 0}, {4, 0}},
+		{{6, 0}, {10, 0}, {10, 0}},
+		{{10, 2}, {10, 6}},
+		{{10, 8}, {10, 10}},
+	}
+	if len(parts) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, parts)
+	}
+	for i := range parts {
+		if len(parts[i]) != len(expected[i]) {
+			t.Fatalf("expected %v, got %v", expected, parts)
+		}
+		for j := range parts[i] {
+			if parts[i][j] != expected[i][j] {
+				t.Errorf("expected %v, got %v", expected, parts)
+			}
+		}
+	}
+
+	if parts := dashes(line, nil); len(parts) != 1 || len(parts[0]) != 3 {
+		t.Errorf("expected solid line, got %v", parts)
+	}
+}
+
+func TestSmooth(t *testing.T) {
+	line := [][2]float64{{0, 0}, {10, 0}, {10, 10}, {20, 10}}
+	curve := smooth(line)
+	if curve[0] != line[0] || curve[len(curve)-1] != line[len(line)-1] {
+		t.Errorf("curve should start and end at ends of line: %v", curve)
+	}
+	// curves join at middles of inner segments
+	found := false
+	for _, p := range curve {
+		if p == [2]float64{10, 5} {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("curve should go through middle of segment: %v", curve)
+	}
+}
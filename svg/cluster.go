@@ -0,0 +1,66 @@
+package svg
+
+import "io"
+
+// Cluster is rendered box around group of nodes.
+// Title is put in top left corner of the box, with padding and font size of theme of graph.
+type Cluster struct {
+	ID    string // used to make DOM IDs
+	X     int
+	Y     int
+	W     int
+	H     int
+	Title string
+	Style Style
+	look  look // set by graph
+}
+
+const clusterRadius = 5
+
+func (c Cluster) Render() string {
+	return render(c)
+}
+
+// WriteTo writes same output as Render to w.
+func (c Cluster) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, c)
+}
+
+func (c Cluster) write(w *writer) {
+	w.start("g", attr("id", "svg:graph:cluster:"+c.ID), attr("class", c.Style.classes("graph-cluster")))
+	w.element("rect", "", optionalAttrs(
+		attr("class", "graph-cluster-box"),
+		intAttr("x", c.X),
+		intAttr("y", c.Y),
+		intAttr("width", c.W),
+		intAttr("height", c.H),
+		intAttr("rx", clusterRadius),
+		attr("style", c.Style.svgStyle()),
+	)...)
+	title := c.TitleLine()
+	w.element("text", title.Text, optionalAttrs(
+		attr("class", "graph-cluster-title"),
+		intAttr("x", title.X),
+		intAttr("y", title.Y),
+		attr("style", c.Style.textStyle()),
+	)...)
+	w.end("g")
+}
+
+func (c Cluster) theme() Theme {
+	if c.look.theme != nil {
+		return *c.look.theme
+	}
+	return DefaultTheme
+}
+
+// TitleLine is title of cluster in its top left corner.
+func (c Cluster) TitleLine() TextLine {
+	padding := c.theme().nodePadding()
+	return TextLine{Text: c.Title, X: c.X + padding/2, Y: c.Y + c.Style.fontSizeIn(c.theme()) + padding/2, Anchor: "start", Title: true}
+}
+
+// Outline is rounded box of cluster.
+func (c Cluster) Outline() [][2]float64 {
+	return roundedRect(float64(c.X), float64(c.Y), float64(c.X+c.W), float64(c.Y+c.H), clusterRadius)
+}
@@ -1,34 +1,90 @@
 package svg
 
 import (
+	"encoding/xml"
 	"fmt"
-	"strings"
+	"io"
 )
 
+// Renderable is part of document.
+// Renderables that also implement io.WriterTo, like all types of this package,
+// are streamed by WriteTo of documents that contain them.
 type Renderable interface {
 	Render() string
 }
 
+// SVG is document with definitions, like arrow markers, and body.
+// Theme is rendered as <style> block, DefaultTheme is used when Theme is nil.
+//
+// When body has bounds, like Graph, document has viewBox around body and Margin, so nothing is cut off and image scales.
+// Image has size of viewBox in pixels, Width and Height change it, when only one of them is set other one keeps aspect ratio.
+// With Fit image fills its container and body is scaled to fit it, Width and Height are ignored.
 type SVG struct {
 	ID          string
+	Theme       *Theme
 	Definitions []Renderable
 	Body        Renderable
+	Margin      int // space around body, in pixels
+	Width       int
+	Height      int
+	Fit         bool
+}
+
+// bounded is body with known bounds.
+type bounded interface {
+	Bounds() (x, y, w, h int)
 }
 
 func (s SVG) Render() string {
-	defs := make([]string, 0, len(s.Definitions))
+	return render(s)
+}
+
+// WriteTo writes same output as Render to w.
+func (s SVG) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, s)
+}
+
+func (s SVG) write(w *writer) {
+	theme := DefaultTheme
+	if s.Theme != nil {
+		theme = *s.Theme
+	}
+
+	w.start("svg", append([]xml.Attr{attr("id", s.ID), attr("xmlns", namespaceSVG)}, s.sizeAttrs()...)...)
+	w.start("defs")
+	w.element("style", "\n"+theme.CSS(s.ID)+"\n")
 	for _, d := range s.Definitions {
-		defs = append(defs, d.Render())
+		w.raw(d)
+	}
+	w.end("defs")
+	if s.Body != nil {
+		w.raw(s.Body)
+	}
+	w.end("svg")
+}
+
+// sizeAttrs are viewBox and size of document.
+func (s SVG) sizeAttrs() []xml.Attr {
+	fill := attr("style", "width: 100%; height: 100%;")
+	b, ok := s.Body.(bounded)
+	if !ok {
+		return []xml.Attr{fill}
+	}
+	x, y, w, h := b.Bounds()
+	view := box{X: x, Y: y, W: w, H: h}.grow(s.Margin)
+	viewBox := attr("viewBox", fmt.Sprintf("%d %d %d %d", view.X, view.Y, view.W, view.H))
+	if s.Fit {
+		return []xml.Attr{viewBox, fill}
+	}
+
+	width, height := s.Width, s.Height
+	switch {
+	case width == 0 && height == 0:
+		width, height = view.W, view.H
+	case height == 0 && view.W > 0:
+		height = width * view.H / view.W
+	case width == 0 && view.H > 0:
+		width = height * view.W / view.H
 	}
-	return strings.Join(
-		[]string{
-			fmt.Sprintf(`<svg id="%s" xmlns="http://www.w3.org/2000/svg" style="width: 100%%; height: 100%%;">`, s.ID),
-			`<defs>`,
-			strings.Join(defs, "\n"),
-			`</defs>`,
-			s.Body.Render(),
-			`</svg>`,
-		},
-		"\n",
-	)
+	return []xml.Attr{viewBox, intAttr("width", width), intAttr("height", height)}
 }
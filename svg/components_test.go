@@ -0,0 +1,160 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// recorder records sizes of writes.
+type recorder struct {
+	strings.Builder
+	writes []int
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.writes = append(r.writes, len(p))
+	return r.Builder.Write(p)
+}
+
+func largeDocument(n int) SVG {
+	g := Graph{ID: "graph", Nodes: map[uint64]Node{}, Edges: map[[2]uint64]Edge{}}
+	for i := 1; i <= n; i++ {
+		g.Nodes[uint64(i)] = Node{ID: fmt.Sprint(i), X: i * 10, Y: i * 20, Title: fmt.Sprintf("node %d", i)}
+		if i > 1 {
+			g.Edges[[2]uint64{uint64(i - 1), uint64(i)}] = Edge{Path: [][2]int{{(i - 1) * 10, (i - 1) * 20}, {i * 10, i * 20}}, Head: ArrowNormal}
+		}
+	}
+	return SVG{ID: "svg", Definitions: g.Markers(), Body: g}
+}
+
+func TestWriteTo(t *testing.T) {
+	doc := largeDocument(2000)
+
+	var r recorder
+	n, err := doc.WriteTo(&r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(r.Len()) {
+		t.Errorf("expected %d written bytes, got %d", r.Len(), n)
+	}
+	parse(t, r.String())
+
+	// document is written in chunks of encoder buffer, not built in memory
+	if len(r.writes) < 10 {
+		t.Errorf("expected many writes, got %d", len(r.writes))
+	}
+	for _, size := range r.writes {
+		if size > 64<<10 {
+			t.Errorf("expected small writes, got write of %d bytes", size)
+		}
+	}
+}
+
+func TestWriteToRender(t *testing.T) {
+	// single node and edge, so order of maps does not change output
+	g := Graph{
+		ID:    "graph",
+		Nodes: map[uint64]Node{1: {ID: "1", Title: "a", NodeData: map[string]interface{}{"k": "v"}}},
+		Edges: map[[2]uint64]Edge{{1, 2}: {Path: [][2]int{{0, 0}, {10, 10}}, Head: ArrowOpen}},
+	}
+	cases := map[string]interface {
+		Renderable
+		io.WriterTo
+	}{
+		"svg":     SVG{ID: "svg", Definitions: g.Markers(), Body: g},
+		"graph":   g,
+		"node":    g.Nodes[1],
+		"edge":    g.Edges[[2]uint64{1, 2}],
+		"cluster": Cluster{ID: "c", W: 10, H: 10, Title: "c"},
+		"marker":  Marker{Arrow: ArrowDiamond},
+	}
+	for name, r := range cases {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			n, err := r.WriteTo(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != r.Render() {
+				t.Errorf("expected output of Render:\n%s\ngot:\n%s", r.Render(), b.String())
+			}
+			if n != int64(b.Len()) {
+				t.Errorf("expected %d written bytes, got %d", b.Len(), n)
+			}
+		})
+	}
+}
+
+// failingWriter fails after limit bytes.
+type failingWriter struct {
+	limit int
+}
+
+var errWrite = errors.New("write failed")
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > f.limit {
+		n := f.limit
+		f.limit = 0
+		return n, errWrite
+	}
+	f.limit -= len(p)
+	return len(p), nil
+}
+
+func TestWriteToError(t *testing.T) {
+	n, err := largeDocument(100).WriteTo(&failingWriter{limit: 5000})
+	if !errors.Is(err, errWrite) {
+		t.Errorf("expected write error, got %v", err)
+	}
+	if n != 5000 {
+		t.Errorf("expected 5000 written bytes, got %d", n)
+	}
+}
+
+func TestViewBox(t *testing.T) {
+	// node at negative coordinates, like from force layouts
+	g := Graph{
+		ID: "graph",
+		Nodes: map[uint64]Node{
+			1: {ID: "1", X: -50, Y: -20, W: 40, H: 20},
+			2: {ID: "2", X: 100, Y: 80, W: 50, H: 20},
+		},
+		Edges: map[[2]uint64]Edge{
+			{1, 2}: {Path: [][2]int{{-30, -10}, {-100, 200}, {125, 90}}},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		svg   SVG
+		attrs string
+	}{
+		{name: "bounds", svg: SVG{}, attrs: `viewBox="-100 -20 250 220" width="250" height="220"`},
+		{name: "margin", svg: SVG{Margin: 10}, attrs: `viewBox="-110 -30 270 240" width="270" height="240"`},
+		{name: "width", svg: SVG{Width: 125}, attrs: `viewBox="-100 -20 250 220" width="125" height="110"`},
+		{name: "height", svg: SVG{Height: 440}, attrs: `viewBox="-100 -20 250 220" width="500" height="440"`},
+		{name: "width and height", svg: SVG{Width: 100, Height: 100}, attrs: `viewBox="-100 -20 250 220" width="100" height="100"`},
+		{name: "fit", svg: SVG{Width: 100, Fit: true}, attrs: `viewBox="-100 -20 250 220" style="width: 100%; height: 100%;"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.svg.ID = "svg"
+			tc.svg.Body = g
+			doc := tc.svg.Render()
+			if expected := `<svg id="svg" xmlns="http://www.w3.org/2000/svg" ` + tc.attrs + `>`; !strings.HasPrefix(doc, expected) {
+				t.Errorf("expected %s, got %s", expected, doc[:strings.Index(doc, "\n")])
+			}
+		})
+	}
+
+	// body without bounds fills container
+	doc := SVG{ID: "svg", Body: Marker{Arrow: ArrowNormal}}.Render()
+	if !strings.HasPrefix(doc, `<svg id="svg" xmlns="http://www.w3.org/2000/svg" style="width: 100%; height: 100%;">`) {
+		t.Errorf("expected svg filling container, got %s", doc[:strings.Index(doc, "\n")])
+	}
+}
@@ -2,18 +2,163 @@ package svg
 
 import (
 	"fmt"
-	"strings"
+	"io"
+	"math"
 )
 
 // Edge is polylines of straight lines going through all points.
+// Head arrow is drawn at last point, Tail arrow at first point.
 type Edge struct {
-	Path [][2]int
+	Path  [][2]int
+	Head  Arrow
+	Tail  Arrow
+	Style Style
+
+	// IDs of end nodes, they are set by graph, so scripts can find edges of nodes
+	from, to string
+	// ID of graph, it is set by graph, so edges reference markers of their graph
+	graph string
 }
 
 func (e Edge) Render() string {
-	var points []string
-	for _, point := range e.Path {
-		points = append(points, fmt.Sprintf("%d,%d", point[0], point[1]))
+	return render(e)
+}
+
+// WriteTo writes same output as Render to w.
+func (e Edge) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, e)
+}
+
+func (e Edge) write(w *writer) {
+	var markerStart, markerEnd string
+	if hasMarker(e.Tail) {
+		markerStart = fmt.Sprintf("url(#%s)", MarkerID(e.graph, e.Tail))
+	}
+	if hasMarker(e.Head) {
+		markerEnd = fmt.Sprintf("url(#%s)", MarkerID(e.graph, e.Head))
+	}
+	w.element("polyline", "", optionalAttrs(
+		attr("class", e.Style.classes("graph-edge")),
+		attr("style", e.Style.svgStyle()),
+		attr("points", points(e.Path...)),
+		attr("data-from", e.from),
+		attr("data-to", e.to),
+		attr("marker-start", markerStart),
+		attr("marker-end", markerEnd),
+	)...)
+}
+
+// box is rectangle with top left corner and size, with outline of shape inside it.
+// Empty shape is rectangle.
+type box struct {
+	X, Y, W, H int
+	Shape      Shape
+}
+
+// union is smallest box that contains both boxes.
+func (b box) union(o box) box {
+	x0, y0 := min(b.X, o.X), min(b.Y, o.Y)
+	x1, y1 := max(b.X+b.W, o.X+o.W), max(b.Y+b.H, o.Y+o.H)
+	return box{X: x0, Y: y0, W: x1 - x0, H: y1 - y0}
+}
+
+// grow adds margin on all sides of box.
+func (b box) grow(margin int) box {
+	return box{X: b.X - margin, Y: b.Y - margin, W: b.W + 2*margin, H: b.H + 2*margin}
+}
+
+func (b box) contains(p [2]int) bool {
+	return b.containsPoint(float64(p[0]), float64(p[1]))
+}
+
+func (b box) containsPoint(x, y float64) bool {
+	if x < float64(b.X) || x > float64(b.X+b.W) || y < float64(b.Y) || y > float64(b.Y+b.H) {
+		return false
+	}
+	rx, ry := float64(b.W)/2, float64(b.H)/2
+	if rx == 0 || ry == 0 {
+		return true
+	}
+	dx, dy := math.Abs(x-float64(b.X)-rx)/rx, math.Abs(y-float64(b.Y)-ry)/ry
+	switch b.Shape {
+	case ShapeEllipse:
+		return dx*dx+dy*dy <= 1
+	case ShapeDiamond:
+		return dx+dy <= 1
+	case ShapeHexagon:
+		return dx <= 1-float64(b.hexagonInset())/rx*dy
+	default:
+		return true
+	}
+}
+
+// border is point where segment from inside point to outside point crosses border of shape.
+func (b box) border(inside, outside [2]int) [2]int {
+	switch b.Shape {
+	case ShapeEllipse, ShapeDiamond, ShapeHexagon:
+		// shapes are convex, so border is found by bisection of segment
+		x0, y0 := float64(inside[0]), float64(inside[1])
+		dx, dy := float64(outside[0])-x0, float64(outside[1])-y0
+		lo, hi := 0.0, 1.0
+		for i := 0; i < 32; i++ {
+			t := (lo + hi) / 2
+			if b.containsPoint(x0+t*dx, y0+t*dy) {
+				lo = t
+			} else {
+				hi = t
+			}
+		}
+		return [2]int{int(math.Round(x0 + lo*dx)), int(math.Round(y0 + lo*dy))}
+	default:
+		return b.rectBorder(inside, outside)
+	}
+}
+
+func (b box) rectBorder(inside, outside [2]int) [2]int {
+	x0, y0 := float64(inside[0]), float64(inside[1])
+	dx, dy := float64(outside[0])-x0, float64(outside[1])-y0
+
+	// smallest fraction of segment that reaches one of sides
+	t := 1.0
+	if dx > 0 {
+		t = math.Min(t, (float64(b.X+b.W)-x0)/dx)
+	} else if dx < 0 {
+		t = math.Min(t, (float64(b.X)-x0)/dx)
+	}
+	if dy > 0 {
+		t = math.Min(t, (float64(b.Y+b.H)-y0)/dy)
+	} else if dy < 0 {
+		t = math.Min(t, (float64(b.Y)-y0)/dy)
+	}
+	return [2]int{int(math.Round(x0 + t*dx)), int(math.Round(y0 + t*dy))}
+}
+
+// clipPath cuts parts of path that are inside boxes of its end nodes, so path starts and ends on their borders.
+// Paths that stay inside boxes are not changed.
+func clipPath(path [][2]int, from, to box) [][2]int {
+	if len(path) < 2 {
+		return path
+	}
+
+	start := 0
+	for start+1 < len(path) && from.contains(path[start+1]) {
+		start++
+	}
+	end := len(path) - 1
+	for end-1 > start && to.contains(path[end-1]) {
+		end--
+	}
+	if start+1 >= len(path) || end <= start || to.contains(path[start]) {
+		return path
+	}
+
+	clipped := append([][2]int(nil), path[start:end+1]...)
+	last := len(clipped) - 1
+	if from.contains(clipped[0]) {
+		clipped[0] = from.border(clipped[0], clipped[1])
+	}
+	if to.contains(clipped[last]) {
+		clipped[last] = to.border(clipped[last], clipped[last-1])
 	}
-	return fmt.Sprintf(`<polyline style="fill:none;stroke-width:1;stroke:black;" points="%s"></polyline>`, strings.Join(points, " "))
+	return clipped
 }
@@ -0,0 +1,89 @@
+package svg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClipPath(t *testing.T) {
+	from := box{X: 0, Y: 0, W: 20, H: 10}
+	to := box{X: 0, Y: 100, W: 20, H: 10}
+
+	tests := []struct {
+		name     string
+		path     [][2]int
+		expected [][2]int
+	}{
+		{"vertical", [][2]int{{10, 5}, {10, 105}}, [][2]int{{10, 10}, {10, 100}}},
+		{"diagonal", [][2]int{{10, 5}, {110, 105}}, [][2]int{{15, 10}, {110, 105}}},
+		{"bends", [][2]int{{10, 5}, {50, 50}, {10, 105}}, [][2]int{{14, 10}, {50, 50}, {14, 100}}},
+		{"bend inside node", [][2]int{{10, 5}, {15, 8}, {15, 105}}, [][2]int{{15, 10}, {15, 100}}},
+		{"overlapping nodes", [][2]int{{10, 5}, {10, 6}}, [][2]int{{10, 5}, {10, 6}}},
+		{"single point", [][2]int{{10, 5}}, [][2]int{{10, 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := to
+			if tt.name == "overlapping nodes" {
+				target = from
+			}
+			if got := clipPath(tt.path, from, target); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEdgeArrows(t *testing.T) {
+	g := Graph{
+		ID: "g",
+		Nodes: map[uint64]Node{
+			1: {ID: "1", X: 0, Y: 0, W: 20, H: 10},
+			2: {ID: "2", X: 0, Y: 100, W: 20, H: 10},
+			3: {ID: "3", X: 100, Y: 100, W: 20, H: 10},
+		},
+		Edges: map[[2]uint64]Edge{
+			{1, 2}: {Path: [][2]int{{10, 5}, {10, 105}}, Head: ArrowNormal, Tail: ArrowDot},
+			{1, 3}: {Path: [][2]int{{10, 5}, {110, 105}}, Head: ArrowNone},
+			{2, 3}: {Path: [][2]int{{10, 105}, {110, 105}}, Head: ArrowDiamond},
+		},
+	}
+
+	var defs []string
+	for _, m := range g.Markers() {
+		defs = append(defs, m.Render())
+	}
+	if len(defs) != 3 ||
+		!strings.HasPrefix(defs[0], `<marker id="svg:g:marker:diamond"`) ||
+		!strings.HasPrefix(defs[1], `<marker id="svg:g:marker:dot"`) ||
+		!strings.HasPrefix(defs[2], `<marker id="svg:g:marker:normal"`) {
+		t.Errorf("unexpected markers:\n%s", strings.Join(defs, "\n"))
+	}
+
+	out := g.Render()
+	for _, s := range []string{
+		`points="10,10 10,100" data-from="1" data-to="2" marker-start="url(#svg:g:marker:dot)" marker-end="url(#svg:g:marker:normal)"`,
+		`points="15,10 105,100" data-from="1" data-to="3"></polyline>`,
+		`points="20,105 100,105" data-from="2" data-to="3" marker-end="url(#svg:g:marker:diamond)"`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %s in:\n%s", s, out)
+		}
+	}
+
+	for _, a := range []Arrow{ArrowNormal, ArrowOpen, ArrowDiamond, ArrowDot} {
+		if !strings.Contains(Marker{Arrow: a}.Render(), `orient="auto-start-reverse"`) {
+			t.Errorf("marker %s is not rendered", a)
+		}
+	}
+	if (Marker{Arrow: ArrowNone}).Render() != "" {
+		t.Error("marker none is rendered")
+	}
+
+	// other graph in same page has its own markers
+	g.ID = "h"
+	if m := g.Markers()[0].Render(); !strings.HasPrefix(m, `<marker id="svg:h:marker:diamond"`) || !strings.Contains(g.Render(), `url(#svg:h:marker:diamond)`) {
+		t.Errorf("markers are not scoped by graph:\n%s", m)
+	}
+}
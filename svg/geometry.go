@@ -0,0 +1,211 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+// curveSegments is number of segments of polylines that approximate full ellipse.
+const curveSegments = 64
+
+// Geometry is node as outlines and positioned text, for renderers of other formats like images and PDF.
+// Curves are approximated by polylines, coordinates are same as coordinates of graph.
+type Geometry struct {
+	Outline    [][2]float64    // closed outline, filled and stroked
+	Lines      [][][2]float64  // open lines stroked like outline, like front of top of cylinder
+	Separators [][2][2]float64 // lines between title and data
+	Text       []TextLine
+	FontSize   int
+}
+
+// TextLine is single line of text. Y is baseline, Anchor is start, middle or end of text at X, like in SVG.
+type TextLine struct {
+	Text   string
+	X, Y   int
+	Anchor string
+	Title  bool
+}
+
+// Geometry is geometry of node, text is placed same way as in SVG shapes.
+// HTML nodes are rounded rectangles with title on top and data rows below it.
+func (n Node) Geometry() Geometry {
+	b := n.box()
+	x0, y0, x1, y1 := float64(b.X), float64(b.Y), float64(b.X+b.W), float64(b.Y+b.H)
+	cx, cy := (x0+x1)/2, (y0+y1)/2
+	rx, ry := float64(b.W)/2, float64(b.H)/2
+
+	g := Geometry{Text: n.textLines(), FontSize: n.fontSize()}
+	switch n.shape() {
+	case ShapeHTML:
+		g.Outline = roundedRect(x0, y0, x1, y1, float64(min(n.theme().NodeRadius, b.H/2)))
+	case ShapeRounded:
+		g.Outline = roundedRect(x0, y0, x1, y1, float64(min(roundedRadius, b.H/2)))
+	case ShapeEllipse:
+		g.Outline = arc(cx, cy, rx, ry, 0, 2*math.Pi)
+	case ShapeDiamond:
+		g.Outline = [][2]float64{{cx, y0}, {x1, cy}, {cx, y1}, {x0, cy}}
+	case ShapeHexagon:
+		inset := float64(b.hexagonInset())
+		g.Outline = [][2]float64{{x0 + inset, y0}, {x1 - inset, y0}, {x1, cy}, {x1 - inset, y1}, {x0 + inset, y1}, {x0, cy}}
+	case ShapeCylinder:
+		capY := float64(n.cylinderCap())
+		// bottom from left to right, then top from right to left
+		g.Outline = append(arc(cx, y1-capY, rx, capY, math.Pi, 0), arc(cx, y0+capY, rx, capY, 0, -math.Pi)...)
+		g.Lines = [][][2]float64{arc(cx, y0+capY, rx, capY, math.Pi, 0)}
+	default:
+		g.Outline = [][2]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+	}
+	if y, ok := n.separator(); ok {
+		g.Separators = [][2][2]float64{{{x0, float64(y)}, {x1, float64(y)}}}
+	}
+	return g
+}
+
+// hasCompartments is true when title and data rows are separated, like in tables of HTML nodes.
+func (n Node) hasCompartments() bool {
+	shape := n.shape()
+	return (shape == ShapeRecord || shape == ShapeHTML) && len(n.dataTable().keys()) > 0
+}
+
+// separator is vertical position of line between title and data.
+func (n Node) separator() (int, bool) {
+	if !n.hasCompartments() {
+		return 0, false
+	}
+	return n.Y + n.fontSize()*textHeightMultiplier + n.theme().nodePadding()/2, true
+}
+
+// textLines are title and data of node. Shapes with compartments have title on top and rows with keys on left and values on right,
+// other shapes have title and rows centered inside outline.
+func (n Node) textLines() []TextLine {
+	b := n.box()
+	keys := n.dataTable().keys()
+	lineHeight := n.fontSize() * textHeightMultiplier
+	cx := b.X + b.W/2
+	padding := n.theme().nodePadding()
+	baseline := func(top int) int {
+		return top + n.fontSize() + n.fontSize()/3
+	}
+
+	if separator, ok := n.separator(); ok {
+		lines := []TextLine{{Text: n.Title, X: cx, Y: baseline(b.Y + padding/4), Anchor: "middle", Title: true}}
+		rowsTop := separator + (b.Y+b.H-separator-len(keys)*lineHeight)/2
+		for i, k := range keys {
+			y := baseline(rowsTop + i*lineHeight)
+			lines = append(lines,
+				TextLine{Text: k, X: b.X + padding/2, Y: y, Anchor: "start"},
+				TextLine{Text: RenderValue(n.NodeData[k]), X: b.X + b.W - padding/2, Y: y, Anchor: "end"},
+			)
+		}
+		return lines
+	}
+
+	// content is centered in area inside outline
+	top, height := b.Y, b.H
+	if n.shape() == ShapeCylinder {
+		top, height = b.Y+2*n.cylinderCap(), b.H-3*n.cylinderCap()
+	}
+	lineTop := top + (height-(1+len(keys))*lineHeight)/2
+	lines := []TextLine{{Text: n.Title, X: cx, Y: baseline(lineTop), Anchor: "middle", Title: true}}
+	for i, k := range keys {
+		lines = append(lines, TextLine{Text: k + ": " + RenderValue(n.NodeData[k]), X: cx, Y: baseline(lineTop + (i+1)*lineHeight), Anchor: "middle"})
+	}
+	return lines
+}
+
+// arc is part of ellipse from angle a0 to angle a1, angles grow clockwise as y axis points down.
+func arc(cx, cy, rx, ry, a0, a1 float64) [][2]float64 {
+	n := int(math.Ceil(math.Abs(a1-a0) / (2 * math.Pi) * curveSegments))
+	n = max(n, 1)
+	points := make([][2]float64, 0, n+1)
+	for i := 0; i <= n; i++ {
+		a := a0 + (a1-a0)*float64(i)/float64(n)
+		points = append(points, [2]float64{cx + rx*math.Cos(a), cy + ry*math.Sin(a)})
+	}
+	return points
+}
+
+func roundedRect(x0, y0, x1, y1, r float64) [][2]float64 {
+	if r <= 0 {
+		return [][2]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+	}
+	var points [][2]float64
+	points = append(points, arc(x1-r, y0+r, r, r, -math.Pi/2, 0)...)
+	points = append(points, arc(x1-r, y1-r, r, r, 0, math.Pi/2)...)
+	points = append(points, arc(x0+r, y1-r, r, r, math.Pi/2, math.Pi)...)
+	points = append(points, arc(x0+r, y0+r, r, r, math.Pi, 3*math.Pi/2)...)
+	return points
+}
+
+// ArrowGeometry is arrow at end of edge, same as marker of arrow.
+type ArrowGeometry struct {
+	Points [][2]float64 // polygon that is filled, or polyline that is stroked when arrow is open
+	Open   bool
+	Width  float64 // stroke width of open arrow
+}
+
+// Arrowhead is geometry of arrow with tip at point, pointing away from previous point of edge.
+// Like markers, arrows are 10 units long and unit is 0.8 of stroke width of edge.
+// It is false for ArrowNone and when points are same.
+func Arrowhead(a Arrow, tip, from [2]float64, strokeWidth float64) (ArrowGeometry, bool) {
+	dx, dy := tip[0]-from[0], tip[1]-from[1]
+	length := math.Hypot(dx, dy)
+	if !hasMarker(a) || length == 0 {
+		return ArrowGeometry{}, false
+	}
+	ux, uy := dx/length, dy/length
+	unit := 0.8 * strokeWidth
+	// point of marker, marker tip is at (10, 5)
+	at := func(x, y float64) [2]float64 {
+		u, v := (x-10)*unit, (y-5)*unit
+		return [2]float64{tip[0] + u*ux - v*uy, tip[1] + u*uy + v*ux}
+	}
+	switch a {
+	case ArrowOpen:
+		return ArrowGeometry{Points: [][2]float64{at(1, 1), at(9, 5), at(1, 9)}, Open: true, Width: 1.5 * unit}, true
+	case ArrowDiamond:
+		return ArrowGeometry{Points: [][2]float64{at(0, 5), at(5, 1), at(10, 5), at(5, 9)}}, true
+	case ArrowDot:
+		center := at(5, 5)
+		return ArrowGeometry{Points: arc(center[0], center[1], 5*unit, 5*unit, 0, 2*math.Pi)}, true
+	default:
+		return ArrowGeometry{Points: [][2]float64{at(0, 0), at(10, 5), at(0, 10)}}, true
+	}
+}
+
+// EdgePath is path of edge, clipped to borders of its nodes like in SVG.
+func (g Graph) EdgePath(e [2]uint64) [][2]int {
+	edge := g.Edges[e]
+	from, okFrom := g.node(e[0])
+	to, okTo := g.node(e[1])
+	if okFrom && okTo {
+		return clipPath(edge.Path, from.box(), to.box())
+	}
+	return edge.Path
+}
+
+// FromLayout is graph with positions and sizes of layout, nodes are titled by their IDs.
+func FromLayout(g layout.Graph) Graph {
+	out := Graph{
+		Nodes:    make(map[uint64]Node, len(g.Nodes)),
+		Edges:    make(map[[2]uint64]Edge, len(g.Edges)),
+		Clusters: make(map[string]Cluster, len(g.Clusters)),
+	}
+	for id, n := range g.Nodes {
+		name := strconv.FormatUint(id, 10)
+		out.Nodes[id] = Node{ID: name, X: n.X, Y: n.Y, W: n.W, H: n.H, Title: name}
+	}
+	for e, edge := range g.Edges {
+		path := make([][2]int, 0, len(edge.Path))
+		for _, p := range edge.Path {
+			path = append(path, [2]int{p.X, p.Y})
+		}
+		out.Edges[e] = Edge{Path: path}
+	}
+	for id, c := range g.Clusters {
+		out.Clusters[id] = Cluster{ID: id, X: c.X, Y: c.Y, W: c.W, H: c.H}
+	}
+	return out
+}
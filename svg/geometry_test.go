@@ -0,0 +1,88 @@
+package svg
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+func TestGeometry(t *testing.T) {
+	for _, shape := range []Shape{ShapeHTML, ShapeRect, ShapeRounded, ShapeEllipse, ShapeDiamond, ShapeHexagon, ShapeCylinder, ShapeRecord} {
+		n := Node{ID: "1", X: 10, Y: 20, Title: "title", NodeData: map[string]interface{}{"a": 1}, Shape: shape}
+		g := n.Geometry()
+		b := n.box()
+		if len(g.Outline) < 3 {
+			t.Errorf("%s: outline should be polygon, got %v", shape, g.Outline)
+		}
+		for _, p := range g.Outline {
+			if p[0] < float64(b.X)-1e-9 || p[0] > float64(b.X+b.W)+1e-9 || p[1] < float64(b.Y)-1e-9 || p[1] > float64(b.Y+b.H)+1e-9 {
+				t.Errorf("%s: point %v of outline is outside of node %+v", shape, p, b)
+			}
+		}
+		if len(g.Text) == 0 || g.Text[0].Text != "title" || !g.Text[0].Title || g.FontSize != nodeFontSize {
+			t.Errorf("%s: unexpected text %+v", shape, g.Text)
+		}
+		if compartments := shape == ShapeHTML || shape == ShapeRecord; compartments != (len(g.Separators) == 1) {
+			t.Errorf("%s: unexpected separators %v", shape, g.Separators)
+		}
+		if (shape == ShapeCylinder) != (len(g.Lines) == 1) {
+			t.Errorf("%s: unexpected lines %v", shape, g.Lines)
+		}
+	}
+}
+
+func TestEdgePath(t *testing.T) {
+	g := Graph{
+		Nodes: map[uint64]Node{
+			1: {X: 0, Y: 0, W: 20, H: 20, Shape: ShapeRect},
+			2: {X: 0, Y: 100, W: 20, H: 20, Shape: ShapeRect},
+		},
+		Edges: map[[2]uint64]Edge{
+			{1, 2}: {Path: [][2]int{{10, 10}, {10, 110}}},
+			{1, 3}: {Path: [][2]int{{10, 10}, {50, 50}}},
+		},
+	}
+	if p := g.EdgePath([2]uint64{1, 2}); len(p) != 2 || p[0] != [2]int{10, 20} || p[1] != [2]int{10, 100} {
+		t.Errorf("path should be clipped to nodes, got %v", p)
+	}
+	if p := g.EdgePath([2]uint64{1, 3}); len(p) != 2 || p[0] != [2]int{10, 10} {
+		t.Errorf("path to missing node should not be clipped, got %v", p)
+	}
+}
+
+func TestFromLayout(t *testing.T) {
+	g := FromLayout(layout.Graph{
+		Nodes:    map[uint64]layout.Node{7: {Position: layout.Position{X: 1, Y: 2}, W: 30, H: 40}},
+		Edges:    map[[2]uint64]layout.Edge{{7, 7}: {Path: []layout.Position{{X: 1, Y: 2}, {X: 3, Y: 4}}}},
+		Clusters: map[string]layout.Cluster{"c": {W: 50, H: 50}},
+	})
+	if n := g.Nodes[7]; n.ID != "7" || n.Title != "7" || n.X != 1 || n.Y != 2 || n.W != 30 || n.H != 40 {
+		t.Errorf("unexpected node %+v", n)
+	}
+	if e := g.Edges[[2]uint64{7, 7}]; len(e.Path) != 2 || e.Path[1] != [2]int{3, 4} {
+		t.Errorf("unexpected edge %+v", e)
+	}
+	if c := g.Clusters["c"]; c.ID != "c" || c.W != 50 {
+		t.Errorf("unexpected cluster %+v", c)
+	}
+}
+
+func TestArrowhead(t *testing.T) {
+	// edge goes right, tip of arrow is at end of edge
+	a, ok := Arrowhead(ArrowNormal, [2]float64{100, 50}, [2]float64{0, 50}, 1)
+	if !ok || a.Open || len(a.Points) != 3 || a.Points[1] != [2]float64{100, 50} || a.Points[0] != [2]float64{92, 46} {
+		t.Errorf("unexpected arrow %+v", a)
+	}
+	if a, ok := Arrowhead(ArrowOpen, [2]float64{0, 0}, [2]float64{0, -10}, 2); !ok || !a.Open || math.Abs(a.Width-2.4) > 1e-9 {
+		t.Errorf("unexpected open arrow %+v", a)
+	}
+	for _, arrow := range []Arrow{"", ArrowNone} {
+		if _, ok := Arrowhead(arrow, [2]float64{1, 1}, [2]float64{0, 0}, 1); ok {
+			t.Errorf("%q: expected no arrow", arrow)
+		}
+	}
+	if _, ok := Arrowhead(ArrowNormal, [2]float64{1, 1}, [2]float64{1, 1}, 1); ok {
+		t.Errorf("expected no arrow without direction")
+	}
+}
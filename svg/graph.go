@@ -1,33 +1,187 @@
 package svg
 
 import (
-	"fmt"
-	"strings"
+	"io"
+	"sort"
 )
 
 // Graph is rendered graph.
+// Elements are drawn in stable order, so same graph is always same document:
+// nodes in NodeOrder and edges in EdgeOrder first, then rest of them sorted by node ID and edge endpoints.
+// Clusters are sorted by ID.
+// Theme and Measurer size nodes and clusters, theme should be same as theme of SVG or renderer.
 type Graph struct {
-	ID    string
-	Nodes map[uint64]Node
-	Edges map[[2]uint64]Edge
+	ID        string
+	Nodes     map[uint64]Node
+	Edges     map[[2]uint64]Edge
+	Clusters  map[string]Cluster
+	NodeOrder []uint64     // optional order of nodes, like order of input
+	EdgeOrder [][2]uint64  // optional order of edges
+	Theme     *Theme       // DefaultTheme when nil
+	Measurer  TextMeasurer // DefaultMeasurer when nil
 }
 
-// Render creates root svg element
+// look is theme and measurer that graph gives to its nodes and clusters.
+type look struct {
+	theme    *Theme
+	measurer TextMeasurer
+}
+
+// node is node with theme and measurer of graph.
+func (g Graph) node(id uint64) (Node, bool) {
+	n, ok := g.Nodes[id]
+	n.look = look{theme: g.Theme, measurer: g.Measurer}
+	return n, ok
+}
+
+func (g Graph) cluster(id string) Cluster {
+	c := g.Clusters[id]
+	c.look = look{theme: g.Theme, measurer: g.Measurer}
+	return c
+}
+
+// NodeSize is size of node with theme and measurer of graph, node does not have to be in graph.
+func (g Graph) NodeSize(n Node) (w, h int) {
+	n.look = look{theme: g.Theme, measurer: g.Measurer}
+	return n.Size()
+}
+
+// Render creates root svg element.
+// Edges are clipped to borders of their nodes, so their arrows are visible.
 func (g Graph) Render() string {
-	body := []string{
-		fmt.Sprintf(`<g id="%s">`, g.ID),
+	return render(g)
+}
+
+// WriteTo writes same output as Render to w.
+func (g Graph) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, g)
+}
+
+func (g Graph) write(w *writer) {
+	w.start("g", attr("id", g.ID))
+	g.Walk(elementWriter{w: w})
+	w.end("g")
+}
+
+// Visitor draws parts of graph that Graph.Walk visits.
+type Visitor interface {
+	VisitCluster(c Cluster)
+	VisitEdge(e Edge)
+	VisitNode(n Node)
+}
+
+// Walk visits parts of graph in order of drawing, same for all renderers:
+// clusters below edges and nodes, then edges with their paths, then nodes always on top of edges.
+func (g Graph) Walk(v Visitor) {
+	for _, id := range g.clusterIDs() {
+		v.VisitCluster(g.cluster(id))
+	}
+
+	for _, e := range g.edgeKeys() {
+		edge := g.Edges[e]
+		edge.Path = g.EdgePath(e)
+		edge.from, edge.to = g.Nodes[e[0]].ID, g.Nodes[e[1]].ID
+		edge.graph = g.ID
+		v.VisitEdge(edge)
 	}
 
+	for _, id := range g.nodeIDs() {
+		n, _ := g.node(id)
+		v.VisitNode(n)
+	}
+}
+
+// elementWriter writes parts of graph as SVG elements.
+type elementWriter struct {
+	w *writer
+}
+
+func (v elementWriter) VisitCluster(c Cluster) { c.write(v.w) }
+
+func (v elementWriter) VisitEdge(e Edge) { e.write(v.w) }
+
+func (v elementWriter) VisitNode(n Node) { n.write(v.w) }
+
+// Bounds is box around nodes, edge paths and clusters of graph, it is empty for empty graph.
+func (g Graph) Bounds() (x, y, w, h int) {
+	var (
+		b     box
+		empty = true
+	)
+	add := func(o box) {
+		if empty {
+			b, empty = o, false
+			return
+		}
+		b = b.union(o)
+	}
+	for id := range g.Nodes {
+		n, _ := g.node(id)
+		add(n.box())
+	}
 	for _, edge := range g.Edges {
-		body = append(body, edge.Render())
+		for _, p := range edge.Path {
+			add(box{X: p[0], Y: p[1]})
+		}
+	}
+	for _, c := range g.Clusters {
+		add(box{X: c.X, Y: c.Y, W: c.W, H: c.H})
 	}
+	return b.X, b.Y, b.W, b.H
+}
 
-	// draw nodes always on top of edges
-	for _, node := range g.Nodes {
-		body = append(body, node.Render())
+// nodeIDs are IDs of nodes in order of drawing.
+func (g Graph) nodeIDs() []uint64 {
+	ids := make([]uint64, 0, len(g.Nodes))
+	seen := make(map[uint64]bool, len(g.NodeOrder))
+	for _, id := range g.NodeOrder {
+		if _, ok := g.Nodes[id]; ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
 	}
+	rest := len(ids)
+	for id := range g.Nodes {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
+	}
+	sorted := ids[rest:]
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return ids
+}
 
-	body = append(body, "</g>")
+// edgeKeys are edges in order of drawing.
+func (g Graph) edgeKeys() [][2]uint64 {
+	keys := make([][2]uint64, 0, len(g.Edges))
+	seen := make(map[[2]uint64]bool, len(g.EdgeOrder))
+	for _, e := range g.EdgeOrder {
+		if _, ok := g.Edges[e]; ok && !seen[e] {
+			seen[e] = true
+			keys = append(keys, e)
+		}
+	}
+	rest := len(keys)
+	for e := range g.Edges {
+		if !seen[e] {
+			keys = append(keys, e)
+		}
+	}
+	sorted := keys[rest:]
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i][0] != sorted[j][0] {
+			return sorted[i][0] < sorted[j][0]
+		}
+		return sorted[i][1] < sorted[j][1]
+	})
+	return keys
+}
 
-	return strings.Join(body, "\n")
+func (g Graph) clusterIDs() []string {
+	ids := make([]string, 0, len(g.Clusters))
+	for id := range g.Clusters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
 }
@@ -0,0 +1,108 @@
+package svg
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// golden compares document with file in testdata byte for byte.
+func golden(t *testing.T, name, doc string) {
+	t.Helper()
+	path := "testdata/" + name
+	if *update {
+		if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(expected) != doc {
+		t.Errorf("%s does not match, run tests with -update if change is expected\ngot:\n%s", path, doc)
+	}
+}
+
+func testGraph() Graph {
+	return Graph{
+		ID: "graph",
+		Nodes: map[uint64]Node{
+			1:  {ID: "1", X: 0, Y: 0, Title: "a", NodeData: map[string]interface{}{"b": 2, "a": 1, "id": "a"}},
+			2:  {ID: "2", X: 100, Y: 100, Title: "b"},
+			3:  {ID: "3", X: 0, Y: 200, Title: "c"},
+			10: {ID: "10", X: 200, Y: 200, Title: "d", Style: Style{Fill: "yellow"}},
+		},
+		Edges: map[[2]uint64]Edge{
+			{1, 2}:  {Path: [][2]int{{10, 10}, {110, 110}}, Head: ArrowNormal},
+			{1, 3}:  {Path: [][2]int{{10, 10}, {10, 210}}, Head: ArrowNormal},
+			{2, 10}: {Path: [][2]int{{110, 110}, {210, 210}}, Head: ArrowOpen, Style: Style{Dash: "5 3"}},
+			{3, 10}: {Path: [][2]int{{10, 210}, {210, 210}}},
+		},
+		Clusters: map[string]Cluster{
+			"b": {ID: "b", X: -10, Y: 190, W: 250, H: 50, Title: "bottom"},
+			"a": {ID: "a", X: -10, Y: -10, W: 150, H: 150, Title: "top"},
+		},
+	}
+}
+
+func TestGraphGolden(t *testing.T) {
+	ordered := testGraph()
+	ordered.NodeOrder = []uint64{10, 3}
+	ordered.EdgeOrder = [][2]uint64{{3, 10}, {2, 10}, {4, 5}}
+
+	tests := map[string]Graph{
+		"graph.svg":         testGraph(),
+		"graph_ordered.svg": ordered,
+	}
+	for name, g := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc := SVG{ID: "svg", Definitions: g.Markers(), Body: g, Margin: 10}.Render()
+			// maps are iterated in random order, so many renders are compared
+			for i := 0; i < 10; i++ {
+				if again := (SVG{ID: "svg", Definitions: g.Markers(), Body: g, Margin: 10}).Render(); again != doc {
+					t.Fatalf("render is not stable:\n%s\n\n%s", doc, again)
+				}
+			}
+			golden(t, name, doc)
+		})
+	}
+}
+
+// walkOrder records parts of graph in order of visits.
+type walkOrder []string
+
+func (w *walkOrder) VisitCluster(c Cluster) { *w = append(*w, "cluster "+c.ID) }
+
+func (w *walkOrder) VisitEdge(e Edge) { *w = append(*w, fmt.Sprintf("edge %v", e.Path)) }
+
+func (w *walkOrder) VisitNode(n Node) { *w = append(*w, "node "+n.ID) }
+
+func TestGraphWalk(t *testing.T) {
+	g := Graph{
+		Nodes: map[uint64]Node{
+			1: {ID: "a", W: 10, H: 10},
+			2: {ID: "b", Y: 50, W: 10, H: 10},
+			3: {ID: "c", X: 50, W: 10, H: 10},
+		},
+		Edges: map[[2]uint64]Edge{
+			{1, 2}: {Path: [][2]int{{5, 5}, {5, 55}}},
+			{1, 3}: {Path: [][2]int{{5, 5}, {55, 5}}},
+		},
+		Clusters:  map[string]Cluster{"y": {ID: "y"}, "x": {ID: "x"}},
+		NodeOrder: []uint64{3},
+		EdgeOrder: [][2]uint64{{1, 3}},
+	}
+
+	var order walkOrder
+	g.Walk(&order)
+	expected := "cluster x|cluster y|edge [[10 5] [50 5]]|edge [[5 10] [5 50]]|node c|node a|node b"
+	if got := strings.Join(order, "|"); got != expected {
+		t.Errorf("expected order %s, got %s", expected, got)
+	}
+}
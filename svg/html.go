@@ -0,0 +1,67 @@
+package svg
+
+import (
+	_ "embed"
+	"html"
+	"io"
+	"strings"
+)
+
+//go:embed viewer.css
+var viewerCSS string
+
+//go:embed viewer.js
+var viewerJS string
+
+// HTML is self-contained page with SVG and viewer: pan and zoom with mouse, highlight of edges of hovered node,
+// focus on neighbors of clicked node and search of nodes by text. Page has no external assets.
+// SVG fills page, so its Fit is always set.
+type HTML struct {
+	Title string
+	SVG   SVG
+}
+
+func (h HTML) Render() string {
+	var b strings.Builder
+	h.WriteTo(&b)
+	return b.String()
+}
+
+// WriteTo writes page to w, SVG is streamed.
+func (h HTML) WriteTo(w io.Writer) (int64, error) {
+	c := &countingWriter{w: w}
+	if _, err := io.WriteString(c, `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>`+html.EscapeString(h.Title)+`</title>
+<style>
+`+viewerCSS+`</style>
+</head>
+<body>
+<div class="graph-toolbar">
+<input id="graph-search" type="search" placeholder="Search nodes" autocomplete="off">
+<button id="graph-reset" type="button" title="Reset zoom and focus">Reset</button>
+</div>
+<div id="graph-viewport">
+`); err != nil {
+		return c.n, err
+	}
+
+	s := h.SVG
+	s.Fit = true
+	if _, err := s.WriteTo(c); err != nil {
+		return c.n, err
+	}
+
+	// script is written as is, it has no closing script tag
+	_, err := io.WriteString(c, `
+</div>
+<script>
+`+viewerJS+`</script>
+</body>
+</html>
+`)
+	return c.n, err
+}
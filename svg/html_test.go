@@ -0,0 +1,49 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	g := testGraph()
+	page := HTML{Title: "<graph>", SVG: SVG{ID: "svg", Definitions: g.Markers(), Body: g}}
+
+	var b strings.Builder
+	n, err := page.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := b.String()
+	if n != int64(len(doc)) {
+		t.Errorf("expected %d written bytes, got %d", len(doc), n)
+	}
+	if doc != page.Render() {
+		t.Errorf("expected output of Render")
+	}
+
+	for _, s := range []string{
+		"<!DOCTYPE html>",
+		"<title>&lt;graph&gt;</title>",
+		`<svg id="svg" xmlns="http://www.w3.org/2000/svg" viewBox="-10 -10 250 250" style="width: 100%; height: 100%;">`,
+		`<g class="graph-node" data-node="10">`,
+		`data-from="2" data-to="10"`,
+		`id="graph-search"`,
+		"<script>\n// Viewer of graph",
+	} {
+		if !strings.Contains(doc, s) {
+			t.Errorf("expected %s in page:\n%s", s, doc)
+		}
+	}
+
+	// page has no external assets, script is not closed early
+	withoutNamespaces := strings.NewReplacer(namespaceSVG, "", namespaceXHTML, "").Replace(doc)
+	for _, s := range []string{"src=", "http://", "https://", "@import"} {
+		if strings.Contains(withoutNamespaces, s) {
+			t.Errorf("unexpected external asset %s in page", s)
+		}
+	}
+	if strings.Count(doc, "</script") != 1 || strings.Contains(viewerJS, "</") {
+		t.Errorf("expected single closing script tag")
+	}
+}
@@ -0,0 +1,97 @@
+package svg
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Arrow is shape drawn at end of edge. Empty arrow is same as ArrowNone.
+type Arrow string
+
+const (
+	ArrowNone    Arrow = "none"
+	ArrowNormal  Arrow = "normal"  // filled triangle
+	ArrowOpen    Arrow = "open"    // triangle outline
+	ArrowDiamond Arrow = "diamond" // filled diamond
+	ArrowDot     Arrow = "dot"     // filled circle
+)
+
+// Marker is definition of arrow that edges of graph reference, it should be in SVG.Definitions.
+// Arrow tip is at end of edge, arrows at start of edge are reversed.
+type Marker struct {
+	GraphID string
+	Arrow   Arrow
+}
+
+// MarkerID is DOM ID of marker of arrow of graph, so graphs in same page do not share markers.
+func MarkerID(graphID string, a Arrow) string {
+	if graphID == "" {
+		return fmt.Sprintf("svg:marker:%s", a)
+	}
+	return fmt.Sprintf("svg:%s:marker:%s", graphID, a)
+}
+
+func (m Marker) Render() string {
+	return render(m)
+}
+
+// WriteTo writes same output as Render to w.
+func (m Marker) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, m)
+}
+
+func (m Marker) write(w *writer) {
+	if !hasMarker(m.Arrow) {
+		return
+	}
+	// size is in stroke widths, so arrows grow with edges
+	w.start("marker",
+		attr("id", MarkerID(m.GraphID, m.Arrow)),
+		attr("viewBox", "0 0 10 10"),
+		attr("refX", "10"),
+		attr("refY", "5"),
+		attr("markerWidth", "8"),
+		attr("markerHeight", "8"),
+		attr("orient", "auto-start-reverse"),
+	)
+	switch m.Arrow {
+	case ArrowOpen:
+		w.element("path", "", attr("class", "graph-arrow-open"), attr("d", "M 1 1 L 9 5 L 1 9"))
+	case ArrowDiamond:
+		w.element("path", "", attr("class", "graph-arrow"), attr("d", "M 0 5 L 5 1 L 10 5 L 5 9 z"))
+	case ArrowDot:
+		w.element("circle", "", attr("class", "graph-arrow"), attr("cx", "5"), attr("cy", "5"), attr("r", "5"))
+	default:
+		w.element("path", "", attr("class", "graph-arrow"), attr("d", "M 0 0 L 10 5 L 0 10 z"))
+	}
+	w.end("marker")
+}
+
+func hasMarker(a Arrow) bool {
+	return a != "" && a != ArrowNone
+}
+
+// Markers are definitions of arrows used by edges of graph, ordered by arrow name.
+func (g Graph) Markers() []Renderable {
+	used := make(map[Arrow]bool)
+	for _, e := range g.Edges {
+		for _, a := range []Arrow{e.Head, e.Tail} {
+			if hasMarker(a) {
+				used[a] = true
+			}
+		}
+	}
+
+	arrows := make([]string, 0, len(used))
+	for a := range used {
+		arrows = append(arrows, string(a))
+	}
+	sort.Strings(arrows)
+
+	markers := make([]Renderable, 0, len(arrows))
+	for _, a := range arrows {
+		markers = append(markers, Marker{GraphID: g.ID, Arrow: Arrow(a)})
+	}
+	return markers
+}
@@ -2,65 +2,121 @@ package svg
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 )
 
 const (
-	nodeFontSize         int     = 9
-	padding              int     = 10
-	textHeightMultiplier int     = 2
-	textWidthMultiplier  float64 = 0.8
+	nodeFontSize         int = 9
+	textHeightMultiplier int = 2
+	cellPadding          int = 1
 )
 
 // Node is rendered point.
 // Can render contents as table.
+// Box has size W and H when they are set, otherwise it fits title and data as measured by measurer of graph.
+// Shape is outline of node, nodes are HTML boxes by default.
+// Padding and font size are from theme of graph, node alone has DefaultTheme and DefaultMeasurer.
 type Node struct {
 	ID       string // used to make DOM IDs
-	X int
-	Y int
+	X        int
+	Y        int
+	W        int
+	H        int
 	Title    string
 	NodeData map[string]interface{}
+	Style    Style
+	Shape    Shape
+	look     look // set by graph
 }
 
 func (n Node) TitleID() string {
 	return fmt.Sprintf("svg:graph:node:title:%s", n.ID)
 }
 
+func (n Node) theme() Theme {
+	if n.look.theme != nil {
+		return *n.look.theme
+	}
+	return DefaultTheme
+}
+
+func (n Node) fontSize() int {
+	return n.Style.fontSizeIn(n.theme())
+}
+
+func (n Node) measurer() TextMeasurer {
+	if n.look.measurer != nil {
+		return n.look.measurer
+	}
+	return DefaultMeasurer
+}
+
+func (n Node) dataTable() NodeDataTable {
+	return NodeDataTable{NodeData: n.NodeData, FontSize: n.fontSize(), Measurer: n.measurer()}
+}
+
 func (n Node) Render() string {
-	body := ""
+	return render(n)
+}
+
+// WriteTo writes same output as Render to w.
+func (n Node) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, n)
+}
+
+func (n Node) write(w *writer) {
+	if n.shape() != ShapeHTML {
+		n.writeShape(w)
+		return
+	}
+	b := n.box()
+	w.start("g", attr("class", n.Style.classes("graph-node")), attr("data-node", n.ID))
+	// https://developer.mozilla.org/en-US/docs/Web/SVG/Element/foreignObject
+	w.start("foreignObject", intAttr("x", b.X), intAttr("y", b.Y), intAttr("width", b.W), intAttr("height", b.H))
+	w.start("div", append(
+		[]xml.Attr{attr("xmlns", namespaceXHTML), attr("class", "graph-node-box unselectable")},
+		optionalAttrs(attr("style", n.Style.htmlStyle()))...,
+	)...)
+	NodeTitle{ID: n.TitleID(), Title: n.Title, FontSize: n.fontSize()}.write(w)
 	if len(n.NodeData) > 0 {
-		body = NodeDataTable{NodeData: n.NodeData, FontSize: nodeFontSize}.Render()
+		n.dataTable().write(w)
 	}
+	w.end("div")
+	w.end("foreignObject")
+	w.end("g")
+}
 
-	// https://developer.mozilla.org/en-US/docs/Web/SVG/Element/foreignObject
-	return fmt.Sprintf(`
-		<g>
-			<foreignObject x="%d" y="%d" width="%d" height="%d">
-				<div xmlns="http://www.w3.org/1999/xhtml" class="unselectable" style="overflow: hidden; background: white; border: 1px solid lightgray; border-radius: 5px;">
-					%s
-					%s
-				</div>
-			</foreignObject>
-		</g>
-		`,
-		n.X,
-		n.Y,
-		n.Width()+padding,
-		n.Height()+padding,
-		NodeTitle{ID: fmt.Sprintf("svg:graph:node:title:%s", n.ID), Title: n.Title, FontSize: nodeFontSize}.Render(),
-		body,
-	)
+// Size is size of rendered box of node, shapes like ellipse are larger than their content.
+func (n Node) Size() (w, h int) {
+	w, h = n.W, n.H
+	padding := n.theme().nodePadding()
+	sw, sh := n.shapeSize(n.Width()+padding, n.Height()+padding)
+	if w == 0 {
+		w = sw
+	}
+	if h == 0 {
+		h = sh
+	}
+	return w, h
 }
 
+func (n Node) box() box {
+	w, h := n.Size()
+	return box{X: n.X, Y: n.Y, W: w, H: h, Shape: n.shape()}
+}
+
+// Width is width of title or data, without padding.
 func (n Node) Width() int {
-	w := int(float64(nodeFontSize*len(n.Title)) * textWidthMultiplier)
+	w := n.measurer().TextWidth(n.Title, n.fontSize())
 	if len(n.NodeData) == 0 {
 		return w
 	}
 
-	nd := NodeDataTable{NodeData: n.NodeData, FontSize: nodeFontSize}
+	nd := n.dataTable()
 	if nd.Width() > w {
 		w = nd.Width()
 	}
@@ -68,13 +124,12 @@ func (n Node) Width() int {
 }
 
 func (n Node) Height() int {
-	titleHeight := nodeFontSize * textHeightMultiplier
+	titleHeight := n.fontSize() * textHeightMultiplier
 	if len(n.NodeData) == 0 {
 		return titleHeight
 	}
 
-	nd := NodeDataTable{NodeData: n.NodeData, FontSize: nodeFontSize}
-	return titleHeight + nd.Height()
+	return titleHeight + n.dataTable().Height()
 }
 
 type NodeTitle struct {
@@ -84,13 +139,19 @@ type NodeTitle struct {
 }
 
 func (n NodeTitle) Render() string {
-	return fmt.Sprintf(`
-		<div id="%s" style="font-size: %dpx; text-align: center; padding: 4px; cursor: pointer;">
-			%s
-		</div>`,
-		n.ID,
-		n.FontSize,
-		n.Title,
+	return render(n)
+}
+
+// WriteTo writes same output as Render to w.
+func (n NodeTitle) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, n)
+}
+
+func (n NodeTitle) write(w *writer) {
+	w.element("div", n.Title,
+		attr("id", n.ID),
+		attr("class", "graph-node-title"),
+		attr("style", fmt.Sprintf("font-size: %dpx;", n.FontSize)),
 	)
 }
 
@@ -99,66 +160,65 @@ func (n NodeTitle) Render() string {
 type NodeDataTable struct {
 	NodeData map[string]interface{}
 	FontSize int
+	Measurer TextMeasurer // DefaultMeasurer when nil
 }
 
+// Width is width of widest row, with padding of cells and space of one em between key and value.
 func (n NodeDataTable) Width() int {
-	maxlen := 0
-	for k, v := range n.NodeData {
-		if k == "id" || strings.HasSuffix(k, "_url") {
-			continue
-		}
-		currLen := len(k) + len(RenderValue(v))
-		if currLen > maxlen {
-			maxlen = currLen
+	m := n.Measurer
+	if m == nil {
+		m = DefaultMeasurer
+	}
+	maxWidth := 0
+	for _, k := range n.keys() {
+		if w := m.TextWidth(k, n.FontSize) + m.TextWidth(RenderValue(n.NodeData[k]), n.FontSize); w > maxWidth {
+			maxWidth = w
 		}
 	}
-	return int(float64(nodeFontSize*maxlen) * textWidthMultiplier)
+	if maxWidth == 0 {
+		return 0
+	}
+	return maxWidth + 4*cellPadding + n.FontSize
 }
 
 func (n NodeDataTable) Height() int {
-	nrows := 0
+	return n.FontSize * len(n.keys()) * textHeightMultiplier
+}
+
+// keys are sorted keys of rows, id and links are not shown.
+func (n NodeDataTable) keys() []string {
+	keys := make([]string, 0, len(n.NodeData))
 	for k := range n.NodeData {
 		if k == "id" || strings.HasSuffix(k, "_url") {
 			continue
 		}
-		nrows++
+		keys = append(keys, k)
 	}
-	return nodeFontSize * nrows * textHeightMultiplier
+	sort.Strings(keys)
+	return keys
 }
 
 func (n NodeDataTable) Render() string {
-	rows := []string{}
-
-	for k, v := range n.NodeData {
-		if k == "id" || strings.HasSuffix(k, "_url") {
-			continue
-		}
+	return render(n)
+}
 
-		row := fmt.Sprintf(`
-			<tr>
-				<td border="1" align="left">%s</td>
-				<td border="1" align="right">%s</td>
-			</tr>`,
-			k,
-			RenderValue(v),
-		)
+// WriteTo writes same output as Render to w.
+func (n NodeDataTable) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, n)
+}
 
-		rows = append(rows, row)
+func (n NodeDataTable) write(w *writer) {
+	keys := n.keys()
+	w.start("div", attr("class", "graph-node-data"), attr("style", fmt.Sprintf("font-size: %dpx;", n.FontSize)))
+	w.start("table", attr("border", "0"), attr("cellspacing", "0"), intAttr("cellpadding", cellPadding), attr("style", "width: 100%;"))
+	for _, k := range keys {
+		w.start("tr")
+		w.element("td", k, attr("border", "1"), attr("align", "left"))
+		w.element("td", RenderValue(n.NodeData[k]), attr("border", "1"), attr("align", "right"))
+		w.end("tr")
 	}
-
-	// sort by key, since key is first
-	sort.Strings(rows)
-
-	return fmt.Sprintf(
-		`<div style="font-size: %dpx; padding: 0px 4px 4px 4px; border-top: 1px solid lightgrey;">
-			<table border="0" cellspacing="0" cellpadding="1" style="width: 100%%;">
-			%s
-			</table>
-		</div>
-		`,
-		n.FontSize,
-		strings.Join(rows, "\n"),
-	)
+	w.end("table")
+	w.end("div")
 }
 
 // RenderValue coerces to json.Number and tries to avoid adding decimal points to integers
@@ -0,0 +1,54 @@
+package svg
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in    string
+		color color.NRGBA
+		ok    bool
+	}{
+		{"black", color.NRGBA{A: 0xff}, true},
+		{" LightGray ", color.NRGBA{R: 0xd3, G: 0xd3, B: 0xd3, A: 0xff}, true},
+		{"#f80", color.NRGBA{R: 0xff, G: 0x88, A: 0xff}, true},
+		{"#1e1e1e", color.NRGBA{R: 0x1e, G: 0x1e, B: 0x1e, A: 0xff}, true},
+		{"#ff000080", color.NRGBA{R: 0xff, A: 0x80}, true},
+		{"rgb(1, 2, 3)", color.NRGBA{R: 1, G: 2, B: 3, A: 0xff}, true},
+		{"none", color.NRGBA{}, false},
+		{"transparent", color.NRGBA{}, false},
+		{"", color.NRGBA{}, false},
+		{"#12", color.NRGBA{}, false},
+		{"#gggggg", color.NRGBA{}, false},
+		{"rgb(1, 2)", color.NRGBA{}, false},
+		{"nocolor", color.NRGBA{}, false},
+	}
+	for _, tc := range tests {
+		c, ok := ParseColor(tc.in)
+		if c != tc.color || ok != tc.ok {
+			t.Errorf("%q: expected %v %v, got %v %v", tc.in, tc.color, tc.ok, c, ok)
+		}
+	}
+}
+
+func TestPaint(t *testing.T) {
+	p := DefaultTheme.NodePaint(Style{Fill: "yellow", Dash: "5 3", FontSize: 20})
+	expected := Paint{Fill: "yellow", Stroke: "lightgray", StrokeWidth: 1, Dash: []float64{5, 3}, FontFamily: "Go, sans-serif", FontSize: 20, FontColor: "black"}
+	if !reflect.DeepEqual(p, expected) {
+		t.Errorf("expected %+v, got %+v", expected, p)
+	}
+
+	e := DarkTheme.EdgePaint(Style{StrokeWidth: 3})
+	if e.Fill != "" || e.Stroke != DarkTheme.EdgeStroke || e.StrokeWidth != 3 || e.FontSize != nodeFontSize {
+		t.Errorf("unexpected edge paint %+v", e)
+	}
+
+	for s, dash := range map[string][]float64{"": nil, "5 3": {5, 3}, "1,2,3": {1, 2, 3, 1, 2, 3}, "5 x": nil, "-1 2": nil} {
+		if got := parseDash(s); !reflect.DeepEqual(got, dash) {
+			t.Errorf("%q: expected dash %v, got %v", s, dash, got)
+		}
+	}
+}
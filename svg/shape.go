@@ -0,0 +1,131 @@
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Shape is outline of node. Empty shape is ShapeHTML.
+// Nodes with ShapeHTML are HTML boxes in foreignObject, all other shapes are pure SVG
+// with title and data in <text>, so they are shown by viewers that do not support HTML in SVG.
+type Shape string
+
+const (
+	ShapeHTML     Shape = "html"     // HTML box with title and data table
+	ShapeRect     Shape = "rect"     // rectangle
+	ShapeRounded  Shape = "rounded"  // rectangle with rounded corners
+	ShapeEllipse  Shape = "ellipse"  // ellipse around title and data
+	ShapeDiamond  Shape = "diamond"  // diamond around title and data
+	ShapeHexagon  Shape = "hexagon"  // hexagon with corners on left and right
+	ShapeCylinder Shape = "cylinder" // database cylinder
+	ShapeRecord   Shape = "record"   // rectangle with title and data rows in compartments
+)
+
+const roundedRadius = 8
+
+func (n Node) shape() Shape {
+	if n.Shape == "" {
+		return ShapeHTML
+	}
+	return n.Shape
+}
+
+// shapeSize is size of shape around content of size w and h.
+func (n Node) shapeSize(w, h int) (int, int) {
+	switch n.shape() {
+	case ShapeEllipse:
+		return int(math.Ceil(float64(w) * math.Sqrt2)), int(math.Ceil(float64(h) * math.Sqrt2))
+	case ShapeDiamond:
+		return 2 * w, 2 * h
+	case ShapeHexagon:
+		return w + h/2, h
+	case ShapeCylinder:
+		return w, h + 3*n.cylinderCap()
+	default:
+		return w, h
+	}
+}
+
+// cylinderCap is vertical radius of ellipses at top and bottom of cylinder.
+func (n Node) cylinderCap() int {
+	return n.fontSize() / 2
+}
+
+// writeShape writes node as SVG shape with title and data in <text>.
+func (n Node) writeShape(w *writer) {
+	b := n.box()
+	shape := n.shape()
+	style := n.Style.svgStyle()
+	shapeAttrs := func(attrs ...xml.Attr) []xml.Attr {
+		return optionalAttrs(append([]xml.Attr{attr("class", "graph-node-shape"), attr("style", style)}, attrs...)...)
+	}
+	cx, cy := b.X+b.W/2, b.Y+b.H/2
+
+	w.start("g", attr("class", n.Style.classes("graph-node graph-node-"+string(shape))), attr("data-node", n.ID))
+
+	switch shape {
+	case ShapeRounded:
+		r := min(roundedRadius, b.H/2)
+		w.element("rect", "", shapeAttrs(intAttr("x", b.X), intAttr("y", b.Y), intAttr("width", b.W), intAttr("height", b.H), intAttr("rx", r), intAttr("ry", r))...)
+	case ShapeEllipse:
+		w.element("ellipse", "", shapeAttrs(intAttr("cx", cx), intAttr("cy", cy), intAttr("rx", b.W/2), intAttr("ry", b.H/2))...)
+	case ShapeDiamond:
+		w.element("polygon", "", shapeAttrs(attr("points", points([2]int{cx, b.Y}, [2]int{b.X + b.W, cy}, [2]int{cx, b.Y + b.H}, [2]int{b.X, cy})))...)
+	case ShapeHexagon:
+		inset := b.hexagonInset()
+		w.element("polygon", "", shapeAttrs(attr("points", points(
+			[2]int{b.X + inset, b.Y}, [2]int{b.X + b.W - inset, b.Y}, [2]int{b.X + b.W, cy},
+			[2]int{b.X + b.W - inset, b.Y + b.H}, [2]int{b.X + inset, b.Y + b.H}, [2]int{b.X, cy},
+		)))...)
+	case ShapeCylinder:
+		ry := n.cylinderCap()
+		rx := b.W / 2
+		left, right := b.X, b.X+b.W
+		w.element("path", "", shapeAttrs(attr("d", fmt.Sprintf("M %d %d V %d A %d %d 0 0 0 %d %d V %d A %d %d 0 0 0 %d %d Z",
+			left, b.Y+ry, b.Y+b.H-ry, rx, ry, right, b.Y+b.H-ry, b.Y+ry, rx, ry, left, b.Y+ry)))...)
+		// front of top ellipse
+		w.element("path", "", optionalAttrs(
+			attr("class", "graph-node-shape"),
+			attr("style", style+"fill:none;"),
+			attr("d", fmt.Sprintf("M %d %d A %d %d 0 0 0 %d %d", left, b.Y+ry, rx, ry, right, b.Y+ry)),
+		)...)
+	default:
+		w.element("rect", "", shapeAttrs(intAttr("x", b.X), intAttr("y", b.Y), intAttr("width", b.W), intAttr("height", b.H))...)
+	}
+
+	if y, ok := n.separator(); ok {
+		w.element("line", "", attr("class", "graph-node-separator"), intAttr("x1", b.X), intAttr("y1", y), intAttr("x2", b.X+b.W), intAttr("y2", y))
+	}
+	w.start("text", n.textAttrs()...)
+	for _, line := range n.textLines() {
+		attrs := []xml.Attr{intAttr("x", line.X), intAttr("y", line.Y)}
+		if line.Title {
+			attrs = append(attrs, attr("id", n.TitleID()))
+		}
+		if line.Anchor != "middle" {
+			attrs = append(attrs, attr("text-anchor", line.Anchor))
+		}
+		w.element("tspan", line.Text, attrs...)
+	}
+	w.end("text")
+	w.end("g")
+}
+
+func (n Node) textAttrs() []xml.Attr {
+	return optionalAttrs(attr("class", "graph-node-text"), attr("text-anchor", "middle"), attr("style", n.Style.textStyle()))
+}
+
+func points(ps ...[2]int) string {
+	s := make([]string, 0, len(ps))
+	for _, p := range ps {
+		s = append(s, fmt.Sprintf("%d,%d", p[0], p[1]))
+	}
+	return strings.Join(s, " ")
+}
+
+// hexagonInset is horizontal distance from side corners of hexagon to its top and bottom sides.
+func (b box) hexagonInset() int {
+	return b.H / 4
+}
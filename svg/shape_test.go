@@ -0,0 +1,92 @@
+package svg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShapesGolden(t *testing.T) {
+	shapes := []Shape{ShapeHTML, ShapeRect, ShapeRounded, ShapeEllipse, ShapeDiamond, ShapeHexagon, ShapeCylinder, ShapeRecord}
+	g := Graph{ID: "graph", Nodes: map[uint64]Node{}}
+	for i, shape := range shapes {
+		g.Nodes[uint64(i+1)] = Node{
+			ID:       fmt.Sprint(i + 1),
+			X:        i * 120,
+			Title:    string(shape) + " <&>",
+			NodeData: map[string]interface{}{"size": 10, "name": "x"},
+			Shape:    shape,
+		}
+	}
+	doc := SVG{ID: "svg", Body: g, Margin: 10}.Render()
+
+	elements, text := parse(t, doc)
+	if count(elements, "foreignObject") != 1 {
+		t.Errorf("expected only html node in foreignObject:\n%s", doc)
+	}
+	if count(elements, "tspan") != 6*3+5 {
+		t.Errorf("expected title and rows in tspans:\n%s", doc)
+	}
+	if !strings.Contains(text, "diamond <&>") || !strings.Contains(text, "size: 10") {
+		t.Errorf("expected text of shapes:\n%s", text)
+	}
+	golden(t, "shapes.svg", doc)
+}
+
+func count(elements []string, name string) int {
+	n := 0
+	for _, e := range elements {
+		if e == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestShapeSize(t *testing.T) {
+	rect := Node{Title: "title", Shape: ShapeRect}
+	w, h := rect.Size()
+
+	tests := map[Shape][2]int{
+		ShapeHTML:     {w, h},
+		ShapeRounded:  {w, h},
+		ShapeRecord:   {w, h},
+		ShapeDiamond:  {2 * w, 2 * h},
+		ShapeEllipse:  {int(float64(w)*1.4143) + 1, int(float64(h)*1.4143) + 1},
+		ShapeHexagon:  {w + h/2, h},
+		ShapeCylinder: {w, h + 3*(nodeFontSize/2)},
+	}
+	for shape, expected := range tests {
+		n := Node{Title: "title", Shape: shape}
+		if sw, sh := n.Size(); [2]int{sw, sh} != expected {
+			t.Errorf("%s: expected size %v, got %v", shape, expected, [2]int{sw, sh})
+		}
+	}
+
+	// shape does not change size set by layout
+	if sw, sh := (Node{Title: "title", Shape: ShapeDiamond, W: 30, H: 20}).Size(); sw != 30 || sh != 20 {
+		t.Errorf("expected size 30x20, got %dx%d", sw, sh)
+	}
+}
+
+func TestClipPathShapes(t *testing.T) {
+	tests := []struct {
+		shape    Shape
+		expected [][2]int
+	}{
+		{ShapeRect, [][2]int{{40, 20}, {160, 80}}},
+		{ShapeEllipse, [][2]int{{28, 14}, {172, 86}}},
+		{ShapeDiamond, [][2]int{{20, 10}, {180, 90}}},
+		{ShapeHexagon, [][2]int{{32, 16}, {168, 84}}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.shape), func(t *testing.T) {
+			from := box{X: -40, Y: -20, W: 80, H: 40, Shape: tt.shape}
+			to := box{X: 160, Y: 80, W: 80, H: 40, Shape: tt.shape}
+			if got := clipPath([][2]int{{0, 0}, {200, 100}}, from, to); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
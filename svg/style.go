@@ -0,0 +1,203 @@
+package svg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Style overrides theme for single node, edge or cluster. Zero values keep values of theme.
+// Font size changes size of node, so it should be set before node is laid out.
+type Style struct {
+	Class       string  // CSS classes added to element
+	Fill        string  // background of nodes and clusters
+	Stroke      string  // color of edges and borders
+	StrokeWidth float64 // width of edges and borders
+	Dash        string  // stroke-dasharray of edges and borders, like "4 2"
+	FontFamily  string
+	FontSize    int
+	FontColor   string
+}
+
+// Theme is look of whole graph, it is rendered as CSS in <style> block.
+// Elements have classes, so pages can override theme with their own CSS:
+// graph-node, graph-node-box, graph-node-title, graph-node-data, graph-edge, graph-arrow,
+// graph-cluster, graph-cluster-box, graph-cluster-title.
+// Nodes with SVG shapes have classes graph-node-<shape>, graph-node-shape, graph-node-text and graph-node-separator.
+type Theme struct {
+	Background string // empty is transparent
+	FontFamily string // should start with family of font that measures text, GoFontFamily for DefaultMeasurer
+	EmbedFont  bool   // embeds Go Regular as GoFontFamily, so browsers without it draw text as it was measured
+	FontSize   int    // size of text of nodes and clusters in pixels, 9 when 0
+	FontColor  string
+
+	NodeFill        string
+	NodeStroke      string
+	NodeStrokeWidth float64
+	NodeRadius      int
+	NodePadding     int // space between border of node and its title and data, it changes size of nodes
+	DataSeparator   string
+
+	EdgeStroke      string
+	EdgeStrokeWidth float64
+
+	ClusterFill      string
+	ClusterStroke    string
+	ClusterFontColor string
+}
+
+// DefaultTheme is black on white.
+var DefaultTheme = Theme{
+	FontFamily:       GoFontFamily + ", sans-serif",
+	FontSize:         nodeFontSize,
+	FontColor:        "black",
+	NodeFill:         "white",
+	NodeStroke:       "lightgray",
+	NodeStrokeWidth:  1,
+	NodeRadius:       5,
+	NodePadding:      4,
+	DataSeparator:    "lightgray",
+	EdgeStroke:       "black",
+	EdgeStrokeWidth:  1,
+	ClusterFill:      "none",
+	ClusterStroke:    "gray",
+	ClusterFontColor: "gray",
+}
+
+// DarkTheme is light on dark background.
+var DarkTheme = Theme{
+	Background:       "#1e1e1e",
+	FontFamily:       GoFontFamily + ", sans-serif",
+	FontSize:         nodeFontSize,
+	FontColor:        "#d4d4d4",
+	NodeFill:         "#2d2d30",
+	NodeStroke:       "#6e6e6e",
+	NodeStrokeWidth:  1,
+	NodeRadius:       5,
+	NodePadding:      4,
+	DataSeparator:    "#4e4e4e",
+	EdgeStroke:       "#c8c8c8",
+	EdgeStrokeWidth:  1,
+	ClusterFill:      "none",
+	ClusterStroke:    "#808080",
+	ClusterFontColor: "#a0a0a0",
+}
+
+// Themes are themes by name.
+var Themes = map[string]Theme{
+	"light": DefaultTheme,
+	"dark":  DarkTheme,
+}
+
+func (t Theme) fontSize() int {
+	if t.FontSize > 0 {
+		return t.FontSize
+	}
+	return nodeFontSize
+}
+
+// nodePadding is what padding and border of 1px add to width and height of node.
+func (t Theme) nodePadding() int {
+	return 2 * (t.NodePadding + 1)
+}
+
+// CSS is style sheet of theme, rules apply only inside element with scope ID.
+func (t Theme) CSS(scope string) string {
+	p := "#" + scope + " "
+	rules := []string{
+		fmt.Sprintf(`%s.graph-node-box { overflow: hidden; box-sizing: border-box; width: 100%%; height: 100%%; background: %s; border: %spx solid %s; border-radius: %dpx; font-family: %s; font-size: %dpx; color: %s; }`,
+			p, t.NodeFill, formatNumber(t.NodeStrokeWidth), t.NodeStroke, t.NodeRadius, t.FontFamily, t.fontSize(), t.FontColor),
+		fmt.Sprintf(`%s.graph-node-title { text-align: center; padding: %dpx; cursor: pointer; }`, p, t.NodePadding),
+		fmt.Sprintf(`%s.graph-node-data { padding: 0px %dpx %dpx %dpx; border-top: 1px solid %s; }`, p, t.NodePadding, t.NodePadding, t.NodePadding, t.DataSeparator),
+		fmt.Sprintf(`%s.graph-node-shape { fill: %s; stroke: %s; stroke-width: %s; }`, p, t.NodeFill, t.NodeStroke, formatNumber(t.NodeStrokeWidth)),
+		fmt.Sprintf(`%s.graph-node-text { font-family: %s; font-size: %dpx; fill: %s; }`, p, t.FontFamily, t.fontSize(), t.FontColor),
+		fmt.Sprintf(`%s.graph-node-separator { stroke: %s; stroke-width: 1; }`, p, t.DataSeparator),
+		fmt.Sprintf(`%s.graph-edge { fill: none; stroke: %s; stroke-width: %s; }`, p, t.EdgeStroke, formatNumber(t.EdgeStrokeWidth)),
+		// arrows take color of their edge where browsers support it
+		fmt.Sprintf(`%s.graph-arrow { fill: %s; stroke: none; fill: context-stroke; }`, p, t.EdgeStroke),
+		fmt.Sprintf(`%s.graph-arrow-open { fill: none; stroke: %s; stroke-width: 1.5; stroke: context-stroke; }`, p, t.EdgeStroke),
+		fmt.Sprintf(`%s.graph-cluster-box { fill: %s; stroke: %s; stroke-width: 1; rx: %dpx; }`, p, t.ClusterFill, t.ClusterStroke, t.NodeRadius),
+		fmt.Sprintf(`%s.graph-cluster-title { font-family: %s; font-size: %dpx; fill: %s; }`, p, t.FontFamily, t.fontSize(), t.ClusterFontColor),
+	}
+	if t.Background != "" {
+		rules = append([]string{fmt.Sprintf(`#%s { background: %s; }`, scope, t.Background)}, rules...)
+	}
+	if t.EmbedFont {
+		rules = append([]string{goFontFace()}, rules...)
+	}
+	return strings.Join(rules, "\n")
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// classes are base classes and classes of style.
+func (s Style) classes(base string) string {
+	if s.Class == "" {
+		return base
+	}
+	return base + " " + s.Class
+}
+
+// svgStyle is style of SVG shape, empty when style does not override theme.
+func (s Style) svgStyle() string {
+	var decls []string
+	decls = appendDecl(decls, "fill", s.Fill)
+	decls = appendDecl(decls, "stroke", s.Stroke)
+	if s.StrokeWidth != 0 {
+		decls = appendDecl(decls, "stroke-width", formatNumber(s.StrokeWidth))
+	}
+	decls = appendDecl(decls, "stroke-dasharray", s.Dash)
+	return joinDecls(decls)
+}
+
+// htmlStyle is style of node box, which is HTML element.
+func (s Style) htmlStyle() string {
+	var decls []string
+	decls = appendDecl(decls, "background", s.Fill)
+	decls = appendDecl(decls, "border-color", s.Stroke)
+	if s.StrokeWidth != 0 {
+		decls = appendDecl(decls, "border-width", formatNumber(s.StrokeWidth)+"px")
+	}
+	if s.Dash != "" {
+		decls = appendDecl(decls, "border-style", "dashed")
+	}
+	decls = appendDecl(decls, "font-family", s.FontFamily)
+	if s.FontSize != 0 {
+		decls = appendDecl(decls, "font-size", fmt.Sprintf("%dpx", s.FontSize))
+	}
+	decls = appendDecl(decls, "color", s.FontColor)
+	return joinDecls(decls)
+}
+
+// textStyle is style of SVG text.
+func (s Style) textStyle() string {
+	var decls []string
+	decls = appendDecl(decls, "font-family", s.FontFamily)
+	if s.FontSize != 0 {
+		decls = appendDecl(decls, "font-size", fmt.Sprintf("%dpx", s.FontSize))
+	}
+	decls = appendDecl(decls, "fill", s.FontColor)
+	return joinDecls(decls)
+}
+
+// appendDecl adds CSS declaration when value is safe.
+// Values come from inputs, so values that could end declaration or load resources are dropped.
+func appendDecl(decls []string, property, value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.ContainsAny(value, ";{}\\<>\"'\n\r") {
+		return decls
+	}
+	if lower := strings.ToLower(value); strings.Contains(lower, "url(") || strings.Contains(lower, "expression(") {
+		return decls
+	}
+	return append(decls, property+":"+value)
+}
+
+func joinDecls(decls []string) string {
+	if len(decls) == 0 {
+		return ""
+	}
+	return strings.Join(decls, ";") + ";"
+}
@@ -0,0 +1,85 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTheme(t *testing.T) {
+	g := Graph{
+		ID:    "g",
+		Nodes: map[uint64]Node{1: {ID: "1", Title: "a"}},
+	}
+
+	light := SVG{ID: "root", Body: g}.Render()
+	if !strings.Contains(light, "<style>\n#root .graph-node-box {") || !strings.Contains(light, "background: white;") {
+		t.Errorf("expected default theme:\n%s", light)
+	}
+	if strings.Contains(light, "#root { background") {
+		t.Errorf("expected transparent background:\n%s", light)
+	}
+
+	dark := SVG{ID: "root", Theme: &DarkTheme, Body: g}.Render()
+	for _, s := range []string{"#root { background: #1e1e1e; }", "#root .graph-edge { fill: none; stroke: #c8c8c8; stroke-width: 1; }"} {
+		if !strings.Contains(dark, s) {
+			t.Errorf("expected %s in:\n%s", s, dark)
+		}
+	}
+}
+
+func TestThemeEmbedFont(t *testing.T) {
+	theme := DefaultTheme
+	if css := theme.CSS("g"); strings.Contains(css, "@font-face") || !strings.Contains(css, "font-family: Go, sans-serif") {
+		t.Errorf("expected font named without embedding:\n%s", css)
+	}
+	theme.EmbedFont = true
+	if css := theme.CSS("g"); !strings.HasPrefix(css, "@font-face { font-family: Go; src: url(data:font/ttf;base64,") {
+		t.Errorf("expected embedded font, got %.200s", css)
+	}
+}
+
+func TestStyle(t *testing.T) {
+	style := Style{
+		Class:       "hot",
+		Fill:        "yellow",
+		Stroke:      "red",
+		StrokeWidth: 1.5,
+		Dash:        "4 2",
+		FontFamily:  "serif",
+		FontSize:    12,
+		FontColor:   "blue",
+	}
+
+	node := Node{ID: "1", Title: "abc", Style: style}.Render()
+	for _, s := range []string{
+		`<g class="graph-node hot" data-node="1">`,
+		`style="background:yellow;border-color:red;border-width:1.5px;border-style:dashed;font-family:serif;font-size:12px;color:blue;"`,
+	} {
+		if !strings.Contains(node, s) {
+			t.Errorf("expected %s in node:\n%s", s, node)
+		}
+	}
+	if w, plain := (Node{Title: "abc", Style: style}).Width(), (Node{Title: "abc"}).Width(); w <= plain {
+		t.Errorf("expected bigger font to make node wider: %d <= %d", w, plain)
+	}
+
+	edge := Edge{Path: [][2]int{{0, 0}, {10, 10}}, Style: style}.Render()
+	if expected := `<polyline class="graph-edge hot" style="fill:yellow;stroke:red;stroke-width:1.5;stroke-dasharray:4 2;" points="0,0 10,10"></polyline>`; edge != expected {
+		t.Errorf("expected edge:\n%s\ngot:\n%s", expected, edge)
+	}
+
+	cluster := Cluster{ID: "c", W: 10, H: 10, Title: "C", Style: Style{Stroke: "green", FontColor: "blue"}}.Render()
+	for _, s := range []string{
+		`<g id="svg:graph:cluster:c" class="graph-cluster">`,
+		`rx="5" style="stroke:green;"`,
+		`<text class="graph-cluster-title" x="5" y="14" style="fill:blue;">C</text>`,
+	} {
+		if !strings.Contains(cluster, s) {
+			t.Errorf("expected %s in cluster:\n%s", s, cluster)
+		}
+	}
+
+	if plain := (Edge{Path: [][2]int{{0, 0}}}).Render(); strings.Contains(plain, "style=") {
+		t.Errorf("expected edge without inline style: %s", plain)
+	}
+}
@@ -0,0 +1,92 @@
+package svg
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// TextMeasurer measures text, so boxes of nodes fit their titles and data.
+type TextMeasurer interface {
+	// TextWidth is width of single line of text in pixels, when it is drawn with font of size in pixels.
+	TextWidth(text string, fontSize int) int
+}
+
+// DefaultMeasurer measures text with metrics of Go Regular font, which is bundled with golang.org/x/image.
+// Its widths are close to widths of common sans-serif fonts.
+// Themes name it first in FontFamily as GoFontFamily, and embed it when EmbedFont is set.
+var DefaultMeasurer TextMeasurer = goRegularMeasurer{}
+
+// GoFontFamily is CSS font family of Go Regular font.
+const GoFontFamily = "Go"
+
+// FontMeasurer measures text with metrics of TrueType or OpenType font.
+// Runes are measured one by one with kerning between them.
+// Runes that font does not have are one em wide, combining marks and format characters have no width.
+type FontMeasurer struct {
+	font *sfnt.Font
+}
+
+// NewFontMeasurer parses font from TTF or OTF data.
+func NewFontMeasurer(data []byte) (*FontMeasurer, error) {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return &FontMeasurer{font: f}, nil
+}
+
+func (m *FontMeasurer) TextWidth(text string, fontSize int) int {
+	var (
+		b     sfnt.Buffer
+		ppem  = fixed.I(fontSize)
+		width fixed.Int26_6
+		prev  sfnt.GlyphIndex
+	)
+	for _, r := range text {
+		g, err := m.font.GlyphIndex(&b, r)
+		if err != nil || g == 0 {
+			prev = 0
+			if !unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc) {
+				width += ppem
+			}
+			continue
+		}
+		if prev != 0 {
+			if kern, err := m.font.Kern(&b, prev, g, ppem, font.HintingNone); err == nil {
+				width += kern
+			}
+		}
+		if advance, err := m.font.GlyphAdvance(&b, g, ppem, font.HintingNone); err == nil {
+			width += advance
+		}
+		prev = g
+	}
+	return width.Ceil()
+}
+
+// goRegular is parsed when text is measured first time.
+var goRegular = sync.OnceValue(func() *FontMeasurer {
+	m, err := NewFontMeasurer(goregular.TTF)
+	if err != nil {
+		panic(err)
+	}
+	return m
+})
+
+type goRegularMeasurer struct{}
+
+func (goRegularMeasurer) TextWidth(text string, fontSize int) int {
+	return goRegular().TextWidth(text, fontSize)
+}
+
+// goFontFace is CSS rule with Go Regular font as data URL.
+var goFontFace = sync.OnceValue(func() string {
+	return fmt.Sprintf(`@font-face { font-family: %s; src: url(data:font/ttf;base64,%s) format("truetype"); }`, GoFontFamily, base64.StdEncoding.EncodeToString(goregular.TTF))
+})
@@ -0,0 +1,82 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFontMeasurer(t *testing.T) {
+	m := DefaultMeasurer
+
+	if narrow, wide := m.TextWidth("iiii", 10), m.TextWidth("WWWW", 10); narrow >= wide {
+		t.Errorf("expected proportional widths, got %d for iiii and %d for WWWW", narrow, wide)
+	}
+	if small, large := m.TextWidth("abc", 9), m.TextWidth("abc", 18); large < 2*small-1 || large > 2*small+1 {
+		t.Errorf("expected width to scale with font size, got %d and %d", small, large)
+	}
+	if w := m.TextWidth("", 10); w != 0 {
+		t.Errorf("expected no width of empty text, got %d", w)
+	}
+
+	// runes are measured, not bytes
+	if ascii, accented := m.TextWidth("eee", 10), m.TextWidth("ééé", 10); accented > ascii+1 {
+		t.Errorf("expected é as wide as e, got %d for eee and %d for ééé", ascii, accented)
+	}
+	if w, plain := m.TextWidth("e\u0301", 10), m.TextWidth("e", 10); w != plain {
+		t.Errorf("expected combining mark without width, got %d and %d", w, plain)
+	}
+	// font does not have CJK glyphs, they are one em wide
+	if w := m.TextWidth("日本", 10); w != 20 {
+		t.Errorf("expected 20 for two missing glyphs, got %d", w)
+	}
+}
+
+func TestNewFontMeasurer(t *testing.T) {
+	if _, err := NewFontMeasurer([]byte("not a font")); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+// runeMeasurer is monospace font, each rune is as wide as font size.
+type runeMeasurer struct{}
+
+func (runeMeasurer) TextWidth(text string, fontSize int) int {
+	return utf8.RuneCountInString(text) * fontSize
+}
+
+func TestNodeMeasurer(t *testing.T) {
+	n, _ := Graph{Nodes: map[uint64]Node{1: {Title: "日本語"}}, Measurer: runeMeasurer{}}.node(1)
+	if w := n.Width(); w != 3*nodeFontSize {
+		t.Errorf("expected width %d, got %d", 3*nodeFontSize, w)
+	}
+	if w, _ := n.Size(); w != 3*nodeFontSize+10 {
+		t.Errorf("expected box width %d, got %d", 3*nodeFontSize+10, w)
+	}
+
+	n.NodeData = map[string]interface{}{"key": "long value", "id": "ignored"}
+	expected := (3+10)*nodeFontSize + 4*cellPadding + nodeFontSize
+	if w := n.Width(); w != expected {
+		t.Errorf("expected width of data %d, got %d", expected, w)
+	}
+}
+
+func TestNodeTheme(t *testing.T) {
+	theme := DefaultTheme
+	theme.NodePadding = 9
+	theme.FontSize = 18
+
+	g := Graph{Clusters: map[string]Cluster{"c": {Title: "abc"}}, Theme: &theme, Measurer: runeMeasurer{}}
+	if w, h := g.NodeSize(Node{Title: "abc"}); w != 3*18+20 || h != 18*textHeightMultiplier+20 {
+		t.Errorf("expected size of node with theme, got %dx%d", w, h)
+	}
+	if l := g.cluster("c").TitleLine(); l.X != 10 || l.Y != 18+10 {
+		t.Errorf("expected title at 10,28, got %d,%d", l.X, l.Y)
+	}
+	if css := theme.CSS("g"); !strings.Contains(css, "font-size: 18px") {
+		t.Errorf("expected font size in css:\n%s", css)
+	}
+	if p := theme.NodePaint(Style{}); p.FontSize != 18 {
+		t.Errorf("expected font size 18, got %d", p.FontSize)
+	}
+}
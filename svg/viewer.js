@@ -0,0 +1,212 @@
+// Viewer of graph: pan and zoom, highlight of edges of hovered node, focus on clicked node and search.
+(function () {
+  "use strict";
+
+  var viewport = document.getElementById("graph-viewport");
+  var svg = viewport.querySelector("svg");
+  var search = document.getElementById("graph-search");
+  var reset = document.getElementById("graph-reset");
+  if (!svg) {
+    return;
+  }
+
+  var nodes = Array.prototype.slice.call(svg.querySelectorAll(".graph-node"));
+  var edges = Array.prototype.slice.call(svg.querySelectorAll(".graph-edge"));
+  var nodeByID = {};
+  var edgesByNode = {};
+  nodes.forEach(function (node) {
+    var id = node.getAttribute("data-node");
+    nodeByID[id] = node;
+    edgesByNode[id] = [];
+  });
+  edges.forEach(function (edge) {
+    [edge.getAttribute("data-from"), edge.getAttribute("data-to")].forEach(function (id) {
+      if (edgesByNode[id]) {
+        edgesByNode[id].push(edge);
+      }
+    });
+  });
+
+  // pan and zoom change viewBox
+  var initial = viewBox();
+  var view = initial.slice();
+
+  function viewBox() {
+    var v = (svg.getAttribute("viewBox") || "").split(/[\s,]+/).map(Number);
+    if (v.length !== 4 || v.some(isNaN)) {
+      var b = svg.getBBox();
+      v = [b.x, b.y, b.width, b.height];
+    }
+    return v;
+  }
+
+  function setView(v) {
+    view = v;
+    svg.setAttribute("viewBox", v.join(" "));
+  }
+
+  // point of viewBox under client point, viewBox is centered and scaled to fit, like default preserveAspectRatio
+  function toView(clientX, clientY) {
+    var r = svg.getBoundingClientRect();
+    var scale = Math.min(r.width / view[2], r.height / view[3]);
+    var x = view[0] - (r.width / scale - view[2]) / 2 + (clientX - r.left) / scale;
+    var y = view[1] - (r.height / scale - view[3]) / 2 + (clientY - r.top) / scale;
+    return { x: x, y: y, scale: scale };
+  }
+
+  viewport.addEventListener("wheel", function (e) {
+    e.preventDefault();
+    var p = toView(e.clientX, e.clientY);
+    var k = Math.exp(e.deltaY * 0.0015);
+    setView([p.x - (p.x - view[0]) * k, p.y - (p.y - view[1]) * k, view[2] * k, view[3] * k]);
+  }, { passive: false });
+
+  var drag = null;
+  viewport.addEventListener("pointerdown", function (e) {
+    if (e.button !== 0) {
+      return;
+    }
+    drag = { x: e.clientX, y: e.clientY, view: view.slice(), moved: false };
+    viewport.setPointerCapture(e.pointerId);
+  });
+  viewport.addEventListener("pointermove", function (e) {
+    if (!drag) {
+      return;
+    }
+    var dx = e.clientX - drag.x;
+    var dy = e.clientY - drag.y;
+    if (!drag.moved && Math.abs(dx) + Math.abs(dy) < 3) {
+      return;
+    }
+    drag.moved = true;
+    viewport.classList.add("graph-panning");
+    var scale = toView(e.clientX, e.clientY).scale;
+    setView([drag.view[0] - dx / scale, drag.view[1] - dy / scale, drag.view[2], drag.view[3]]);
+  });
+  function endDrag(e) {
+    if (!drag) {
+      return;
+    }
+    var moved = drag.moved;
+    drag = null;
+    viewport.classList.remove("graph-panning");
+    if (!moved && e.type === "pointerup") {
+      click(e);
+    }
+  }
+  viewport.addEventListener("pointerup", endDrag);
+  viewport.addEventListener("pointercancel", endDrag);
+
+  // hover highlights node and its edges
+  function nodeAt(target) {
+    return target && target.closest ? target.closest(".graph-node") : null;
+  }
+
+  function setHighlight(node, on) {
+    var id = node.getAttribute("data-node");
+    node.classList.toggle("graph-highlight", on);
+    (edgesByNode[id] || []).forEach(function (edge) {
+      edge.classList.toggle("graph-highlight", on);
+    });
+  }
+
+  svg.addEventListener("mouseover", function (e) {
+    var node = nodeAt(e.target);
+    if (node && !node.contains(e.relatedTarget)) {
+      setHighlight(node, true);
+    }
+  });
+  svg.addEventListener("mouseout", function (e) {
+    var node = nodeAt(e.target);
+    if (node && !node.contains(e.relatedTarget)) {
+      setHighlight(node, false);
+    }
+  });
+
+  // click focuses on node and its neighbors, click on background clears focus
+  function click(e) {
+    var node = nodeAt(document.elementFromPoint(e.clientX, e.clientY));
+    if (!node) {
+      dim(null);
+      return;
+    }
+    var id = node.getAttribute("data-node");
+    var shown = {};
+    shown[id] = true;
+    (edgesByNode[id] || []).forEach(function (edge) {
+      shown[edge.getAttribute("data-from")] = true;
+      shown[edge.getAttribute("data-to")] = true;
+    });
+    dim(shown, edgesByNode[id] || []);
+    zoomTo(Object.keys(shown).map(function (id) { return nodeByID[id]; }));
+  }
+
+  // dim hides nodes that are not shown and edges that are not in shownEdges, null shows everything
+  function dim(shown, shownEdges) {
+    nodes.forEach(function (node) {
+      node.classList.toggle("graph-dim", shown !== null && !shown[node.getAttribute("data-node")]);
+    });
+    edges.forEach(function (edge) {
+      edge.classList.toggle("graph-dim", shown !== null && shownEdges.indexOf(edge) < 0);
+    });
+  }
+
+  function zoomTo(elements) {
+    var x0 = Infinity, y0 = Infinity, x1 = -Infinity, y1 = -Infinity;
+    elements.forEach(function (element) {
+      if (!element) {
+        return;
+      }
+      var b = element.getBBox();
+      x0 = Math.min(x0, b.x);
+      y0 = Math.min(y0, b.y);
+      x1 = Math.max(x1, b.x + b.width);
+      y1 = Math.max(y1, b.y + b.height);
+    });
+    if (x0 === Infinity) {
+      return;
+    }
+    var margin = Math.max(20, (x1 - x0) * 0.1, (y1 - y0) * 0.1);
+    setView([x0 - margin, y0 - margin, x1 - x0 + 2 * margin, y1 - y0 + 2 * margin]);
+  }
+
+  // search marks nodes with text that contains query, enter zooms to them
+  function matches() {
+    var q = search.value.trim().toLowerCase();
+    if (q === "") {
+      return null;
+    }
+    return nodes.filter(function (node) {
+      return node.textContent.toLowerCase().indexOf(q) >= 0;
+    });
+  }
+
+  search.addEventListener("input", function () {
+    var found = matches();
+    nodes.forEach(function (node) {
+      var match = found !== null && found.indexOf(node) >= 0;
+      node.classList.toggle("graph-match", match);
+      node.classList.toggle("graph-dim", found !== null && !match);
+    });
+    edges.forEach(function (edge) {
+      edge.classList.toggle("graph-dim", found !== null);
+    });
+  });
+  search.addEventListener("keydown", function (e) {
+    if (e.key === "Enter") {
+      zoomTo(matches() || []);
+    } else if (e.key === "Escape") {
+      search.value = "";
+      search.dispatchEvent(new Event("input"));
+    }
+  });
+
+  reset.addEventListener("click", function () {
+    search.value = "";
+    nodes.forEach(function (node) {
+      node.classList.remove("graph-match");
+    });
+    dim(null);
+    setView(initial.slice());
+  });
+})();
@@ -0,0 +1,135 @@
+package svg
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	namespaceSVG   = "http://www.w3.org/2000/svg"
+	namespaceXHTML = "http://www.w3.org/1999/xhtml"
+)
+
+// element is part of document that writes itself as XML tokens, so all text and attributes are escaped.
+type element interface {
+	write(w *writer)
+}
+
+// writer writes XML with encoding/xml. Text and attribute values are escaped by encoder,
+// invalid characters are replaced, so output is always well-formed.
+// First error stops writing, it is returned by flush.
+type writer struct {
+	out io.Writer
+	enc *xml.Encoder
+	err error
+}
+
+func newWriter(out io.Writer) *writer {
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	return &writer{out: out, enc: enc}
+}
+
+// attr is attribute of element.
+func attr(name, value string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: name}, Value: value}
+}
+
+func intAttr(name string, value int) xml.Attr {
+	return attr(name, strconv.Itoa(value))
+}
+
+// optionalAttrs are attributes with values, attributes with empty values are dropped.
+func optionalAttrs(attrs ...xml.Attr) []xml.Attr {
+	var out []xml.Attr
+	for _, a := range attrs {
+		if a.Value != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func (w *writer) token(t xml.Token) {
+	if w.err != nil {
+		return
+	}
+	w.err = w.enc.EncodeToken(t)
+}
+
+func (w *writer) start(name string, attrs ...xml.Attr) {
+	w.token(xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs})
+}
+
+func (w *writer) end(name string) {
+	w.token(xml.EndElement{Name: xml.Name{Local: name}})
+}
+
+// element writes element with attributes and escaped text content.
+func (w *writer) element(name, text string, attrs ...xml.Attr) {
+	w.start(name, attrs...)
+	if text != "" {
+		w.text(text)
+	}
+	w.end(name)
+}
+
+func (w *writer) text(s string) {
+	w.token(xml.CharData(s))
+}
+
+// raw writes markup of renderable as is. It is for definitions that are not part of this package.
+// Renderables that implement io.WriterTo are streamed.
+func (w *writer) raw(r Renderable) {
+	if e, ok := r.(element); ok {
+		e.write(w)
+		return
+	}
+	if w.flush() != nil {
+		return
+	}
+	if wt, ok := r.(io.WriterTo); ok {
+		_, w.err = wt.WriteTo(w.out)
+		return
+	}
+	_, w.err = io.WriteString(w.out, r.Render())
+}
+
+func (w *writer) flush() error {
+	if w.err == nil {
+		w.err = w.enc.Flush()
+	}
+	return w.err
+}
+
+// countingWriter counts written bytes.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// writeTo streams element to out. Encoder buffers small part of output, so memory does not grow with size of document.
+func writeTo(out io.Writer, e element) (int64, error) {
+	c := &countingWriter{w: out}
+	w := newWriter(c)
+	e.write(w)
+	err := w.flush()
+	return c.n, err
+}
+
+// render is element as string.
+func render(e element) string {
+	var b strings.Builder
+	w := newWriter(&b)
+	e.write(w)
+	w.flush()
+	return b.String()
+}
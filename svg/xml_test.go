@@ -0,0 +1,88 @@
+package svg
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// parse checks that document is well-formed XML and returns names of its elements and its text.
+func parse(t *testing.T, doc string) (elements []string, text string) {
+	t.Helper()
+	d := xml.NewDecoder(strings.NewReader(doc))
+	var b strings.Builder
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return elements, b.String()
+		}
+		if err != nil {
+			t.Fatalf("not well-formed XML: %v\n%s", err, doc)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			elements = append(elements, tok.Name.Local)
+		case xml.CharData:
+			b.Write(tok)
+		}
+	}
+}
+
+func testDocument(id, title, key, value, cluster, class, color string) SVG {
+	style := Style{Class: class, Fill: color, Stroke: color, FontFamily: color, FontColor: color}
+	return SVG{
+		ID: id,
+		Body: Graph{
+			ID: id,
+			Nodes: map[uint64]Node{
+				1: {ID: id, Title: title, NodeData: map[string]interface{}{key: value}, Style: style},
+				2: {ID: "2", X: 100, Y: 100, Title: title},
+			},
+			Edges: map[[2]uint64]Edge{
+				{1, 2}: {Path: [][2]int{{0, 0}, {100, 100}}, Head: Arrow(value), Style: style},
+			},
+			Clusters: map[string]Cluster{
+				cluster: {ID: cluster, W: 200, H: 200, Title: cluster, Style: style},
+			},
+		},
+		Definitions: []Renderable{Marker{Arrow: Arrow(title)}, Marker{Arrow: ArrowDot}},
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	title := `<script>alert("x")</script> & co`
+	doc := testDocument(`"><script>`, title, "<b>", "</td>", "c&<", `x" onclick="alert(1)`, "red;background:url(x)").Render()
+
+	elements, text := parse(t, doc)
+	for _, e := range elements {
+		if e == "script" || e == "b" {
+			t.Errorf("injected element %s:\n%s", e, doc)
+		}
+	}
+	for _, s := range []string{title, "<b>", "</td>", "c&<"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("expected text %q in:\n%s", s, doc)
+		}
+	}
+	if strings.Contains(doc, "url(x)") {
+		t.Errorf("unsafe style is not dropped:\n%s", doc)
+	}
+}
+
+func FuzzRender(f *testing.F) {
+	f.Add("root", "title", "key", "value", "cluster", "class", "red")
+	f.Add(`"><script>`, "<script>alert(1)</script>", "&amp;", "]]>", "<!--", `a" b="c`, "red;}")
+	f.Add("\x00", "\xff\xfe", "\u2028", "\r\n", "\x1b", "\t", "\ufffe")
+
+	f.Fuzz(func(t *testing.T, id, title, key, value, cluster, class, color string) {
+		doc := testDocument(id, title, key, value, cluster, class, color).Render()
+		elements, _ := parse(t, doc)
+		for _, e := range elements {
+			if e == "script" {
+				t.Errorf("injected script:\n%s", doc)
+			}
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package term
+
+// directions of lines that leave cell
+const (
+	up = 1 << iota
+	down
+	left
+	right
+
+	vertical   = up | down
+	horizontal = left | right
+)
+
+// box is owner of lines of boxes, edges are owners of their lines.
+const box = -1
+
+type cell struct {
+	lines    int
+	owner    int
+	crossing bool // vertical and horizontal lines of different edges
+	arrow    rune
+	text     rune
+}
+
+var (
+	unicodeLines = map[int]rune{
+		up: '│', down: '│', vertical: '│', left: '─', right: '─', horizontal: '─',
+		down | right: '┌', down | left: '┐', up | right: '└', up | left: '┘',
+		vertical | right: '├', vertical | left: '┤', horizontal | down: '┬', horizontal | up: '┴',
+		vertical | horizontal: '┼',
+	}
+	asciiLines = map[int]rune{
+		up: '|', down: '|', vertical: '|', left: '-', right: '-', horizontal: '-',
+	}
+)
+
+func (c cell) rune(ascii bool) rune {
+	switch {
+	case c.text != 0:
+		return c.text
+	case c.arrow != 0:
+		if !ascii {
+			return c.arrow
+		}
+		if c.arrow == '▲' {
+			return '^'
+		}
+		return 'v'
+	case c.lines == 0:
+		return ' '
+	case c.crossing && ascii:
+		return '|'
+	case c.crossing:
+		return '│'
+	case ascii:
+		if r, ok := asciiLines[c.lines]; ok {
+			return r
+		}
+		return '+'
+	default:
+		return unicodeLines[c.lines]
+	}
+}
+
+// canvas is grid of cells, lines are added to cells so lines that meet are joined.
+type canvas struct {
+	cells [][]cell
+}
+
+func newCanvas(rows, cols int) *canvas {
+	cells := make([][]cell, rows)
+	for i := range cells {
+		cells[i] = make([]cell, cols)
+	}
+	return &canvas{cells: cells}
+}
+
+// add adds lines of owner to cell. Vertical and horizontal lines of different edges cross without joining.
+func (c *canvas) add(owner, row, col, lines int) {
+	if row < 0 || row >= len(c.cells) || col < 0 || col >= len(c.cells[row]) {
+		return
+	}
+	cell := &c.cells[row][col]
+	if cell.lines != 0 && cell.owner != owner && owner != box && cell.owner != box &&
+		(cell.lines == vertical && lines == horizontal || cell.lines == horizontal && lines == vertical) {
+		cell.crossing = true
+	}
+	if cell.lines == 0 {
+		cell.owner = owner
+	}
+	cell.lines |= lines
+}
+
+// line draws vertical or horizontal line between cells.
+func (c *canvas) line(owner, row0, col0, row1, col1 int) {
+	dr, dc := sign(row1-row0), sign(col1-col0)
+	if dr != 0 && dc != 0 {
+		panic("line is not vertical or horizontal")
+	}
+	forward, backward := down, up
+	if dc != 0 {
+		forward, backward = right, left
+	}
+	if dr < 0 || dc < 0 {
+		forward, backward = backward, forward
+	}
+	for r, col := row0, col0; ; r, col = r+dr, col+dc {
+		lines := 0
+		if r != row1 || col != col1 {
+			lines |= forward
+		}
+		if r != row0 || col != col0 {
+			lines |= backward
+		}
+		c.add(owner, r, col, lines)
+		if r == row1 && col == col1 {
+			return
+		}
+	}
+}
+
+func (c *canvas) arrow(row, col int, upward bool) {
+	if row < 0 || row >= len(c.cells) || col < 0 || col >= len(c.cells[row]) {
+		return
+	}
+	if upward {
+		c.cells[row][col].arrow = '▲'
+	} else {
+		c.cells[row][col].arrow = '▼'
+	}
+}
+
+func (c *canvas) text(row, col int, s string) {
+	for _, r := range s {
+		if row >= 0 && row < len(c.cells) && col >= 0 && col < len(c.cells[row]) {
+			c.cells[row][col].text = r
+		}
+		col++
+	}
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,376 @@
+// Package term draws layered layouts as text, for terminals and code review comments.
+//
+// Layers of layout are rows of boxes with titles, order of nodes in layers is kept and their positions
+// are snapped to columns. Edges are lines of box-drawing characters that follow fake nodes of long edges.
+// Between layers, each horizontal part of edge has its own row, so edges do not overlap,
+// and where edges cross, vertical line is drawn over horizontal line, so crossings are not mistaken for joins.
+//
+//	lg := layers.UpdateGraphLayoutLayered(g) // layers is layout.SugiyamaLayersStrategyGraphLayout
+//	fmt.Print(term.Renderer{}.Render(g, lg))
+package term
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+// DefaultCharWidth is units of layout per column, about width of character of monospace font in pixels.
+const DefaultCharWidth = 8
+
+// Renderer draws layered layouts as text.
+type Renderer struct {
+	ASCII      bool              // only ASCII characters, for terminals and fonts without box-drawing characters
+	Titles     map[uint64]string // titles of nodes, IDs when missing
+	Undirected bool              // edges have no arrows
+	CharWidth  int               // units of layout per column, DefaultCharWidth when 0
+}
+
+// boxHeight is rows of layer: top border, title and bottom border.
+const boxHeight = 3
+
+// segment is part of edge between nodes of adjacent layers.
+type segment struct {
+	edge     int
+	from, to uint64
+	src, dst int // columns where segment leaves upper node and enters lower node
+	track    int // row of horizontal part in channel between layers
+}
+
+// Render draws layout g with layered graph lg, that layers layout returned for g.
+// Clusters are not drawn. Titles are measured in runes, so wide characters shift lines after them.
+func (r Renderer) Render(g layout.Graph, lg layout.LayeredGraph) string {
+	charWidth := r.CharWidth
+	if charWidth <= 0 {
+		charWidth = DefaultCharWidth
+	}
+	if len(lg.NodePosition) == 0 {
+		return ""
+	}
+	layers := lg.Layers()
+
+	// edges in stable order, with real direction and path of each edge
+	keys := make([][2]uint64, 0, len(lg.Edges))
+	for e := range lg.Edges {
+		keys = append(keys, e)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	// dummy nodes are at points of edge paths, or between ends of edges when paths do not follow them
+	centerX := make(map[uint64]float64, len(lg.NodePosition))
+	for n, node := range g.Nodes {
+		centerX[n] = float64(node.X) + float64(node.W)/2
+	}
+	for _, e := range keys {
+		chain := lg.Edges[e]
+		path, _ := r.path(g, e)
+		for i, n := range chain {
+			if !lg.Dummy[n] {
+				continue
+			}
+			if len(path) == len(chain) {
+				centerX[n] = float64(path[i].X)
+			} else {
+				t := float64(i) / float64(len(chain)-1)
+				centerX[n] = centerX[chain[0]]*(1-t) + centerX[chain[len(chain)-1]]*t
+			}
+		}
+	}
+
+	// boxes are wide enough for their titles, and for ends of their edges to be apart from each other and from corners
+	ends := make(map[[2]uint64]int) // node and 0 for edges from its bottom or 1 for edges to its top
+	for _, e := range keys {
+		chain := lg.Edges[e]
+		for j := 1; j < len(chain); j++ {
+			ends[[2]uint64{chain[j-1], 0}]++
+			ends[[2]uint64{chain[j], 1}]++
+		}
+	}
+
+	// columns of nodes keep order of layers, nodes are pushed right when they overlap
+	left := make(map[uint64]int, len(lg.NodePosition))
+	width := make(map[uint64]int, len(lg.NodePosition))
+	for _, layer := range layers {
+		prev, prevDummy := math.MinInt, true
+		for _, n := range layer {
+			w := 1
+			if !lg.Dummy[n] {
+				w = max(utf8.RuneCountInString(r.title(n))+4, 2*max(ends[[2]uint64{n, 0}], ends[[2]uint64{n, 1}])+3)
+			}
+			l := int(math.Round(centerX[n]/float64(charWidth))) - w/2
+			if prev != math.MinInt {
+				gap := 1
+				if !lg.Dummy[n] && !prevDummy {
+					gap = 2
+				}
+				l = max(l, prev+gap+1)
+			}
+			left[n], width[n] = l, w
+			prev, prevDummy = l+w-1, lg.Dummy[n]
+		}
+	}
+	minLeft := math.MaxInt
+	for _, l := range left {
+		minLeft = min(minLeft, l)
+	}
+	for n := range left {
+		left[n] -= minLeft
+	}
+	center := func(n uint64) int { return left[n] + width[n]/2 }
+
+	// segments of edges between each pair of adjacent layers
+	channels := make([][]*segment, len(layers))
+	for i, e := range keys {
+		chain := lg.Edges[e]
+		for j := 1; j < len(chain); j++ {
+			from, to := chain[j-1], chain[j]
+			layer := lg.NodePosition[from].Layer
+			if lg.NodePosition[to].Layer != layer+1 {
+				continue
+			}
+			channels[layer] = append(channels[layer], &segment{edge: i, from: from, to: to, src: center(from), dst: center(to)})
+		}
+	}
+
+	// segments are spread along borders of boxes, in order of columns of their other ends
+	for _, segments := range channels {
+		r.attach(segments, lg, left, width, true)
+		r.attach(segments, lg, left, width, false)
+	}
+
+	// rows of layers and of channels after them
+	top := make([]int, len(layers))
+	rows := 0
+	for i, segments := range channels {
+		top[i] = rows
+		rows += boxHeight
+		if i < len(layers)-1 {
+			rows += max(1, assignTracks(segments))
+		}
+	}
+	cols := 0
+	for n, l := range left {
+		cols = max(cols, l+width[n])
+	}
+	c := newCanvas(rows, cols)
+
+	for i, layer := range layers {
+		for _, n := range layer {
+			if lg.Dummy[n] {
+				continue
+			}
+			y0, x0, x1 := top[i], left[n], left[n]+width[n]-1
+			c.line(box, y0, x0, y0, x1)
+			c.line(box, y0, x1, y0+boxHeight-1, x1)
+			c.line(box, y0+boxHeight-1, x1, y0+boxHeight-1, x0)
+			c.line(box, y0+boxHeight-1, x0, y0, x0)
+			title := r.title(n)
+			c.text(y0+1, x0+(width[n]-utf8.RuneCountInString(title))/2, title)
+		}
+	}
+
+	for i, segments := range channels {
+		for _, s := range segments {
+			y0, y1 := top[i]+boxHeight-1, top[i+1]
+			// fake nodes are lines through their layer
+			if lg.Dummy[s.to] {
+				c.line(s.edge, y1, s.dst, y1+boxHeight-1, s.dst)
+			}
+			if s.src == s.dst {
+				c.line(s.edge, y0, s.src, y1, s.dst)
+				continue
+			}
+			track := top[i] + boxHeight + s.track
+			c.line(s.edge, y0, s.src, track, s.src)
+			c.line(s.edge, track, s.src, track, s.dst)
+			c.line(s.edge, track, s.dst, y1, s.dst)
+		}
+	}
+
+	// arrows are on borders of boxes where edges end
+	if !r.Undirected {
+		for i, segments := range channels {
+			for _, s := range segments {
+				e := keys[s.edge]
+				if _, reversed := r.path(g, e); reversed {
+					if s.from == e[0] {
+						c.arrow(top[i]+boxHeight-1, s.src, true)
+					}
+				} else if s.to == e[1] {
+					c.arrow(top[i+1], s.dst, false)
+				}
+			}
+		}
+	}
+
+	return c.String(r.ASCII)
+}
+
+func (r Renderer) title(n uint64) string {
+	if t, ok := r.Titles[n]; ok {
+		return t
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+// path is path of edge of layered graph in layout, and if edge goes up in layout.
+// Layers layout reverses edges to remove cycles, edges that it reversed go up from their lower node.
+func (r Renderer) path(g layout.Graph, e [2]uint64) ([]layout.Position, bool) {
+	if edge, ok := g.Edges[e]; ok {
+		return edge.Path, false
+	}
+	edge, ok := g.Edges[[2]uint64{e[1], e[0]}]
+	if !ok {
+		return nil, false
+	}
+	path := make([]layout.Position, len(edge.Path))
+	for i, p := range edge.Path {
+		path[len(path)-1-i] = p
+	}
+	return path, true
+}
+
+// attach spreads ends of segments along bottom borders of upper boxes, or top borders of lower boxes.
+// Ends on lower boxes avoid columns where other segments leave, so their vertical parts do not join.
+func (r Renderer) attach(segments []*segment, lg layout.LayeredGraph, left, width map[uint64]int, upper bool) {
+	byNode := make(map[uint64][]*segment)
+	leaving := make(map[int]*segment)
+	for _, s := range segments {
+		leaving[s.src] = s
+		n := s.to
+		if upper {
+			n = s.from
+		}
+		if !lg.Dummy[n] {
+			byNode[n] = append(byNode[n], s)
+		}
+	}
+	for n, ss := range byNode {
+		sort.SliceStable(ss, func(i, j int) bool {
+			if upper {
+				return ss[i].dst < ss[j].dst
+			}
+			return ss[i].src < ss[j].src
+		})
+		inner := width[n] - 2
+		used := make(map[int]bool)
+		for i, s := range ss {
+			col := left[n] + 1 + (2*i+1)*inner/(2*len(ss))
+			if upper {
+				s.src = col
+				continue
+			}
+			if o, ok := leaving[col]; ok && o.edge != s.edge {
+				for _, c := range []int{col + 1, col - 1, col + 2, col - 2} {
+					if _, ok := leaving[c]; !ok && !used[c] && c > left[n] && c < left[n]+width[n]-1 {
+						col = c
+						break
+					}
+				}
+			}
+			s.dst, used[col] = col, true
+		}
+	}
+}
+
+// assignTracks puts horizontal parts of segments on rows of channel, so that parts on same row do not overlap.
+// Segment that leaves from column where other segment arrives is above it, so their vertical parts do not overlap.
+// It is number of rows.
+func assignTracks(segments []*segment) int {
+	var bent []*segment
+	for _, s := range segments {
+		if s.src != s.dst {
+			bent = append(bent, s)
+		}
+	}
+	sort.SliceStable(bent, func(i, j int) bool {
+		return min(bent[i].src, bent[i].dst) < min(bent[j].src, bent[j].dst)
+	})
+
+	// above[s] are segments that should be above s
+	above := make(map[*segment][]*segment)
+	for _, a := range bent {
+		for _, b := range bent {
+			if a != b && a.src == b.dst {
+				above[b] = append(above[b], a)
+			}
+		}
+	}
+
+	var (
+		tracks   [][]*segment
+		assigned = make(map[*segment]bool)
+	)
+	for len(assigned) < len(bent) {
+		// first segment with segments above it assigned, or first segment when they wait for each other
+		next, first := -1, -1
+		for i, s := range bent {
+			if assigned[s] {
+				continue
+			}
+			if first < 0 {
+				first = i
+			}
+			ready := true
+			for _, a := range above[s] {
+				ready = ready && assigned[a]
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			next = first
+		}
+		s := bent[next]
+
+		track := 0
+		for _, a := range above[s] {
+			if assigned[a] {
+				track = max(track, a.track+1)
+			}
+		}
+		for ; track < len(tracks) && overlaps(tracks[track], s); track++ {
+		}
+		if track == len(tracks) {
+			tracks = append(tracks, nil)
+		}
+		s.track = track
+		tracks[track] = append(tracks[track], s)
+		assigned[s] = true
+	}
+	return len(tracks)
+}
+
+func overlaps(track []*segment, s *segment) bool {
+	lo, hi := min(s.src, s.dst), max(s.src, s.dst)
+	for _, t := range track {
+		if min(t.src, t.dst) <= hi && lo <= max(t.src, t.dst) {
+			return true
+		}
+	}
+	return false
+}
+
+// String is lines of canvas without trailing spaces.
+func (c *canvas) String(ascii bool) string {
+	lines := make([]string, 0, len(c.cells))
+	for _, row := range c.cells {
+		var b strings.Builder
+		for _, cell := range row {
+			b.WriteRune(cell.rune(ascii))
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,159 @@
+package term
+
+import (
+	"testing"
+
+	"github.com/gverger/go-graph-layout/layout"
+)
+
+// layered is graph with nodes at columns of layers, and layered graph with fake nodes of long edges.
+// Fake nodes are in layers, their IDs are from 100.
+func layered(layers [][]uint64, cols map[uint64]int, edges map[[2]uint64][]uint64) (layout.Graph, layout.LayeredGraph) {
+	g := layout.Graph{Nodes: map[uint64]layout.Node{}, Edges: map[[2]uint64]layout.Edge{}}
+	lg := layout.LayeredGraph{
+		Segments:     map[[2]uint64]bool{},
+		Dummy:        map[uint64]bool{},
+		NodePosition: map[uint64]layout.LayerPosition{},
+		Edges:        edges,
+	}
+	pos := map[uint64]layout.Position{}
+	for l, layer := range layers {
+		for o, n := range layer {
+			lg.NodePosition[n] = layout.LayerPosition{Layer: l, Order: o}
+			pos[n] = layout.Position{X: cols[n] * DefaultCharWidth, Y: l * 100}
+			if n >= 100 {
+				lg.Dummy[n] = true
+				continue
+			}
+			g.Nodes[n] = layout.Node{Position: layout.Position{X: pos[n].X - 20, Y: pos[n].Y - 10}, W: 40, H: 20}
+		}
+	}
+	for e, chain := range edges {
+		var path []layout.Position
+		for i, n := range chain {
+			path = append(path, pos[n])
+			if i > 0 {
+				lg.Segments[[2]uint64{chain[i-1], n}] = true
+			}
+		}
+		g.Edges[e] = layout.Edge{Path: path}
+	}
+	return g, lg
+}
+
+func TestRender(t *testing.T) {
+	g, lg := layered(
+		[][]uint64{{1}, {2, 100, 3}, {4}},
+		map[uint64]int{1: 10, 2: 3, 100: 10, 3: 16, 4: 10},
+		map[[2]uint64][]uint64{{1, 2}: {1, 2}, {1, 3}: {1, 3}, {1, 4}: {1, 100, 4}, {2, 4}: {2, 4}, {3, 4}: {3, 4}},
+	)
+	titles := map[uint64]string{1: "root", 4: "leaf"}
+
+	expected := `     ┌───────┐
+     │ root  │
+     └─┬─┬─┬─┘
+  ┌────┘ │ └───┐
+┌─▼─┐    │   ┌─▼─┐
+│ 2 │    │   │ 3 │
+└─┬─┘    │   └─┬─┘
+  └────┐ │ ┌───┘
+     ┌─▼─▼─▼─┐
+     │ leaf  │
+     └───────┘
+`
+	if got := (Renderer{Titles: titles}).Render(g, lg); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	expected = `     +-------+
+     | root  |
+     +-+-+-+-+
+  +----+ | +---+
++-v-+    |   +-v-+
+| 2 |    |   | 3 |
++-+-+    |   +-+-+
+  +----+ | +---+
+     +-v-v-v-+
+     | leaf  |
+     +-------+
+`
+	if got := (Renderer{Titles: titles, ASCII: true}).Render(g, lg); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestRenderCrossing(t *testing.T) {
+	g, lg := layered(
+		[][]uint64{{1, 2}, {3, 4}},
+		map[uint64]int{1: 0, 2: 6, 3: 0, 4: 6},
+		map[[2]uint64][]uint64{{1, 4}: {1, 4}, {2, 3}: {2, 3}},
+	)
+
+	// vertical line of edge 2 -> 3 is drawn over horizontal line of edge 1 -> 4,
+	// and edges enter boxes beside columns where other edges leave
+	expected := `┌───┐  ┌───┐
+│ 1 │  │ 2 │
+└─┬─┘  └─┬─┘
+  └──────│┐
+   ┌─────┘│
+┌──▼┐  ┌──▼┐
+│ 3 │  │ 4 │
+└───┘  └───┘
+`
+	if got := (Renderer{}).Render(g, lg); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestRenderReversed(t *testing.T) {
+	g, lg := layered(
+		[][]uint64{{1}, {2}},
+		map[uint64]int{1: 0, 2: 0},
+		map[[2]uint64][]uint64{{1, 2}: {1, 2}},
+	)
+	// layers layout reversed edge 2 -> 1 to remove cycle, and restored it in layout
+	g.Edges = map[[2]uint64]layout.Edge{{2, 1}: {Path: []layout.Position{{X: 0, Y: 100}, {X: 0, Y: 0}}}}
+
+	expected := `┌───┐
+│ 1 │
+└─▲─┘
+  │
+┌─┴─┐
+│ 2 │
+└───┘
+`
+	if got := (Renderer{}).Render(g, lg); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	expected = `┌───┐
+│ 1 │
+└─┬─┘
+  │
+┌─┴─┐
+│ 2 │
+└───┘
+`
+	if got := (Renderer{Undirected: true}).Render(g, lg); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	if got := (Renderer{}).Render(layout.Graph{}, layout.LayeredGraph{}); got != "" {
+		t.Errorf("expected nothing, got %q", got)
+	}
+}
+
+func TestAssignTracks(t *testing.T) {
+	a := &segment{src: 0, dst: 4}
+	b := &segment{src: 2, dst: 0} // arrives where a leaves, so it is below a
+	c := &segment{src: 6, dst: 8}
+	d := &segment{src: 3, dst: 3} // straight
+	if n := assignTracks([]*segment{b, a, c, d}); n != 2 {
+		t.Errorf("expected 2 tracks, got %d", n)
+	}
+	if a.track != 0 || b.track != 1 || c.track != 0 {
+		t.Errorf("unexpected tracks %d %d %d", a.track, b.track, c.track)
+	}
+}